@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/leaharmstrong/heroku-calc/internal/fleet"
+	"github.com/leaharmstrong/heroku-calc/internal/heroku"
+	"github.com/leaharmstrong/heroku-calc/internal/pricing"
+	"github.com/leaharmstrong/heroku-calc/internal/project"
+	"github.com/leaharmstrong/heroku-calc/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pipelineExport   string
+	pipelineParallel int
+)
+
+var pipelineCmd = &cobra.Command{
+	Use:   "pipeline",
+	Short: "Compare every app in the detected app's Heroku pipeline",
+	Long: `Discover the pipeline the app is coupled to, analyze each app in it (review,
+development, staging and production) and show their formation, plans, concurrency
+env vars and recommendations side by side, flagging differences that change capacity.`,
+	RunE: runPipeline,
+}
+
+func init() {
+	pipelineCmd.Flags().StringVarP(&pipelineExport, "export", "e", "", "Also write the comparison as a markdown report to this file")
+	pipelineCmd.Flags().IntVar(&pipelineParallel, "parallel", fleet.DefaultParallelism, "Number of apps to analyze at once")
+	rootCmd.AddCommand(pipelineCmd)
+}
+
+func runPipeline(cmd *cobra.Command, args []string) error {
+	if err := resolveProjectPath(); err != nil {
+		return err
+	}
+
+	name, err := resolveAppName()
+	if err != nil {
+		return err
+	}
+
+	source, err := heroku.NewSource(name)
+	if err != nil {
+		return fmt.Errorf("failed to create Heroku client: %w", err)
+	}
+
+	pipelineSource, ok := source.(heroku.PipelineSource)
+	if !ok {
+		return fmt.Errorf("the Heroku source for %s cannot list pipelines", name)
+	}
+
+	pipeline, err := pipelineSource.GetPipeline()
+	if err != nil {
+		return err
+	}
+
+	pricingData, err := pricing.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load pricing data: %w", err)
+	}
+
+	// Apps in a pipeline deploy the same code, so the project applies to all of them
+	apps := make([]string, len(pipeline.Apps))
+	for i, app := range pipeline.Apps {
+		apps[i] = app.Name
+	}
+	results := fleet.AnalyzeApps(apps, heroku.NewSource, pricingData, project.Load(projectPath), pipelineParallel)
+	for i := range results {
+		results[i].Stage = pipeline.Apps[i].Stage
+	}
+
+	cmp := fleet.Compare(results)
+	writePipelineComparison(cmd.OutOrStdout(), pipeline.Name, cmp)
+
+	if pipelineExport != "" {
+		if err := report.Save(report.GeneratePipelineMarkdown(pipeline.Name, cmp), pipelineExport); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "\nReport written to %s\n", pipelineExport)
+	}
+
+	return nil
+}
+
+// writePipelineComparison prints the comparison as an aligned table grouped by section
+func writePipelineComparison(out io.Writer, pipelineName string, cmp *fleet.Comparison) {
+	fmt.Fprintf(out, "PIPELINE %s\n\n", pipelineName)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	header := []string{"", ""}
+	for _, app := range cmp.Apps {
+		label := app.App
+		if app.Stage != "" {
+			label = fmt.Sprintf("%s (%s)", app.App, app.Stage)
+		}
+		header = append(header, label)
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+
+	section := ""
+	for _, row := range cmp.Rows {
+		if row.Section != section {
+			section = row.Section
+			fmt.Fprintf(w, "%s\n", strings.ToUpper(section))
+		}
+		fields := append([]string{report.DifferenceMarker(row), row.Name}, row.Values...)
+		fmt.Fprintln(w, strings.Join(fields, "\t"))
+	}
+	w.Flush()
+
+	fmt.Fprintln(out, "\n! differs in a way that changes capacity, ~ differs")
+
+	for _, app := range cmp.Apps {
+		if app.Err != nil {
+			fmt.Fprintf(out, "%s could not be analyzed: %v\n", app.App, app.Err)
+		}
+	}
+}
@@ -6,6 +6,12 @@ import (
 	"path/filepath"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/leaharmstrong/heroku-calc/internal/analysis"
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+	"github.com/leaharmstrong/heroku-calc/internal/drain"
+	"github.com/leaharmstrong/heroku-calc/internal/heroku"
+	"github.com/leaharmstrong/heroku-calc/internal/logs"
+	"github.com/leaharmstrong/heroku-calc/internal/snapshot"
 	"github.com/leaharmstrong/heroku-calc/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -14,10 +20,18 @@ var (
 	// Flags
 	projectPath  string
 	appName      string
+	remoteName   string
 	dryRun       bool
 	interactive  bool
 	apply        bool
 	exportReport string
+	fromSnapshot string
+	logFile      string
+	drainPath    string
+	drainDays    int
+
+	requestsPerMinute float64
+	serviceTime       float64
 )
 
 var rootCmd = &cobra.Command{
@@ -29,12 +43,19 @@ providing performance recommendations, and optionally applying suggested changes
 }
 
 func init() {
-	rootCmd.Flags().StringVarP(&projectPath, "project", "p", "", "Path to Rails project (default: current directory)")
-	rootCmd.Flags().StringVarP(&appName, "app", "a", "", "Heroku app name (auto-detected from git if not specified)")
+	rootCmd.PersistentFlags().StringVarP(&projectPath, "project", "p", "", "Path to Rails project (default: current directory)")
+	rootCmd.PersistentFlags().StringVarP(&appName, "app", "a", "", "Heroku app name (auto-detected from git if not specified)")
+	rootCmd.PersistentFlags().StringVarP(&remoteName, "remote", "r", "", "Heroku git remote to analyze when the project has several")
 	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would change without applying")
 	rootCmd.Flags().BoolVar(&interactive, "interactive", false, "Interactively prompt for each change")
 	rootCmd.Flags().BoolVar(&apply, "apply", false, "Apply all recommended changes (use with caution)")
 	rootCmd.Flags().StringVarP(&exportReport, "export", "e", "", "Export markdown report to file (default: auto-generated filename)")
+	rootCmd.Flags().StringVar(&fromSnapshot, "from-snapshot", "", "Analyze a snapshot file instead of a live Heroku app")
+	rootCmd.Flags().StringVar(&logFile, "logs", "", "Measure dyno memory and router traffic from a saved `heroku logs` file instead of recent logs")
+	rootCmd.Flags().StringVar(&drainPath, "drain", "", "Measure dyno memory and router traffic from `heroku-calc drain` aggregates in this directory")
+	rootCmd.Flags().IntVar(&drainDays, "days", drain.DefaultRetention, "Days of drain aggregates to analyze (with --drain)")
+	rootCmd.Flags().Float64Var(&requestsPerMinute, "rpm", 0, "Peak requests per minute to size web dynos for (default: measured from router logs)")
+	rootCmd.Flags().Float64Var(&serviceTime, "service-time", 0, "Mean request service time in ms to size web dynos for (default: measured from router logs)")
 }
 
 func Execute() error {
@@ -42,6 +63,16 @@ func Execute() error {
 }
 
 func runRoot(cmd *cobra.Command, args []string) error {
+	if err := resolveProjectPath(); err != nil {
+		return err
+	}
+
+	// Launch the TUI
+	return launchTUI()
+}
+
+// resolveProjectPath defaults the project path to the current directory and makes it absolute
+func resolveProjectPath() error {
 	// Determine project path
 	if projectPath == "" {
 		cwd, err := os.Getwd()
@@ -58,16 +89,69 @@ func runRoot(cmd *cobra.Command, args []string) error {
 	}
 	projectPath = absPath
 
-	// Launch the TUI
-	return launchTUI()
+	return nil
+}
+
+// resolveAppName returns the --app flag or the app of the selected Heroku git remote
+func resolveAppName() (string, error) {
+	if appName != "" {
+		return appName, nil
+	}
+
+	remotes, err := heroku.DetectHerokuRemotes(projectPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect Heroku app: %w", err)
+	}
+
+	if remoteName != "" {
+		remote := heroku.SelectRemote(remotes, remoteName)
+		if remote == nil || remote.Name != remoteName {
+			return "", fmt.Errorf("git remote %q is not a Heroku remote", remoteName)
+		}
+		return remote.AppName, nil
+	}
+
+	if remote := heroku.SelectRemote(remotes, savedRemote()); remote != nil {
+		return remote.AppName, nil
+	}
+
+	// No single remote to choose; DetectHerokuApp explains why
+	_, _, err = heroku.DetectHerokuApp(projectPath)
+	return "", fmt.Errorf("failed to detect Heroku app: %w", err)
+}
+
+// savedRemote returns the git remote remembered in .heroku-calc.yml, or ""
+func savedRemote() string {
+	if !config.Exists(projectPath) {
+		return ""
+	}
+	cfg, err := config.Load(projectPath)
+	if err != nil {
+		return ""
+	}
+	return cfg.GitRemote
 }
 
 func launchTUI() error {
 	// Determine mode
 	mode := determineMode()
 
+	model := ui.NewModel(projectPath, appName, remoteName, mode)
+	if fromSnapshot != "" {
+		snap, err := snapshot.Load(fromSnapshot)
+		if err != nil {
+			return err
+		}
+		model = ui.NewModelFromSnapshot(projectPath, snap, mode)
+	}
+	measured, err := loadMeasurements()
+	if err != nil {
+		return err
+	}
+	model.SetMeasurements(measured)
+
 	// Create and run the BubbleTea app
-	p := tea.NewProgram(ui.NewModel(projectPath, appName, mode))
+	p := tea.NewProgram(model)
 
 	if _, err := p.Run(); err != nil {
 		return fmt.Errorf("failed to run TUI: %w", err)
@@ -76,6 +160,56 @@ func launchTUI() error {
 	return nil
 }
 
+// loadMeasurements collects the --logs file or --drain aggregates and the --rpm and --service-time numbers
+func loadMeasurements() (analysis.Measurements, error) {
+	var measured analysis.Measurements
+	if requestsPerMinute < 0 || serviceTime < 0 {
+		return measured, fmt.Errorf("--rpm and --service-time must not be negative")
+	}
+	if requestsPerMinute > 0 || serviceTime > 0 {
+		measured.Traffic = &config.TrafficInput{RequestsPerMinute: requestsPerMinute, ServiceTimeMS: serviceTime}
+	}
+
+	if logFile != "" || drainPath != "" {
+		runtime, router, err := loadLogMetrics()
+		if err != nil {
+			return measured, err
+		}
+		measured.Runtime, measured.Router = runtime, router
+	}
+	return measured, nil
+}
+
+// loadLogMetrics aggregates the --logs file or the --drain aggregates
+func loadLogMetrics() (*config.RuntimeMetrics, *config.RouterMetrics, error) {
+	if logFile != "" && drainPath != "" {
+		return nil, nil, fmt.Errorf("use either --logs or --drain, not both")
+	}
+
+	var collector *logs.Collector
+	var source string
+	var err error
+	if logFile != "" {
+		collector, err = logs.LoadFile(logFile)
+		source = logFile
+	} else {
+		if drainDays < 1 {
+			return nil, nil, fmt.Errorf("--days must be at least 1")
+		}
+		collector, err = drain.Load(drainPath, drainDays)
+		source = fmt.Sprintf("log drain, last %d days", drainDays)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	runtime, router := collector.RuntimeMetrics(source), collector.RouterMetrics(source)
+	if runtime == nil && router == nil {
+		return nil, nil, fmt.Errorf("no runtime metrics or router lines in %s", source)
+	}
+	return runtime, router, nil
+}
+
 func determineMode() ui.AppMode {
 	if apply {
 		return ui.ModeApply
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/leaharmstrong/heroku-calc/internal/fleet"
+	"github.com/leaharmstrong/heroku-calc/internal/heroku"
+	"github.com/leaharmstrong/heroku-calc/internal/pricing"
+	"github.com/leaharmstrong/heroku-calc/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	scanTeam     string
+	scanParallel int
+	scanExport   string
+	scanFormat   string
+)
+
+var scanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: "Analyze every app in a Heroku team and rank them by risk and cost",
+	Long: `Enumerate a team's apps, analyze each one concurrently and print them ranked by
+critical components, warnings, connection utilisation and monthly cost. The combined
+result can be exported as markdown or JSON.
+
+Apps are analyzed from Heroku data alone: no Rails project is read, since team apps
+come from different codebases.`,
+	RunE: runScan,
+}
+
+func init() {
+	scanCmd.Flags().StringVar(&scanTeam, "team", "", "Heroku team whose apps to scan")
+	scanCmd.Flags().IntVar(&scanParallel, "parallel", fleet.DefaultParallelism, "Number of apps to analyze at once")
+	scanCmd.Flags().StringVarP(&scanExport, "export", "e", "", "Also write the combined result to this file")
+	scanCmd.Flags().StringVar(&scanFormat, "format", "markdown", "Export format: markdown or json")
+	scanCmd.MarkFlagRequired("team")
+	rootCmd.AddCommand(scanCmd)
+}
+
+func runScan(cmd *cobra.Command, args []string) error {
+	if scanFormat != "markdown" && scanFormat != "json" {
+		return fmt.Errorf("unknown export format %q (use markdown or json)", scanFormat)
+	}
+
+	apps, err := heroku.ListTeamApps(scanTeam)
+	if err != nil {
+		return err
+	}
+	if len(apps) == 0 {
+		return fmt.Errorf("team %s has no apps", scanTeam)
+	}
+
+	pricingData, err := pricing.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load pricing data: %w", err)
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Analyzing %d apps in team %s...\n", len(apps), scanTeam)
+
+	results := fleet.AnalyzeApps(apps, heroku.NewSource, pricingData, nil, scanParallel)
+	scan := report.NewScanReport(scanTeam, fleet.Scan(results))
+	writeScanTable(cmd.OutOrStdout(), scan)
+
+	if scanExport != "" {
+		content := report.GenerateScanMarkdown(scan)
+		if scanFormat == "json" {
+			if content, err = report.GenerateScanJSON(scan); err != nil {
+				return err
+			}
+		}
+		if err := report.Save(content, scanExport); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "\nReport written to %s\n", scanExport)
+	}
+
+	return nil
+}
+
+// writeScanTable prints the ranked apps as an aligned table
+func writeScanTable(out io.Writer, scan *report.ScanReport) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tAPP\tCRITICAL\tWARNINGS\tDB CONNECTIONS\tREDIS CONNECTIONS\tDYNOS\tMONTHLY COST")
+
+	for i, entry := range scan.Apps {
+		if entry.Error != "" {
+			fmt.Fprintf(w, "%d\t%s\t-\t-\t-\t-\t-\t-\n", i+1, entry.App)
+			continue
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%s\t%s\t%d\t$%.2f\n",
+			i+1, entry.App, entry.Critical, entry.Warnings,
+			report.FormatUtilisation(entry.DatabaseUtilisation, entry.PostgresPlan),
+			report.FormatUtilisation(entry.RedisUtilisation, entry.RedisPlan),
+			entry.Dynos, entry.MonthlyCost)
+	}
+	w.Flush()
+
+	fmt.Fprintf(out, "\nTotal monthly cost: $%.2f\n", scan.MonthlyCost)
+
+	for _, entry := range scan.Apps {
+		if entry.Error != "" {
+			fmt.Fprintf(out, "%s could not be analyzed: %s\n", entry.App, entry.Error)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/leaharmstrong/heroku-calc/internal/heroku"
+	"github.com/leaharmstrong/heroku-calc/internal/pricing"
+	"github.com/leaharmstrong/heroku-calc/internal/snapshot"
+	"github.com/spf13/cobra"
+)
+
+var snapshotOutput string
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Capture an app's configuration to a JSON file for offline analysis",
+	Long: `Capture app info, env vars (sanitized), dyno formation and addons into a versioned
+JSON file. Replay it later with --from-snapshot without access to Heroku.`,
+	RunE: runSnapshot,
+}
+
+func init() {
+	snapshotCmd.Flags().StringVarP(&snapshotOutput, "output", "o", "", "Snapshot file to write (default: auto-generated filename)")
+	rootCmd.AddCommand(snapshotCmd)
+}
+
+func runSnapshot(cmd *cobra.Command, args []string) error {
+	if err := resolveProjectPath(); err != nil {
+		return err
+	}
+
+	name, err := resolveAppName()
+	if err != nil {
+		return err
+	}
+
+	source, err := heroku.NewSource(name)
+	if err != nil {
+		return fmt.Errorf("failed to create Heroku client: %w", err)
+	}
+
+	if err := source.TestConnection(); err != nil {
+		return fmt.Errorf("failed to connect to Heroku: %w", err)
+	}
+
+	pricingData, err := pricing.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load pricing data: %w", err)
+	}
+
+	snap, err := snapshot.Capture(source, pricingData)
+	if err != nil {
+		return err
+	}
+
+	filename := snapshotOutput
+	if filename == "" {
+		filename = snapshot.GenerateFileName(name)
+	}
+
+	if err := snap.Save(filename); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Snapshot of %s written to %s\n", name, filename)
+	return nil
+}
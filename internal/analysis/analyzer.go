@@ -3,35 +3,80 @@ package analysis
 import (
 	"fmt"
 	"strconv"
-	"strings"
 
 	"github.com/leaharmstrong/heroku-calc/internal/config"
 	"github.com/leaharmstrong/heroku-calc/internal/heroku"
+	"github.com/leaharmstrong/heroku-calc/internal/logs"
 	"github.com/leaharmstrong/heroku-calc/internal/pricing"
+	"github.com/leaharmstrong/heroku-calc/internal/project"
+	"github.com/leaharmstrong/heroku-calc/internal/releases"
 )
 
 // Analyzer performs configuration analysis
 type Analyzer struct {
-	client      *heroku.Client
+	source      heroku.Source
 	pricingData *pricing.Data
 	envVars     map[string]string
 	dynos       []config.DynoFormation
+	oneOffDynos []config.Dyno
 	addons      []config.Addon
+	project     *project.Project
+
+	runtime  *config.RuntimeMetrics
+	router   *config.RouterMetrics
+	traffic  *config.TrafficInput
+	releases *config.ReleaseHistory
+
+	// Postgres and Redis datastores, primary first
+	databases   []config.Datastore
+	redisStores []config.Datastore
+
+	// Buildpack names or URLs (nil if the source can't list them)
+	buildpacks []string
+
+	// Live pg:info and redis:info by the config var they were read through
+	// (missing when the source can't provide them)
+	postgresInfo map[string]*config.PostgresInfo
+	redisInfo    map[string]*config.RedisInfo
 }
 
-// NewAnalyzer creates a new analyzer instance
-func NewAnalyzer(client *heroku.Client, pricingData *pricing.Data) *Analyzer {
+// NewAnalyzer creates a new analyzer instance reading from the given source
+func NewAnalyzer(source heroku.Source, pricingData *pricing.Data) *Analyzer {
 	return &Analyzer{
-		client:      client,
+		source:      source,
 		pricingData: pricingData,
 		envVars:     make(map[string]string),
 	}
 }
 
+// SetProject provides configuration parsed from the Rails project
+func (a *Analyzer) SetProject(p *project.Project) {
+	a.project = p
+}
+
+// Measurements are numbers supplied from outside the source: log files, drain aggregates,
+// snapshots or the user. Any field may be nil
+type Measurements struct {
+	Runtime  *config.RuntimeMetrics
+	Router   *config.RouterMetrics
+	Traffic  *config.TrafficInput // Overrides the router's request rate and service time
+	Releases *config.ReleaseHistory
+}
+
+// SetMeasurements provides measured or user-supplied numbers
+// Without runtime or router metrics, LoadData reads the source's recent platform logs when it can,
+// and without a release history it reads the source's recent releases
+func (a *Analyzer) SetMeasurements(m Measurements) {
+	a.runtime = m.Runtime
+	a.router = m.Router
+	a.traffic = m.Traffic
+	a.releases = m.Releases
+}
+
 // LoadData loads all necessary data from Heroku
 func (a *Analyzer) LoadData() error {
 	// Load environment variables
-	envVars, err := a.client.GetEnvVars()
+	envVars, err := a.source.GetEnvVars()
 	if err != nil {
 		return fmt.Errorf("failed to load env vars: %w", err)
 	}
@@ -41,20 +86,72 @@ func (a *Analyzer) LoadData() error {
 		a.envVars[ev.Name] = ev.Value
 	}
 
-	// Load dynos
-	dynos, err := a.client.GetDynos()
+	// Load dyno formation and the state of running processes
+	dynos, err := a.source.GetDynos()
 	if err != nil {
 		return fmt.Errorf("failed to load dynos: %w", err)
 	}
-	a.dynos = dynos
+	processes, err := a.source.GetProcesses()
+	if err != nil {
+		return fmt.Errorf("failed to load dyno processes: %w", err)
+	}
+	a.dynos = heroku.AttachStates(dynos, processes)
+	a.oneOffDynos = heroku.OneOffDynos(processes)
 
 	// Load addons
-	addons, err := a.client.GetAddons()
+	addons, err := a.source.GetAddons()
 	if err != nil {
 		return fmt.Errorf("failed to load addons: %w", err)
 	}
 	a.addons = addons
 
+	// Map every data add-on and URL config var to the datastore it points at
+	a.databases = heroku.FindDatastores(config.DatastorePostgres, addons, envVars)
+	a.redisStores = heroku.FindDatastores(config.DatastoreRedis, addons, envVars)
+
+	// Live measurements are optional: the analysis falls back to estimates without them
+	// Only add-ons can be queried, through the first config var attaching them
+	a.postgresInfo = make(map[string]*config.PostgresInfo)
+	if pgSource, ok := a.source.(heroku.PostgresInfoSource); ok {
+		for _, store := range a.databases {
+			if store.Addon == "" {
+				continue
+			}
+			if info, err := pgSource.GetPostgresInfo(store.ConfigVars[0]); err == nil {
+				a.postgresInfo[store.ConfigVars[0]] = info
+			}
+		}
+	}
+	a.redisInfo = make(map[string]*config.RedisInfo)
+	if redisSource, ok := a.source.(heroku.RedisInfoSource); ok {
+		for _, store := range a.redisStores {
+			if store.Addon == "" {
+				continue
+			}
+			if info, err := redisSource.GetRedisInfo(store.ConfigVars[0]); err == nil {
+				a.redisInfo[store.ConfigVars[0]] = info
+			}
+		}
+	}
+	a.buildpacks = nil
+	if buildpackSource, ok := a.source.(heroku.BuildpackSource); ok {
+		if buildpacks, err := buildpackSource.GetBuildpacks(); err == nil {
+			a.buildpacks = buildpacks
+		}
+	}
+	if logSource, ok := a.source.(heroku.LogSource); ok && a.runtime == nil && a.router == nil {
+		if output, err := logSource.GetLogs(heroku.DefaultLogLines); err == nil {
+			collector := logs.Parse(output)
+			a.runtime = collector.RuntimeMetrics("heroku logs")
+			a.router = collector.RouterMetrics("heroku logs")
+		}
+	}
+	if releaseSource, ok := a.source.(heroku.ReleaseSource); ok && a.releases == nil {
+		if history, err := releases.Load(releaseSource, heroku.DefaultReleaseLimit); err == nil {
+			a.releases = history
+		}
+	}
+
 	return nil
 }
 
@@ -64,20 +161,38 @@ func (a *Analyzer) Analyze() (*config.AnalysisResult, error) {
 		Recommendations: []config.Recommendation{},
 	}
 
-	// Analyze database configuration
-	dbAnalysis := a.analyzeDatabase()
-	result.DatabaseAnalysis = dbAnalysis
+	if a.project != nil {
+		result.ProjectWarnings = a.project.Warnings
+	}
+	result.Stack = a.stack()
+	result.MemoryAnalysis = a.analyzeMemory()
+	result.TrafficAnalysis = a.analyzeTraffic()
+	result.ReleaseHistory = a.releases
+	result.Cost = a.analyzeCost()
 
-	// Analyze Redis configuration
-	redisAnalysis := a.analyzeRedis()
-	result.RedisAnalysis = redisAnalysis
+	// Analyze each database, the one behind DATABASE_URL first
+	result.Databases = a.analyzeDatabases()
+	result.DatabaseAnalysis = result.Databases[0]
+
+	// Analyze each Redis, the one behind REDIS_URL first
+	result.RedisInstances = a.analyzeRedisInstances()
+	result.RedisAnalysis = result.RedisInstances[0]
 
 	// Analyze web tier configuration
 	webAnalysis := a.analyzeWebTier()
 	result.WebTierAnalysis = webAnalysis
 
+	// Show the releases that may explain what needs attention
+	for _, dbAnalysis := range result.Databases {
+		dbAnalysis.RecentChanges = a.recentChanges("database", dbAnalysis.Status, dbAnalysis.Datastore)
+	}
+	for _, redisAnalysis := range result.RedisInstances {
+		redisAnalysis.RecentChanges = a.recentChanges("redis", redisAnalysis.Status, redisAnalysis.Datastore)
+	}
+	webAnalysis.RecentChanges = a.recentChanges("web", webAnalysis.Status, nil)
+
 	// Generate recommendations based on analysis
-	result.Recommendations = a.generateRecommendations(dbAnalysis, redisAnalysis, webAnalysis)
+	result.Recommendations = a.generateRecommendations(result.Databases, result.RedisInstances, webAnalysis)
 
 	return result, nil
 }
@@ -102,43 +217,6 @@ func (a *Analyzer) getDynosByType(dynoType string) *config.DynoFormation {
 	return nil
 }
 
-// extractPostgresPlan extracts the Postgres plan from DATABASE_URL or addon name
-func (a *Analyzer) extractPostgresPlan() string {
-	// Look through addons for Postgres
-	for _, addon := range a.addons {
-		if strings.Contains(strings.ToLower(addon.Plan), "postgres") ||
-		   strings.Contains(strings.ToLower(addon.Name), "postgres") {
-			// Extract plan from something like "heroku-postgresql:standard-0"
-			parts := strings.Split(addon.Plan, ":")
-			if len(parts) == 2 {
-				return parts[1]
-			}
-			return addon.Plan
-		}
-	}
-
-	// If not found in addons, return "unknown"
-	return "unknown"
-}
-
-// extractRedisPlan extracts the Redis plan from REDIS_URL or addon name
-func (a *Analyzer) extractRedisPlan() string {
-	// Look through addons for Redis
-	for _, addon := range a.addons {
-		if strings.Contains(strings.ToLower(addon.Plan), "redis") ||
-		   strings.Contains(strings.ToLower(addon.Name), "redis") {
-			// Extract plan from something like "heroku-redis:premium-0"
-			parts := strings.Split(addon.Plan, ":")
-			if len(parts) == 2 {
-				return parts[1]
-			}
-			return addon.Plan
-		}
-	}
-
-	return "unknown"
-}
-
 // hasEnvVar checks if an environment variable exists
 func (a *Analyzer) hasEnvVar(name string) bool {
 	_, ok := a.envVars[name]
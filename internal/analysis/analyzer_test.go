@@ -0,0 +1,110 @@
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+	"github.com/leaharmstrong/heroku-calc/internal/heroku"
+	"github.com/leaharmstrong/heroku-calc/internal/pricing"
+	"github.com/leaharmstrong/heroku-calc/internal/project"
+)
+
+// Config var values pointing at the test databases and Redis
+const (
+	primaryDatabaseURL  = "postgres://u:p@ec2-primary.compute-1.amazonaws.com:5432/d"
+	followerDatabaseURL = "postgres://u:p@ec2-follower.compute-1.amazonaws.com:5432/d"
+	primaryRedisURL     = "rediss://:p@ec2-redis.compute-1.amazonaws.com:6380"
+)
+
+// testApp describes the fixture an analyzer reads
+type testApp struct {
+	name       string
+	env        map[string]string
+	dynos      []config.DynoFormation
+	addons     []config.Addon
+	files      map[string]string // Project files by path; no project when nil
+	buildpacks []string
+
+	postgresInfo      *config.PostgresInfo
+	postgresInfoByVar map[string]*config.PostgresInfo
+	redisInfo         *config.RedisInfo
+}
+
+// newTestAnalyzer loads a fixture app into an analyzer priced with the bundled data
+func newTestAnalyzer(t *testing.T, app testApp) *Analyzer {
+	t.Helper()
+
+	name := app.name
+	if name == "" {
+		name = "shop"
+	}
+	fixture := heroku.NewFixtureSource(name)
+	for key, value := range app.env {
+		fixture.EnvVars = append(fixture.EnvVars, config.HerokuEnvVar{Name: key, Value: value})
+	}
+	fixture.Dynos = app.dynos
+	fixture.Addons = app.addons
+	fixture.Buildpacks = app.buildpacks
+	fixture.PostgresInfo = app.postgresInfo
+	fixture.PostgresInfoByVar = app.postgresInfoByVar
+	fixture.RedisInfo = app.redisInfo
+
+	pricingData, err := pricing.LoadBundled()
+	if err != nil {
+		t.Fatal(err)
+	}
+	analyzer := NewAnalyzer(fixture, pricingData)
+	if app.files != nil {
+		analyzer.SetProject(project.Load(writeProject(t, app.files)))
+	}
+	analyzer.SetMeasurements(Measurements{Releases: &config.ReleaseHistory{}})
+	if err := analyzer.LoadData(); err != nil {
+		t.Fatalf("LoadData: %v", err)
+	}
+	return analyzer
+}
+
+// writeProject writes project files into a temporary directory and returns it
+func writeProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for path, content := range files {
+		full := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// gemfileLock returns a Gemfile.lock locking the given gems ("name (version)")
+func gemfileLock(specs ...string) string {
+	var lock strings.Builder
+	lock.WriteString("GEM\n  remote: https://rubygems.org/\n  specs:\n")
+	for _, spec := range specs {
+		lock.WriteString("    " + spec + "\n")
+	}
+	lock.WriteString("\nPLATFORMS\n  ruby\n")
+	return lock.String()
+}
+
+// postgresAddon returns a Heroku Postgres add-on attached through the config vars
+func postgresAddon(name, plan string, configVars ...string) config.Addon {
+	return config.Addon{Name: name, Plan: "heroku-postgresql:" + plan, Service: "heroku-postgresql", ConfigVars: configVars}
+}
+
+// hasIssue reports whether an issue contains text
+func hasIssue(issues []string, text string) bool {
+	for _, issue := range issues {
+		if strings.Contains(issue, text) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+// Web capacity sizing
+const (
+	targetWebUtilization = 70  // Percent of Puma threads kept busy at peak, leaving room for bursts
+	minRouterMinutes     = 1.0 // Shorter router samples say nothing about the request rate
+	redundantWebDynos    = 2   // Formations already running this many web dynos keep at least this many
+)
+
+// estimateCapacity sizes the web formation for the traffic with Little's law
+// Returns nil when neither router logs nor user-supplied numbers give both a rate and a service time
+func (a *Analyzer) estimateCapacity(webDynos *config.DynoFormation, threadsPerDyno int) *config.CapacityEstimate {
+	if threadsPerDyno < 1 {
+		return nil
+	}
+
+	estimate := &config.CapacityEstimate{
+		ThreadsPerDyno:    threadsPerDyno,
+		TargetUtilization: targetWebUtilization,
+		CurrentDynos:      webDynos.Quantity,
+	}
+
+	// Measured numbers first, overridden by whatever the user supplied
+	measured := false
+	if router := a.router; router != nil && router.Duration().Minutes() >= minRouterMinutes {
+		estimate.RequestsPerMinute = router.PeakRequestsPerMinute
+		if estimate.RequestsPerMinute == 0 {
+			estimate.RequestsPerMinute = router.RequestsPerMinute()
+		}
+		estimate.ServiceTimeMS = router.ServiceMeanMS
+		measured = true
+	}
+	supplied := false
+	if input := a.traffic; input != nil {
+		if input.RequestsPerMinute > 0 {
+			estimate.RequestsPerMinute = input.RequestsPerMinute
+			supplied = true
+		}
+		if input.ServiceTimeMS > 0 {
+			estimate.ServiceTimeMS = input.ServiceTimeMS
+			supplied = true
+		}
+	}
+	if estimate.RequestsPerMinute <= 0 || estimate.ServiceTimeMS <= 0 {
+		return nil
+	}
+
+	switch {
+	case measured && supplied:
+		estimate.Source = "router logs and user-supplied"
+	case measured:
+		estimate.Source = "router logs"
+	default:
+		estimate.Source = "user-supplied"
+	}
+
+	// Little's law: requests in flight = arrival rate × time in system
+	estimate.InFlight = estimate.RequestsPerMinute / 60 * estimate.ServiceTimeMS / 1000
+	if estimate.CurrentDynos > 0 {
+		estimate.CurrentUtilization = estimate.InFlight / float64(estimate.CurrentDynos*threadsPerDyno) * 100
+	}
+
+	required := int(math.Ceil(estimate.InFlight / (float64(threadsPerDyno) * targetWebUtilization / 100)))
+	if required < 1 {
+		required = 1
+	}
+	if estimate.CurrentDynos >= redundantWebDynos && required < redundantWebDynos {
+		required = redundantWebDynos
+	}
+	estimate.RequiredDynos = required
+
+	if price, err := a.pricingData.GetDynoPrice(webDynos.Size); err == nil {
+		estimate.MonthlyCostDelta = float64(required-estimate.CurrentDynos) * price.PriceMonthly
+		estimate.CostKnown = true
+	}
+
+	return estimate
+}
+
+// capacityRecommendation suggests scaling web dynos to the count the traffic needs,
+// or returns nil when the formation already matches it
+func capacityRecommendation(analysis *config.WebTierAnalysis) *config.Recommendation {
+	capacity := analysis.Capacity
+	if capacity == nil || capacity.RequiredDynos == capacity.CurrentDynos {
+		return nil
+	}
+
+	recommendation := &config.Recommendation{
+		Category: "web",
+		Severity: config.SeverityHigh,
+		Title:    "Scale Up Web Dynos",
+		Description: fmt.Sprintf("%.0f req/min × %.0f ms mean service time = %.1f requests in flight (%s); %d threads per dyno at %.0f%% target utilization need %d dynos",
+			capacity.RequestsPerMinute, capacity.ServiceTimeMS, capacity.InFlight, capacity.Source,
+			capacity.ThreadsPerDyno, capacity.TargetUtilization, capacity.RequiredDynos),
+		Current:   fmt.Sprintf("web=%d (%s, %.0f%% of threads busy)", capacity.CurrentDynos, analysis.DynoType, capacity.CurrentUtilization),
+		Suggested: fmt.Sprintf("heroku ps:scale web=%d", capacity.RequiredDynos),
+		Impact:    formatDynoCostImpact(capacity),
+		AutoApply: false, // Changes the formation, not an env var
+	}
+
+	// Eco and Basic dynos can't run more than one web dyno
+	if size := strings.ToLower(analysis.DynoType); capacity.RequiredDynos > 1 && (size == "eco" || size == "basic") {
+		recommendation.Suggested = fmt.Sprintf("heroku ps:type web=standard-1x, then heroku ps:scale web=%d", capacity.RequiredDynos)
+		recommendation.Impact += " at the current size"
+	}
+
+	switch {
+	case capacity.RequiredDynos < capacity.CurrentDynos:
+		recommendation.Category = "cost"
+		recommendation.Severity = config.SeverityLow
+		recommendation.Title = "Scale Down Web Dynos"
+	case capacity.CurrentUtilization >= 100:
+		recommendation.Severity = config.SeverityCritical
+	}
+
+	return recommendation
+}
+
+// formatDynoCostImpact describes the monthly cost of scaling to the required dyno count
+func formatDynoCostImpact(capacity *config.CapacityEstimate) string {
+	if !capacity.CostKnown {
+		return "Cost impact unknown"
+	}
+	sign := "+"
+	if capacity.MonthlyCostDelta < 0 {
+		sign = "-"
+	}
+	return fmt.Sprintf("%s$%.2f/month (%d → %d dynos)", sign, math.Abs(capacity.MonthlyCostDelta), capacity.CurrentDynos, capacity.RequiredDynos)
+}
@@ -0,0 +1,144 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+func TestEstimateCapacity(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	router := func(duration time.Duration, peak, serviceMS float64) *config.RouterMetrics {
+		return &config.RouterMetrics{
+			From:                  start,
+			To:                    start.Add(duration),
+			Requests:              int(peak * duration.Minutes()),
+			PeakRequestsPerMinute: peak,
+			ServiceMeanMS:         serviceMS,
+		}
+	}
+
+	type want struct {
+		source      string
+		inFlight    float64
+		utilization float64
+		required    int
+		costDelta   float64
+		costKnown   bool
+	}
+
+	tests := []struct {
+		name    string
+		dynos   config.DynoFormation
+		threads int
+		router  *config.RouterMetrics
+		traffic *config.TrafficInput
+		want    *want
+	}{
+		{
+			name:    "no traffic numbers",
+			dynos:   config.DynoFormation{Type: "web", Quantity: 2, Size: "Standard-1X"},
+			threads: 10,
+		},
+		{
+			name:    "no threads",
+			dynos:   config.DynoFormation{Type: "web", Quantity: 2, Size: "Standard-1X"},
+			router:  router(time.Hour, 600, 200),
+			threads: 0,
+		},
+		{
+			name:    "router sample too short",
+			dynos:   config.DynoFormation{Type: "web", Quantity: 2, Size: "Standard-1X"},
+			threads: 10,
+			router:  router(30*time.Second, 600, 200),
+		},
+		{
+			name:    "quiet app keeps two dynos",
+			dynos:   config.DynoFormation{Type: "web", Quantity: 2, Size: "Standard-1X"},
+			threads: 10,
+			// 10 req/s × 0.2 s = 2 requests in flight, 10% of 20 threads
+			router: router(time.Hour, 600, 200),
+			want:   &want{source: "router logs", inFlight: 2, utilization: 10, required: 2, costKnown: true},
+		},
+		{
+			name:    "single dyno stays at one",
+			dynos:   config.DynoFormation{Type: "web", Quantity: 1, Size: "Standard-1X"},
+			threads: 10,
+			router:  router(time.Hour, 600, 200),
+			want:    &want{source: "router logs", inFlight: 2, utilization: 20, required: 1, costKnown: true},
+		},
+		{
+			name:    "scale up",
+			dynos:   config.DynoFormation{Type: "web", Quantity: 2, Size: "Standard-2X"},
+			threads: 10,
+			// 100 req/s × 0.3 s = 30 in flight; 7 busy threads per dyno at 70%
+			router: router(time.Hour, 6000, 300),
+			want:   &want{source: "router logs", inFlight: 30, utilization: 150, required: 5, costDelta: 150, costKnown: true},
+		},
+		{
+			name:    "scale down",
+			dynos:   config.DynoFormation{Type: "web", Quantity: 8, Size: "Standard-1X"},
+			threads: 10,
+			router:  router(time.Hour, 1200, 500),
+			want:    &want{source: "router logs", inFlight: 10, utilization: 12.5, required: 2, costDelta: -150, costKnown: true},
+		},
+		{
+			name:    "user-supplied numbers override the router",
+			dynos:   config.DynoFormation{Type: "web", Quantity: 2, Size: "Standard-1X"},
+			threads: 10,
+			router:  router(time.Hour, 600, 200),
+			traffic: &config.TrafficInput{RequestsPerMinute: 6000},
+			want:    &want{source: "router logs and user-supplied", inFlight: 20, utilization: 100, required: 3, costDelta: 25, costKnown: true},
+		},
+		{
+			name:    "user-supplied only",
+			dynos:   config.DynoFormation{Type: "web", Quantity: 2, Size: "Standard-1X"},
+			threads: 10,
+			traffic: &config.TrafficInput{RequestsPerMinute: 6000, ServiceTimeMS: 100},
+			want:    &want{source: "user-supplied", inFlight: 10, utilization: 50, required: 2, costKnown: true},
+		},
+		{
+			name:    "user-supplied rate without a service time",
+			dynos:   config.DynoFormation{Type: "web", Quantity: 2, Size: "Standard-1X"},
+			threads: 10,
+			traffic: &config.TrafficInput{RequestsPerMinute: 6000},
+		},
+		{
+			name:    "unpriced dyno size",
+			dynos:   config.DynoFormation{Type: "web", Quantity: 2, Size: "Shield-M"},
+			threads: 10,
+			router:  router(time.Hour, 6000, 300),
+			want:    &want{source: "router logs", inFlight: 30, utilization: 150, required: 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := newTestAnalyzer(t, testApp{dynos: []config.DynoFormation{tt.dynos}})
+			analyzer.SetMeasurements(Measurements{Router: tt.router, Traffic: tt.traffic})
+
+			got := analyzer.estimateCapacity(&tt.dynos, tt.threads)
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("estimateCapacity() = %+v; want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("estimateCapacity() = nil")
+			}
+			if got.Source != tt.want.source || !within(got.InFlight, tt.want.inFlight) ||
+				!within(got.CurrentUtilization, tt.want.utilization) || got.RequiredDynos != tt.want.required ||
+				!within(got.MonthlyCostDelta, tt.want.costDelta) || got.CostKnown != tt.want.costKnown {
+				t.Errorf("estimateCapacity() = %+v; want %+v", got, *tt.want)
+			}
+		})
+	}
+}
+
+// within reports whether two computed numbers are equal but for rounding
+func within(got, want float64) bool {
+	diff := got - want
+	return diff < 1e-9 && diff > -1e-9
+}
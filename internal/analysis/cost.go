@@ -0,0 +1,65 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+	"github.com/leaharmstrong/heroku-calc/internal/pricing"
+)
+
+// analyzeCost totals the app's monthly bill at list price: the formation, its data add-ons and
+// its marketplace add-ons by category
+// Add-ons attached from other apps are billed there; one-off dynos are billed by the second and left out
+func (a *Analyzer) analyzeCost() *config.CostAnalysis {
+	if a.pricingData == nil {
+		return nil
+	}
+	cost := &config.CostAnalysis{}
+
+	for _, dyno := range a.dynos {
+		if dyno.Quantity == 0 {
+			continue
+		}
+		price, err := a.pricingData.GetDynoPrice(dyno.Size)
+		if err != nil {
+			cost.Unpriced = append(cost.Unpriced, fmt.Sprintf("%s dynos (%s)", dyno.Type, dyno.Size))
+			continue
+		}
+		cost.Items = append(cost.Items, config.CostItem{
+			Category: pricing.CategoryDynos,
+			Name:     dyno.Type,
+			Detail:   fmt.Sprintf("%d × %s", dyno.Quantity, dyno.Size),
+			Monthly:  price.PriceMonthly * float64(dyno.Quantity),
+		})
+	}
+
+	addons := []config.CostItem{}
+	for _, addon := range a.addons {
+		if addon.OwnerApp != "" {
+			continue
+		}
+		price, err := a.pricingData.GetAddonPrice(addon.ServiceName(), addon.PlanName())
+		if err != nil {
+			cost.Unpriced = append(cost.Unpriced, fmt.Sprintf("%s (%s)", addon.Name, addon.Plan))
+			continue
+		}
+		addons = append(addons, config.CostItem{
+			Category: price.Category,
+			Name:     addon.Name,
+			Detail:   price.Name,
+			Monthly:  price.PriceMonthly,
+		})
+	}
+
+	// Data add-ons follow the dynos, then marketplace categories alphabetically
+	sort.SliceStable(addons, func(i, j int) bool {
+		if (addons[i].Category == pricing.CategoryData) != (addons[j].Category == pricing.CategoryData) {
+			return addons[i].Category == pricing.CategoryData
+		}
+		return addons[i].Category < addons[j].Category
+	})
+	cost.Items = append(cost.Items, addons...)
+
+	return cost
+}
@@ -0,0 +1,74 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+	"github.com/leaharmstrong/heroku-calc/internal/pricing"
+)
+
+func TestAnalyzeCost(t *testing.T) {
+	analyzer := newTestAnalyzer(t, testApp{
+		dynos: []config.DynoFormation{
+			{Type: "web", Quantity: 2, Size: "Standard-2X"},
+			{Type: "worker", Quantity: 1, Size: "Performance-M"},
+			{Type: "clock", Quantity: 0, Size: "Basic"},
+			{Type: "edge", Quantity: 1, Size: "Mystery-9X"},
+		},
+		addons: []config.Addon{
+			postgresAddon("pg-main", "standard-0", "DATABASE_URL"),
+			{Name: "papertrail-1", Plan: "papertrail:fixa"},
+			{Name: "redis-main", Plan: "heroku-redis:premium-0", Service: pricing.HerokuRedisService, ConfigVars: []string{"REDIS_URL"}},
+			{Name: "kafka-1", Plan: "heroku-kafka:basic-0"},
+			{Name: "pg-shared", Plan: "heroku-postgresql:standard-2", Service: "heroku-postgresql", OwnerApp: "billing"},
+			{Name: "mystery-1", Plan: "mystery-addon:gold"},
+		},
+	})
+
+	cost := analyzer.analyzeCost()
+	if cost == nil {
+		t.Fatal("analyzeCost() = nil; want the bill")
+	}
+
+	// Scaled-down dynos and add-ons billed to another app are left out; data add-ons follow the dynos
+	wantItems := []config.CostItem{
+		{Category: pricing.CategoryDynos, Name: "web", Detail: "2 × Standard-2X", Monthly: 100},
+		{Category: pricing.CategoryDynos, Name: "worker", Detail: "1 × Performance-M", Monthly: 250},
+		{Category: pricing.CategoryData, Name: "pg-main", Detail: "Heroku Postgres Standard-0", Monthly: 50},
+		{Category: pricing.CategoryData, Name: "redis-main", Detail: "Heroku Data for Redis Premium-0", Monthly: 15},
+		{Category: pricing.CategoryData, Name: "kafka-1", Detail: "Apache Kafka on Heroku Basic-0", Monthly: 100},
+		{Category: "Logging", Name: "papertrail-1", Detail: "Papertrail Fixa", Monthly: 7},
+	}
+	if !reflect.DeepEqual(cost.Items, wantItems) {
+		t.Errorf("Items = %+v; want %+v", cost.Items, wantItems)
+	}
+	wantUnpriced := []string{"edge dynos (Mystery-9X)", "mystery-1 (mystery-addon:gold)"}
+	if !reflect.DeepEqual(cost.Unpriced, wantUnpriced) {
+		t.Errorf("Unpriced = %v; want %v", cost.Unpriced, wantUnpriced)
+	}
+
+	if total := cost.Total(); total != 522 {
+		t.Errorf("Total() = %.2f; want 522.00", total)
+	}
+	categories, totals := cost.CategoryTotals()
+	if want := []string{pricing.CategoryDynos, pricing.CategoryData, "Logging"}; !reflect.DeepEqual(categories, want) {
+		t.Errorf("CategoryTotals() categories = %v; want %v", categories, want)
+	}
+	if want := map[string]float64{pricing.CategoryDynos: 350, pricing.CategoryData: 165, "Logging": 7}; !reflect.DeepEqual(totals, want) {
+		t.Errorf("CategoryTotals() totals = %v; want %v", totals, want)
+	}
+}
+
+func TestAnalyzeCostWithoutPricing(t *testing.T) {
+	analyzer := newTestAnalyzer(t, testApp{dynos: []config.DynoFormation{{Type: "web", Quantity: 1, Size: "Basic"}}})
+	analyzer.pricingData = nil
+
+	cost := analyzer.analyzeCost()
+	if cost != nil {
+		t.Errorf("analyzeCost() = %+v; want nil", cost)
+	}
+	if cost.Total() != 0 {
+		t.Errorf("Total() of no bill = %.2f; want 0", cost.Total())
+	}
+}
@@ -4,10 +4,30 @@ import (
 	"fmt"
 
 	"github.com/leaharmstrong/heroku-calc/internal/config"
+	"github.com/leaharmstrong/heroku-calc/internal/project"
 )
 
-// analyzeDatabase analyzes database connection configuration
-func (a *Analyzer) analyzeDatabase() *config.DatabaseAnalysis {
+// analyzeDatabases analyzes every Postgres datastore, the one behind DATABASE_URL first
+// Without one, the first analysis reports DATABASE_URL missing
+func (a *Analyzer) analyzeDatabases() []*config.DatabaseAnalysis {
+	analyses := []*config.DatabaseAnalysis{}
+	for _, store := range a.databases {
+		store := store
+		if store.HasConfigVar("DATABASE_URL") {
+			analyses = append(analyses, a.analyzeDatabase(&store))
+		} else {
+			analyses = append(analyses, a.analyzeSecondaryDatabase(&store))
+		}
+	}
+	if len(analyses) == 0 || !analyses[0].Datastore.HasConfigVar("DATABASE_URL") {
+		analyses = append([]*config.DatabaseAnalysis{a.analyzeDatabase(nil)}, analyses...)
+	}
+	return analyses
+}
+
+// analyzeDatabase analyzes the connections the formation opens to the database behind DATABASE_URL
+// The datastore is nil when DATABASE_URL is missing or matches no add-on
+func (a *Analyzer) analyzeDatabase(store *config.Datastore) *config.DatabaseAnalysis {
 	analysis := &config.DatabaseAnalysis{
 		DatabaseURL:      "unknown",
 		PostgresPlan:     "unknown",
@@ -32,61 +52,157 @@ func (a *Analyzer) analyzeDatabase() *config.DatabaseAnalysis {
 	}
 
 	analysis.DatabaseURL = "present"
+	a.setDatabasePlan(analysis, store)
+
+	// Calculate connection requirements per scaled process type, with a pool for each
+	// config/database.yml database on this server
+	analysis.Processes, analysis.Budgets = a.processUsage(a.databaseEntriesFor(store, true))
+	for _, usage := range analysis.Processes {
+		analysis.CurrentUsage += usage.Connections
+
+		switch {
+		case usage.Type == "web":
+			analysis.WebDynos = usage.Dynos
+
+			// WEB_CONCURRENCY (Puma workers per dyno)
+			analysis.WorkersPerDyno = a.webConcurrency().Value
+
+			// RAILS_MAX_THREADS (threads per worker)
+			analysis.ThreadsPerWorker = a.railsMaxThreads().Value
+		case usage.Kind == string(project.KindSidekiq):
+			analysis.SidekiqDynos += usage.Dynos
+			analysis.SidekiqThreads = usage.Threads
+			if warning := a.sidekiqSettings(usage.Type).Warning; warning != "" {
+				analysis.Issues = append(analysis.Issues, warning)
+			}
+		}
+	}
 
-	// Get Postgres plan
-	postgresPlan := a.extractPostgresPlan()
-	analysis.PostgresPlan = postgresPlan
+	// Check the ActiveRecord pool against the threads that share it
+	pools, poolIssues := a.databasePools()
+	analysis.Pools = pools
+	analysis.Issues = append(analysis.Issues, poolIssues...)
+	if len(pools) > 0 {
+		analysis.Environment = a.railsEnv()
+	}
 
-	// Get max connections from pricing data
-	if postgresPlan != "unknown" {
-		if pgPrice, err := a.pricingData.GetPostgresPrice(postgresPlan); err == nil {
-			analysis.MaxConnections = pgPrice.MaxConnections
-		} else {
-			analysis.Issues = append(analysis.Issues, fmt.Sprintf("Unknown Postgres plan: %s", postgresPlan))
+	// Rails fails to connect to a database whose URL env var isn't set
+	for _, pool := range pools {
+		if pool.URLEnv != "" && !a.hasEnvVar(pool.URLEnv) {
+			analysis.Issues = append(analysis.Issues, fmt.Sprintf(
+				"%s: %s reads %s, which is not set on the app", project.DatabaseConfigPath, pool.Name, pool.URLEnv))
+		}
+	}
+
+	for _, usage := range analysis.Processes {
+		if usage.Pool > 0 && usage.Pool < usage.ThreadsPerProcess() {
+			analysis.Issues = append(analysis.Issues, fmt.Sprintf(
+				"%s: pool of %d is smaller than %d threads per process (ActiveRecord::ConnectionTimeoutError risk)",
+				usage.Type, usage.Pool, usage.ThreadsPerProcess()))
 		}
 	}
 
-	// Calculate connection requirements
+	// Check DB_POOL setting
+	dbPool := a.getEnvVarInt("DB_POOL", 0)
+	if dbPool > 0 {
+		switch {
+		case len(pools) == 0:
+			// If DB_POOL is set, it overrides RAILS_MAX_THREADS for pool size
+			analysis.Issues = append(analysis.Issues, fmt.Sprintf("DB_POOL is set to %d (overrides RAILS_MAX_THREADS)", dbPool))
+		case !a.databaseReadsEnv("DB_POOL"):
+			analysis.Issues = append(analysis.Issues, fmt.Sprintf("DB_POOL is set to %d but %s doesn't read it", dbPool, project.DatabaseConfigPath))
+		}
+	}
 
-	// Web dynos
-	webDynos := a.getDynosByType("web")
-	if webDynos != nil {
-		analysis.WebDynos = webDynos.Quantity
+	analysis.TotalRequired = analysis.CurrentUsage
 
-		// WEB_CONCURRENCY (Puma workers per dyno)
-		analysis.WorkersPerDyno = a.getEnvVarInt("WEB_CONCURRENCY", 2)
+	// Behind a pooler, the plan's limit applies to the pooler's server connections
+	poolers, poolerIssues := a.connectionPoolers(analysis)
+	analysis.Poolers = poolers
+	analysis.Issues = append(analysis.Issues, poolerIssues...)
+	for _, pooler := range poolers {
+		analysis.TotalRequired += pooler.ServerConnections - pooler.ClientConnections
+	}
 
-		// RAILS_MAX_THREADS (threads per worker)
-		analysis.ThreadsPerWorker = a.getEnvVarInt("RAILS_MAX_THREADS", 5)
+	checkUnaccounted(analysis)
+	setDatabaseStatus(analysis)
+	return analysis
+}
 
-		// Total web connections = dynos × workers × threads
-		webConnections := analysis.WebDynos * analysis.WorkersPerDyno * analysis.ThreadsPerWorker
-		analysis.CurrentUsage += webConnections
+// analyzeSecondaryDatabase analyzes a database the app doesn't reach through DATABASE_URL,
+// such as a follower or a database attached from another app
+// The formation's connections are modelled for the config/database.yml databases reading one of
+// its config vars; without any, only measured connections count
+func (a *Analyzer) analyzeSecondaryDatabase(store *config.Datastore) *config.DatabaseAnalysis {
+	analysis := &config.DatabaseAnalysis{
+		DatabaseURL: "present",
+		Status:      config.StatusUnknown,
+		Issues:      []string{},
+	}
+	a.setDatabasePlan(analysis, store)
+
+	// Rails only connects to databases config/database.yml names, e.g. a replica role on a follower
+	entries := a.databaseEntriesFor(store, false)
+	if len(entries) > 0 {
+		analysis.Processes, analysis.Budgets = a.processUsage(entries)
+		for _, usage := range analysis.Processes {
+			analysis.CurrentUsage += usage.Connections
+		}
+		analysis.TotalRequired = analysis.CurrentUsage
+
+		checkUnaccounted(analysis)
+		setDatabaseStatus(analysis)
+		return analysis
+	}
+	if len(a.databaseEntries()) > 0 {
+		analysis.Issues = append(analysis.Issues, fmt.Sprintf(
+			"No database in %s reads %s (follower or used outside the app?)", project.DatabaseConfigPath, store.ConfigVars[0]))
 	}
 
-	// Worker/Sidekiq dynos
-	workerDynos := a.getDynosByType("worker")
-	if workerDynos != nil {
-		analysis.SidekiqDynos = workerDynos.Quantity
+	if analysis.Measured == nil {
+		analysis.Issues = append(analysis.Issues, fmt.Sprintf("Connections through %s are not modelled without pg:info", store.ConfigVars[0]))
+		return analysis
+	}
+	analysis.CurrentUsage = analysis.Measured.Connections
+	analysis.TotalRequired = analysis.Measured.Connections
 
-		// Sidekiq concurrency
-		concurrency := a.getEnvVarInt("SIDEKIQ_CONCURRENCY", 10)
-		analysis.SidekiqThreads = concurrency
+	setDatabaseStatus(analysis)
+	return analysis
+}
 
-		// Total worker connections = dynos × concurrency
-		workerConnections := analysis.SidekiqDynos * concurrency
-		analysis.CurrentUsage += workerConnections
+// checkUnaccounted compares the estimate with what the database actually sees
+func checkUnaccounted(analysis *config.DatabaseAnalysis) {
+	if unaccounted := analysis.UnaccountedConnections(); unaccounted > 0 {
+		analysis.Issues = append(analysis.Issues, fmt.Sprintf(
+			"pg:info shows %d connections but the formation accounts for %d: %d may be leaked or idle (check heroku pg:ps) or come from outside the formation",
+			analysis.Measured.Connections, analysis.TotalRequired, unaccounted))
 	}
+}
 
-	// Check DB_POOL setting
-	dbPool := a.getEnvVarInt("DB_POOL", 0)
-	if dbPool > 0 {
-		// If DB_POOL is set, it overrides RAILS_MAX_THREADS for pool size
-		analysis.Issues = append(analysis.Issues, fmt.Sprintf("DB_POOL is set to %d (overrides RAILS_MAX_THREADS)", dbPool))
+// setDatabasePlan records the datastore's plan and its connection limit, from pg:info
+// when available, otherwise from pricing data
+func (a *Analyzer) setDatabasePlan(analysis *config.DatabaseAnalysis, store *config.Datastore) {
+	analysis.Datastore = store
+	analysis.PostgresPlan = "unknown"
+	if store == nil {
+		return
 	}
+	analysis.PostgresPlan = store.Plan
 
-	analysis.TotalRequired = analysis.CurrentUsage
+	analysis.Measured = a.postgresInfo[store.ConfigVars[0]]
+	if analysis.Measured != nil && analysis.Measured.ConnectionLimit > 0 {
+		analysis.MaxConnections = analysis.Measured.ConnectionLimit
+	} else if store.Plan != "unknown" {
+		if pgPrice, err := a.pricingData.GetPostgresPrice(store.Plan); err == nil {
+			analysis.MaxConnections = pgPrice.MaxConnections
+		} else {
+			analysis.Issues = append(analysis.Issues, fmt.Sprintf("Unknown Postgres plan: %s", store.Plan))
+		}
+	}
+}
 
+// setDatabaseStatus sets the status from the buffer left between TotalRequired and the connection limit
+func setDatabaseStatus(analysis *config.DatabaseAnalysis) {
 	// Calculate buffer percentage
 	if analysis.MaxConnections > 0 {
 		analysis.BufferPercent = float64(analysis.MaxConnections-analysis.TotalRequired) / float64(analysis.MaxConnections) * 100
@@ -98,13 +214,22 @@ func (a *Analyzer) analyzeDatabase() *config.DatabaseAnalysis {
 		} else if analysis.BufferPercent < 20 {
 			analysis.Status = config.StatusCritical
 			analysis.Issues = append(analysis.Issues, fmt.Sprintf("Very low buffer: only %.1f%% available", analysis.BufferPercent))
-		} else if analysis.BufferPercent < 50 {
+		} else if analysis.BufferPercent < 50 && len(analysis.Poolers) == 0 {
+			// Poolers queue bursts instead of opening more server connections
 			analysis.Status = config.StatusWarning
-			analysis.Issues = append(analysis.Issues, fmt.Sprintf("Low buffer: %.1f%% available (recommend 50%+ for bursts)", analysis.BufferPercent))
+			analysis.Issues = append(analysis.Issues, fmt.Sprintf("Low buffer: %.1f%% available (recommend 50%%+ for bursts)", analysis.BufferPercent))
 		} else {
 			analysis.Status = config.StatusOptimal
 		}
-	}
 
-	return analysis
+		// Connections already open count against the limit whatever the estimate says;
+		// poolers keep theirs open by design
+		if measured := analysis.Measured; measured != nil && analysis.Status == config.StatusOptimal && len(analysis.Poolers) == 0 {
+			measuredPercent := float64(measured.Connections) / float64(analysis.MaxConnections) * 100
+			if measuredPercent >= 50 {
+				analysis.Status = config.StatusWarning
+				analysis.Issues = append(analysis.Issues, fmt.Sprintf("Measured usage at %.1f%% of the connection limit", measuredPercent))
+			}
+		}
+	}
 }
@@ -0,0 +1,186 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+// replicaDatabaseYML declares a primary and a replica read through the follower's config var
+const replicaDatabaseYML = `production:
+  primary:
+    url: <%= ENV["DATABASE_URL"] %>
+    pool: <%= ENV.fetch("RAILS_MAX_THREADS") { 5 } %>
+  primary_replica:
+    url: <%= ENV["HEROKU_POSTGRESQL_CHARCOAL_URL"] %>
+    pool: 3
+    replica: true
+`
+
+func TestAnalyzeDatabases(t *testing.T) {
+	webAndWorker := []config.DynoFormation{
+		{Type: "web", Quantity: 2, Size: "Standard-2X"},
+		{Type: "worker", Quantity: 1, Size: "Standard-1X"},
+	}
+	threads := map[string]string{
+		"DATABASE_URL":        primaryDatabaseURL,
+		"WEB_CONCURRENCY":     "2",
+		"RAILS_MAX_THREADS":   "5",
+		"SIDEKIQ_CONCURRENCY": "10",
+	}
+	withFollower := map[string]string{"HEROKU_POSTGRESQL_CHARCOAL_URL": followerDatabaseURL}
+	for name, value := range threads {
+		withFollower[name] = value
+	}
+	primary := postgresAddon("pg-main", "standard-0", "DATABASE_URL")
+	follower := postgresAddon("pg-follower", "standard-2", "HEROKU_POSTGRESQL_CHARCOAL_URL")
+
+	type want struct {
+		plan     string
+		max      int
+		required int
+		status   config.AnalysisStatus
+		issue    string // Substring of one of the issues ("" to skip)
+	}
+
+	tests := []struct {
+		name string
+		app  testApp
+		want []want
+	}{
+		{
+			name: "no DATABASE_URL",
+			app:  testApp{dynos: webAndWorker},
+			want: []want{{plan: "unknown", status: config.StatusWarning, issue: "DATABASE_URL not found"}},
+		},
+		{
+			name: "web and Sidekiq within the limit",
+			app:  testApp{env: threads, dynos: webAndWorker, addons: []config.Addon{primary}},
+			// web 2 dynos × 2 workers × 5 threads + worker 10 threads
+			want: []want{{plan: "standard-0", max: 120, required: 30, status: config.StatusOptimal}},
+		},
+		{
+			name: "connection exhaustion",
+			app: testApp{
+				env:    threads,
+				dynos:  []config.DynoFormation{{Type: "web", Quantity: 12, Size: "Standard-2X"}},
+				addons: []config.Addon{primary},
+			},
+			want: []want{{plan: "standard-0", max: 120, required: 120, status: config.StatusCritical, issue: "Connection exhaustion"}},
+		},
+		{
+			name: "low buffer",
+			app: testApp{
+				env:    threads,
+				dynos:  []config.DynoFormation{{Type: "web", Quantity: 7, Size: "Standard-2X"}},
+				addons: []config.Addon{primary},
+			},
+			want: []want{{plan: "standard-0", max: 120, required: 70, status: config.StatusWarning, issue: "Low buffer"}},
+		},
+		{
+			name: "pg:info shows more connections than the formation",
+			app: testApp{
+				env:          threads,
+				dynos:        webAndWorker,
+				addons:       []config.Addon{primary},
+				postgresInfo: &config.PostgresInfo{Connections: 70, ConnectionLimit: 120},
+			},
+			want: []want{{plan: "standard-0", max: 120, required: 30, status: config.StatusWarning, issue: "40 may be leaked"}},
+		},
+		{
+			name: "unknown plan",
+			app: testApp{
+				env:    threads,
+				dynos:  webAndWorker,
+				addons: []config.Addon{postgresAddon("pg-main", "legacy-9", "DATABASE_URL")},
+			},
+			want: []want{{plan: "legacy-9", required: 30, status: config.StatusUnknown, issue: "Unknown Postgres plan: legacy-9"}},
+		},
+		{
+			name: "follower without pg:info",
+			app:  testApp{env: withFollower, dynos: webAndWorker, addons: []config.Addon{primary, follower}},
+			want: []want{
+				{plan: "standard-0", max: 120, required: 30, status: config.StatusOptimal},
+				{plan: "standard-2", max: 240, status: config.StatusUnknown, issue: "not modelled without pg:info"},
+			},
+		},
+		{
+			name: "follower measured by pg:info",
+			app: testApp{
+				env:               withFollower,
+				dynos:             webAndWorker,
+				addons:            []config.Addon{primary, follower},
+				postgresInfoByVar: map[string]*config.PostgresInfo{"HEROKU_POSTGRESQL_CHARCOAL_URL": {Connections: 200, ConnectionLimit: 240}},
+			},
+			want: []want{
+				{plan: "standard-0", max: 120, required: 30, status: config.StatusOptimal},
+				{plan: "standard-2", max: 240, required: 200, status: config.StatusCritical, issue: "Very low buffer"},
+			},
+		},
+		{
+			name: "replica in database.yml",
+			app: testApp{
+				env:    withFollower,
+				dynos:  webAndWorker,
+				addons: []config.Addon{primary, follower},
+				files:  map[string]string{"config/database.yml": replicaDatabaseYML},
+			},
+			// Pools of 5 and 3: web 2 × 2 × 5 + worker 5 on the primary, 2 × 2 × 3 + 3 on the replica
+			want: []want{
+				{plan: "standard-0", max: 120, required: 25, status: config.StatusOptimal},
+				{plan: "standard-2", max: 240, required: 15, status: config.StatusOptimal},
+			},
+		},
+		{
+			name: "follower no database.yml entry reads",
+			app: testApp{
+				env:    withFollower,
+				dynos:  webAndWorker,
+				addons: []config.Addon{primary, follower},
+				files:  map[string]string{"config/database.yml": "production:\n  url: <%= ENV[\"DATABASE_URL\"] %>\n"},
+			},
+			// ActiveRecord's default pool of 5 caps the worker's 10 threads
+			want: []want{
+				{plan: "standard-0", max: 120, required: 25, status: config.StatusOptimal, issue: "worker: pool of 5 is smaller than 10 threads"},
+				{plan: "standard-2", max: 240, status: config.StatusUnknown, issue: "No database in config/database.yml reads HEROKU_POSTGRESQL_CHARCOAL_URL"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyses := newTestAnalyzer(t, tt.app).analyzeDatabases()
+			if len(analyses) != len(tt.want) {
+				t.Fatalf("got %d analyses; want %d", len(analyses), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := analyses[i]
+				if got.PostgresPlan != want.plan || got.MaxConnections != want.max || got.TotalRequired != want.required || got.Status != want.status {
+					t.Errorf("analysis %d: plan %s, %d/%d connections, %s; want plan %s, %d/%d connections, %s (issues %q)",
+						i, got.PostgresPlan, got.TotalRequired, got.MaxConnections, got.Status,
+						want.plan, want.required, want.max, want.status, got.Issues)
+				}
+				if want.issue != "" && !hasIssue(got.Issues, want.issue) {
+					t.Errorf("analysis %d: issues %q; want one containing %q", i, got.Issues, want.issue)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeSecondaryDatabaseWithoutProject(t *testing.T) {
+	analyzer := newTestAnalyzer(t, testApp{
+		env:    map[string]string{"DATABASE_URL": primaryDatabaseURL, "HEROKU_POSTGRESQL_CHARCOAL_URL": followerDatabaseURL},
+		dynos:  []config.DynoFormation{{Type: "web", Quantity: 2, Size: "Standard-2X"}},
+		addons: []config.Addon{postgresAddon("pg-follower", "standard-2", "HEROKU_POSTGRESQL_CHARCOAL_URL")},
+	})
+
+	// Without config/database.yml the formation isn't assumed to connect to a follower
+	analysis := analyzer.analyzeSecondaryDatabase(&analyzer.databases[0])
+	if analysis.CurrentUsage != 0 || len(analysis.Processes) != 0 {
+		t.Errorf("modelled %d connections from %d process types to a follower", analysis.CurrentUsage, len(analysis.Processes))
+	}
+	if hasIssue(analysis.Issues, "No database in") {
+		t.Errorf("issues %q blame a database.yml the project doesn't have", analysis.Issues)
+	}
+}
@@ -0,0 +1,93 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+	"github.com/leaharmstrong/heroku-calc/internal/logs"
+)
+
+// Measured memory thresholds, as a percentage of the dyno's memory quota
+const (
+	memoryWarningPercent = 85  // p95 above this leaves little room for spikes
+	memoryQuotaPercent   = 100 // Above the quota the dyno swaps and logs R14
+)
+
+// analyzeMemory reports measured memory and R14 risk for every process type in the runtime metrics
+func (a *Analyzer) analyzeMemory() *config.MemoryAnalysis {
+	if a.runtime == nil {
+		return nil
+	}
+
+	analysis := &config.MemoryAnalysis{
+		Source: a.runtime.Source,
+		From:   a.runtime.From,
+		To:     a.runtime.To,
+		Status: config.StatusOptimal,
+		Issues: []string{},
+	}
+
+	for _, processType := range logs.ProcessTypes(a.runtime) {
+		process := a.processRuntime(processType)
+		analysis.Processes = append(analysis.Processes, *process)
+
+		status, issue := memoryRisk(process)
+		if issue != "" {
+			analysis.Issues = append(analysis.Issues, issue)
+		}
+		analysis.Status = worseStatus(analysis.Status, status)
+	}
+
+	return analysis
+}
+
+// processRuntime returns the runtime metrics of a process type, filling in the quota
+// from the formation's dyno size when the logs didn't report it
+func (a *Analyzer) processRuntime(processType string) *config.ProcessRuntime {
+	if a.runtime == nil || a.runtime.Processes[processType] == nil {
+		return nil
+	}
+
+	process := *a.runtime.Processes[processType]
+	if process.QuotaMB == 0 {
+		if dynos := a.getDynosByType(processType); dynos != nil {
+			if price, err := a.pricingData.GetDynoPrice(dynos.Size); err == nil {
+				process.QuotaMB = float64(price.MemoryMB)
+			}
+		}
+	}
+	return &process
+}
+
+// memoryRisk rates a process type's R14 risk from its measured memory
+func memoryRisk(process *config.ProcessRuntime) (config.AnalysisStatus, string) {
+	errors := process.R14Errors + process.R15Errors
+	switch {
+	case errors > 0:
+		return config.StatusCritical, fmt.Sprintf("%s: %d memory quota errors (R14/R15), peak %.0f MB of %.0f MB",
+			process.Type, errors, process.MemoryPeakMB, process.QuotaMB)
+	case process.QuotaMB == 0 || process.Samples == 0:
+		return config.StatusUnknown, ""
+	case process.PeakPercent() >= memoryQuotaPercent:
+		return config.StatusCritical, fmt.Sprintf("%s: peak memory %.0f MB exceeds the %.0f MB quota (R14 swapping)",
+			process.Type, process.MemoryPeakMB, process.QuotaMB)
+	case process.P95Percent() >= memoryWarningPercent:
+		return config.StatusWarning, fmt.Sprintf("%s: p95 memory at %.0f%% of the %.0f MB quota (R14 risk under load)",
+			process.Type, process.P95Percent(), process.QuotaMB)
+	}
+	return config.StatusOptimal, ""
+}
+
+// worseStatus returns the more severe of two statuses
+func worseStatus(a, b config.AnalysisStatus) config.AnalysisStatus {
+	rank := map[config.AnalysisStatus]int{
+		config.StatusUnknown:  0,
+		config.StatusOptimal:  1,
+		config.StatusWarning:  2,
+		config.StatusCritical: 3,
+	}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,100 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+	"github.com/leaharmstrong/heroku-calc/internal/project"
+)
+
+// railsEnv returns the environment the app boots in on Heroku
+func (a *Analyzer) railsEnv() string {
+	for _, name := range []string{"RAILS_ENV", "RACK_ENV"} {
+		if value := a.envVars[name]; value != "" {
+			return value
+		}
+	}
+	return "production"
+}
+
+// databaseEntries returns the databases config/database.yml declares for the app's environment
+func (a *Analyzer) databaseEntries() []project.DatabaseEntry {
+	if a.project == nil {
+		return nil
+	}
+	return a.project.Database.Databases(a.railsEnv())
+}
+
+// primaryDatabaseEntry returns the first database that isn't a replica, or the zero entry
+// (primary, reading DATABASE_URL with ActiveRecord's defaults) without config/database.yml
+func (a *Analyzer) primaryDatabaseEntry() project.DatabaseEntry {
+	for _, entry := range a.databaseEntries() {
+		if !entry.Replica {
+			return entry
+		}
+	}
+	return project.DatabaseEntry{Name: "primary"}
+}
+
+// databaseEntriesFor returns the config/database.yml databases that connect to a datastore
+// The primary datastore also gets the databases without a URL env var of their own, which share
+// DATABASE_URL's server, and falls back to the primary database when none match
+func (a *Analyzer) databaseEntriesFor(store *config.Datastore, primary bool) []project.DatabaseEntry {
+	entries := []project.DatabaseEntry{}
+	for _, entry := range a.databaseEntries() {
+		urlEnv := entry.URLEnv()
+		if store.HasConfigVar(urlEnv) || (primary && (urlEnv == "" || urlEnv == "DATABASE_URL")) {
+			entries = append(entries, entry)
+		}
+	}
+	if primary && len(entries) == 0 && len(a.databaseEntries()) > 0 {
+		entries = append(entries, a.primaryDatabaseEntry())
+	}
+	return entries
+}
+
+// databasePools evaluates the pool of every database against the app's env vars
+// Pools that can't be interpreted are returned with Pool 0 and described in the issues
+func (a *Analyzer) databasePools() ([]config.DatabasePool, []string) {
+	pools := []config.DatabasePool{}
+	issues := []string{}
+
+	for _, entry := range a.databaseEntries() {
+		pool, ok := entry.Pool(a.envVars)
+		if !ok {
+			pool = 0
+			issues = append(issues, fmt.Sprintf("%s: could not interpret `pool: %s` for %s", project.DatabaseConfigPath, entry.PoolExpr, entry.Name))
+		}
+
+		pools = append(pools, config.DatabasePool{
+			Name:    entry.Name,
+			Pool:    pool,
+			PoolSet: entry.PoolExpr != "",
+			URLEnv:  entry.URLEnv(),
+			Replica: entry.Replica,
+		})
+	}
+
+	return pools, issues
+}
+
+// primaryPool returns the pool size of the primary database, or 0 if unknown
+func (a *Analyzer) primaryPool() int {
+	pools, _ := a.databasePools()
+	for _, pool := range pools {
+		if !pool.Replica {
+			return pool.Pool
+		}
+	}
+	return 0
+}
+
+// databaseReadsEnv returns true if any database's pool can be changed through the named env var
+func (a *Analyzer) databaseReadsEnv(name string) bool {
+	for _, entry := range a.databaseEntries() {
+		if entry.ReadsEnv(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+	"github.com/leaharmstrong/heroku-calc/internal/project"
+)
+
+// Heroku Postgres connection pooling
+const (
+	herokuPoolerConfigVar     = "DATABASE_CONNECTION_POOL_URL"
+	herokuPoolerServerPercent = 75 // Share of the plan's connection limit the pooler opens at most
+)
+
+// pgbouncer buildpack defaults
+const (
+	pgbouncerStartCommand    = "bin/start-pgbouncer" // Also matches bin/start-pgbouncer-stunnel
+	pgbouncerDefaultPoolSize = 1                     // PGBOUNCER_DEFAULT_POOL_SIZE
+	pgbouncerReservePoolSize = 1                     // PGBOUNCER_RESERVE_POOL_SIZE
+	pgbouncerMaxClientConn   = 100                   // PGBOUNCER_MAX_CLIENT_CONN
+)
+
+// connectionPoolers detects the PgBouncers between the processes and the primary database: the
+// pgbouncer buildpack started by Procfile commands, and Heroku's pooler attached as
+// DATABASE_CONNECTION_POOL_URL for the other processes, and models the server connections they open
+// It returns no poolers when connections go straight to Postgres
+func (a *Analyzer) connectionPoolers(analysis *config.DatabaseAnalysis) ([]*config.ConnectionPooler, []string) {
+	poolers := []*config.ConnectionPooler{}
+	pooled := make(map[string]bool)
+
+	buildpack, issues := a.pgbouncerBuildpack(analysis)
+	if buildpack != nil {
+		poolers = append(poolers, buildpack)
+		for _, processType := range buildpack.Processes {
+			pooled[processType] = true
+		}
+	}
+	heroku, herokuIssues := a.herokuPooler(analysis, pooled)
+	issues = append(issues, herokuIssues...)
+	if heroku != nil {
+		poolers = append(poolers, heroku)
+	}
+
+	for _, pooler := range poolers {
+		if pooler.Mode == "transaction" {
+			issues = append(issues, a.transactionModeIssues(pooler)...)
+		}
+	}
+	return poolers, issues
+}
+
+// pgbouncerBuildpack models the PgBouncer the buildpack runs in each dyno whose command
+// starts with bin/start-pgbouncer
+// Each dyno opens at most the default and reserve pool to Postgres, however many threads it runs
+func (a *Analyzer) pgbouncerBuildpack(analysis *config.DatabaseAnalysis) (*config.ConnectionPooler, []string) {
+	issues := []string{}
+	installed, known := a.hasBuildpack("pgbouncer")
+
+	pooler := &config.ConnectionPooler{
+		Kind: config.PoolerPgBouncer,
+		Mode: a.envVars["PGBOUNCER_POOL_MODE"],
+	}
+	if pooler.Mode == "" {
+		pooler.Mode = "transaction"
+	}
+	serverPerDyno := a.getEnvVarInt("PGBOUNCER_DEFAULT_POOL_SIZE", pgbouncerDefaultPoolSize) +
+		a.getEnvVarInt("PGBOUNCER_RESERVE_POOL_SIZE", pgbouncerReservePoolSize)
+	maxClients := a.getEnvVarInt("PGBOUNCER_MAX_CLIENT_CONN", pgbouncerMaxClientConn)
+
+	for _, usage := range analysis.Processes {
+		if !strings.Contains(a.processCommand(usage.Type), pgbouncerStartCommand) || usage.Dynos == 0 {
+			continue
+		}
+		clientsPerDyno := usage.Connections / usage.Dynos
+		pooler.Processes = append(pooler.Processes, usage.Type)
+		pooler.ClientConnections += usage.Connections
+		pooler.ServerConnections += usage.Dynos * min(clientsPerDyno, serverPerDyno)
+		pooler.MaxServerConnections += usage.Dynos * serverPerDyno
+
+		if clientsPerDyno > maxClients {
+			issues = append(issues, fmt.Sprintf(
+				"%s: %d connections per dyno exceed PGBOUNCER_MAX_CLIENT_CONN (%d): PgBouncer will refuse the rest",
+				usage.Type, clientsPerDyno, maxClients))
+		}
+	}
+
+	if len(pooler.Processes) == 0 {
+		if installed && a.hasProcfile() {
+			issues = append(issues, fmt.Sprintf(
+				"The pgbouncer buildpack is installed but no Procfile command starts with %s: every connection goes straight to Postgres", pgbouncerStartCommand))
+		}
+		return nil, issues
+	}
+	if known && !installed {
+		issues = append(issues, fmt.Sprintf(
+			"%s runs %s but the pgbouncer buildpack is not installed", strings.Join(pooler.Processes, ", "), pgbouncerStartCommand))
+	}
+	return pooler, issues
+}
+
+// herokuPooler models Heroku's connection pooling for the processes that connect through
+// DATABASE_CONNECTION_POOL_URL, via config/database.yml or their Procfile command, leaving out
+// those already pooled in the dyno
+// The pooler opens at most 75% of the plan's connections, however many clients it serves
+func (a *Analyzer) herokuPooler(analysis *config.DatabaseAnalysis, pooled map[string]bool) (*config.ConnectionPooler, []string) {
+	issues := []string{}
+	if !a.hasEnvVar(herokuPoolerConfigVar) {
+		return nil, issues
+	}
+
+	// Without the project files there is no telling which processes use it
+	viaDatabaseConfig := a.primaryDatabaseEntry().URLEnv() == herokuPoolerConfigVar
+	assumed := !a.hasProcfile() && len(a.databaseEntries()) == 0
+	if assumed {
+		issues = append(issues, fmt.Sprintf(
+			"Assuming every process connects through %s (no Procfile or %s to check)", herokuPoolerConfigVar, project.DatabaseConfigPath))
+	}
+
+	pooler := &config.ConnectionPooler{
+		Kind: config.PoolerHeroku,
+		Mode: "transaction",
+	}
+	for _, usage := range analysis.Processes {
+		if pooled[usage.Type] {
+			continue
+		}
+		if !assumed && !viaDatabaseConfig && !strings.Contains(a.processCommand(usage.Type), herokuPoolerConfigVar) {
+			continue
+		}
+		pooler.Processes = append(pooler.Processes, usage.Type)
+		pooler.ClientConnections += usage.Connections
+	}
+
+	if len(pooler.Processes) == 0 {
+		issues = append(issues, fmt.Sprintf(
+			"%s is attached but neither %s nor the Procfile uses it", herokuPoolerConfigVar, project.DatabaseConfigPath))
+		return nil, issues
+	}
+
+	pooler.ServerConnections = pooler.ClientConnections
+	if analysis.MaxConnections > 0 {
+		pooler.MaxServerConnections = analysis.MaxConnections * herokuPoolerServerPercent / 100
+		pooler.ServerConnections = min(pooler.ClientConnections, pooler.MaxServerConnections)
+	}
+	return pooler, issues
+}
+
+// transactionModeIssues flags ActiveRecord settings that break when each transaction may run
+// on a different server connection
+func (a *Analyzer) transactionModeIssues(pooler *config.ConnectionPooler) []string {
+	issues := []string{}
+	entries := a.databaseEntries()
+	if len(entries) == 0 && !a.stack().Detected {
+		return issues
+	}
+	entry := a.primaryDatabaseEntry()
+
+	if !entry.PreparedStatementsDisabled() {
+		issues = append(issues, fmt.Sprintf(
+			"%s doesn't set prepared_statements: false behind %s: queries can fail with \"prepared statement does not exist\"",
+			project.DatabaseConfigPath, pooler.Describe()))
+	}
+
+	// Migrations hold a session-level advisory lock, which transaction pooling can't keep
+	release := a.processCommand("release")
+	migratesThroughPooler := strings.Contains(release, pgbouncerStartCommand)
+	if pooler.Kind == config.PoolerHeroku {
+		migratesThroughPooler = entry.URLEnv() == herokuPoolerConfigVar || strings.Contains(release, herokuPoolerConfigVar)
+	}
+	if migratesThroughPooler && !entry.AdvisoryLocksDisabled() {
+		issues = append(issues, fmt.Sprintf(
+			"Release phase migrations run through %s: set advisory_locks: false or migrate through DATABASE_URL",
+			pooler.Describe()))
+	}
+
+	return issues
+}
+
+// hasBuildpack reports whether a buildpack whose name contains name is installed,
+// and whether the app's buildpacks are known at all
+func (a *Analyzer) hasBuildpack(name string) (installed, known bool) {
+	for _, buildpack := range a.buildpacks {
+		if strings.Contains(strings.ToLower(buildpack), name) {
+			return true, true
+		}
+	}
+	return false, a.buildpacks != nil
+}
+
+// processCommand returns the Procfile command of a process type, or "" if the project doesn't declare it
+func (a *Analyzer) processCommand(processType string) string {
+	if process := a.project.Process(processType); process != nil {
+		return process.Command
+	}
+	return ""
+}
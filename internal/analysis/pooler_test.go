@@ -0,0 +1,188 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+func TestConnectionPoolers(t *testing.T) {
+	env := func(extra ...string) map[string]string {
+		vars := map[string]string{
+			"DATABASE_URL":        primaryDatabaseURL,
+			"WEB_CONCURRENCY":     "2",
+			"RAILS_MAX_THREADS":   "5",
+			"SIDEKIQ_CONCURRENCY": "10",
+		}
+		for i := 0; i+1 < len(extra); i += 2 {
+			vars[extra[i]] = extra[i+1]
+		}
+		return vars
+	}
+	dynos := []config.DynoFormation{
+		{Type: "web", Quantity: 2, Size: "Standard-2X"},
+		{Type: "worker", Quantity: 1, Size: "Standard-1X"},
+	}
+	addons := []config.Addon{postgresAddon("pg-main", "standard-0", "DATABASE_URL")}
+	poolURL := "postgres://u:p@ec2-primary.compute-1.amazonaws.com:5432/d?pool=1"
+
+	pgbouncerProcfile := "web: bin/start-pgbouncer bundle exec puma -C config/puma.rb\nworker: bundle exec sidekiq\n"
+	plainProcfile := "web: bundle exec puma -C config/puma.rb\nworker: bundle exec sidekiq\n"
+	pgbouncerBuildpacks := []string{"heroku/ruby", "https://github.com/heroku/heroku-buildpack-pgbouncer"}
+
+	tests := []struct {
+		name     string
+		app      testApp
+		want     []config.ConnectionPooler
+		required int    // TotalRequired with the poolers' server connections
+		issue    string // Substring of one of the issues ("" for none)
+	}{
+		{
+			name:     "direct connections",
+			app:      testApp{env: env(), dynos: dynos, addons: addons, files: map[string]string{"Procfile": plainProcfile}},
+			want:     []config.ConnectionPooler{},
+			required: 30,
+		},
+		{
+			name: "pgbouncer buildpack on web",
+			app: testApp{
+				env: env(), dynos: dynos, addons: addons, buildpacks: pgbouncerBuildpacks,
+				files: map[string]string{"Procfile": pgbouncerProcfile},
+			},
+			// Each web dyno's 10 client connections share the default and reserve pool of 1 each
+			want: []config.ConnectionPooler{{
+				Kind: config.PoolerPgBouncer, Mode: "transaction", Processes: []string{"web"},
+				ClientConnections: 20, ServerConnections: 4, MaxServerConnections: 4,
+			}},
+			required: 14,
+		},
+		{
+			name: "pgbouncer buildpack with larger pools",
+			app: testApp{
+				env:   env("PGBOUNCER_DEFAULT_POOL_SIZE", "20", "PGBOUNCER_POOL_MODE", "session"),
+				dynos: dynos, addons: addons, buildpacks: pgbouncerBuildpacks,
+				files: map[string]string{"Procfile": pgbouncerProcfile},
+			},
+			// Server connections never exceed the clients
+			want: []config.ConnectionPooler{{
+				Kind: config.PoolerPgBouncer, Mode: "session", Processes: []string{"web"},
+				ClientConnections: 20, ServerConnections: 20, MaxServerConnections: 42,
+			}},
+			required: 30,
+		},
+		{
+			name: "pgbouncer client limit",
+			app: testApp{
+				env:   env("PGBOUNCER_MAX_CLIENT_CONN", "8"),
+				dynos: dynos, addons: addons, buildpacks: pgbouncerBuildpacks,
+				files: map[string]string{"Procfile": pgbouncerProcfile},
+			},
+			want: []config.ConnectionPooler{{
+				Kind: config.PoolerPgBouncer, Mode: "transaction", Processes: []string{"web"},
+				ClientConnections: 20, ServerConnections: 4, MaxServerConnections: 4,
+			}},
+			required: 14,
+			issue:    "web: 10 connections per dyno exceed PGBOUNCER_MAX_CLIENT_CONN (8)",
+		},
+		{
+			name: "pgbouncer started without the buildpack",
+			app: testApp{
+				env: env(), dynos: dynos, addons: addons, buildpacks: []string{"heroku/ruby"},
+				files: map[string]string{"Procfile": pgbouncerProcfile},
+			},
+			want: []config.ConnectionPooler{{
+				Kind: config.PoolerPgBouncer, Mode: "transaction", Processes: []string{"web"},
+				ClientConnections: 20, ServerConnections: 4, MaxServerConnections: 4,
+			}},
+			required: 14,
+			issue:    "web runs bin/start-pgbouncer but the pgbouncer buildpack is not installed",
+		},
+		{
+			name: "pgbouncer buildpack never started",
+			app: testApp{
+				env: env(), dynos: dynos, addons: addons, buildpacks: pgbouncerBuildpacks,
+				files: map[string]string{"Procfile": plainProcfile},
+			},
+			want:     []config.ConnectionPooler{},
+			required: 30,
+			issue:    "no Procfile command starts with bin/start-pgbouncer",
+		},
+		{
+			name: "Heroku pooler assumed without project files",
+			app:  testApp{env: env(herokuPoolerConfigVar, poolURL), dynos: dynos, addons: addons},
+			want: []config.ConnectionPooler{{
+				Kind: config.PoolerHeroku, Mode: "transaction", Processes: []string{"web", "worker"},
+				ClientConnections: 30, ServerConnections: 30, MaxServerConnections: 90,
+			}},
+			required: 30,
+			issue:    "Assuming every process connects through DATABASE_CONNECTION_POOL_URL",
+		},
+		{
+			name: "Heroku pooler caps server connections at 75%",
+			app: testApp{
+				env:    env(herokuPoolerConfigVar, poolURL),
+				dynos:  []config.DynoFormation{{Type: "web", Quantity: 10, Size: "Standard-2X"}, {Type: "worker", Quantity: 1, Size: "Standard-1X"}},
+				addons: addons,
+				files: map[string]string{
+					"Procfile":            plainProcfile,
+					"config/database.yml": "production:\n  url: <%= ENV[\"DATABASE_CONNECTION_POOL_URL\"] %>\n  pool: 10\n",
+				},
+			},
+			want: []config.ConnectionPooler{{
+				Kind: config.PoolerHeroku, Mode: "transaction", Processes: []string{"web", "worker"},
+				ClientConnections: 110, ServerConnections: 90, MaxServerConnections: 90,
+			}},
+			required: 90,
+			issue:    "doesn't set prepared_statements: false behind Heroku connection pooling",
+		},
+		{
+			name: "Heroku pooler for the processes PgBouncer doesn't pool",
+			app: testApp{
+				env: env(herokuPoolerConfigVar, poolURL), dynos: dynos, addons: addons, buildpacks: pgbouncerBuildpacks,
+				files: map[string]string{"Procfile": "web: bin/start-pgbouncer bundle exec puma\nworker: DATABASE_URL=$DATABASE_CONNECTION_POOL_URL bundle exec sidekiq\n"},
+			},
+			want: []config.ConnectionPooler{
+				{
+					Kind: config.PoolerPgBouncer, Mode: "transaction", Processes: []string{"web"},
+					ClientConnections: 20, ServerConnections: 4, MaxServerConnections: 4,
+				},
+				{
+					Kind: config.PoolerHeroku, Mode: "transaction", Processes: []string{"worker"},
+					ClientConnections: 10, ServerConnections: 10, MaxServerConnections: 90,
+				},
+			},
+			required: 14,
+		},
+		{
+			name: "Heroku pooler attached but unused",
+			app: testApp{
+				env: env(herokuPoolerConfigVar, poolURL), dynos: dynos, addons: addons,
+				files: map[string]string{"Procfile": plainProcfile},
+			},
+			want:     []config.ConnectionPooler{},
+			required: 30,
+			issue:    "DATABASE_CONNECTION_POOL_URL is attached but neither",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis := newTestAnalyzer(t, tt.app).analyzeDatabases()[0]
+
+			got := []config.ConnectionPooler{}
+			for _, pooler := range analysis.Poolers {
+				got = append(got, *pooler)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("poolers =\n  %+v\nwant\n  %+v", got, tt.want)
+			}
+			if analysis.TotalRequired != tt.required {
+				t.Errorf("TotalRequired = %d; want %d", analysis.TotalRequired, tt.required)
+			}
+			if tt.issue != "" && !hasIssue(analysis.Issues, tt.issue) {
+				t.Errorf("issues %q; want one containing %q", analysis.Issues, tt.issue)
+			}
+		})
+	}
+}
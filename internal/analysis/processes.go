@@ -0,0 +1,133 @@
+package analysis
+
+import (
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+	"github.com/leaharmstrong/heroku-calc/internal/project"
+)
+
+// processKind classifies a formation process type using the project's Procfile,
+// falling back to conventional names when the project does not declare it
+func (a *Analyzer) processKind(dynoType string) project.ProcessKind {
+	if process := a.project.Process(dynoType); process != nil {
+		return process.Kind
+	}
+
+	// Names like "worker" suggest a job worker; the lockfile tells which backend it runs
+	kind := project.ClassifyProcess(dynoType, "")
+	if kind.IsJobWorker() && a.stack().Detected && !a.stack().HasJobBackend(string(kind)) {
+		if backend := a.singleJobBackend(); backend != "" {
+			return backend
+		}
+	}
+	return kind
+}
+
+// hasProcfile returns true if the project declares its process types
+func (a *Analyzer) hasProcfile() bool {
+	return a.project != nil && len(a.project.Processes) > 0
+}
+
+// getDynosByKind returns all formation entries whose process type runs the given kind
+func (a *Analyzer) getDynosByKind(kind project.ProcessKind) []config.DynoFormation {
+	result := []config.DynoFormation{}
+	for _, dyno := range a.dynos {
+		if a.processKind(dyno.Type) == kind {
+			result = append(result, dyno)
+		}
+	}
+	return result
+}
+
+// processUsage estimates the database connections held by each scaled process type to the given
+// config/database.yml databases, and what each of those databases takes across the formation
+// Each process keeps one pool per database holding at most min(pool, threads) connections;
+// without database.yml a single pool as large as the thread count is assumed
+func (a *Analyzer) processUsage(entries []project.DatabaseEntry) ([]config.ProcessUsage, []config.DatabaseBudget) {
+	usage := []config.ProcessUsage{}
+
+	budgets := make([]config.DatabaseBudget, len(entries))
+	pools := []int{0}
+	if len(entries) > 0 {
+		pools = make([]int, len(entries))
+	}
+	for i, entry := range entries {
+		if pool, ok := entry.Pool(a.envVars); ok {
+			pools[i] = pool
+		}
+		budgets[i] = config.DatabaseBudget{
+			Name:    entry.Name,
+			URLEnv:  entry.URLEnv(),
+			Replica: entry.Replica,
+			Pool:    pools[i],
+		}
+	}
+
+	for _, dyno := range a.dynos {
+		if dyno.Quantity == 0 {
+			continue
+		}
+
+		kind := a.processKind(dyno.Type)
+		processes, threads := a.processThreads(dyno.Type, kind)
+		if processes == 0 {
+			continue
+		}
+
+		process := config.ProcessUsage{
+			Type:      dyno.Type,
+			Kind:      string(kind),
+			Dynos:     dyno.Quantity,
+			Processes: processes,
+			Threads:   processes * threads,
+			Pool:      pools[0],
+		}
+		for i, pool := range pools {
+			connections := threads
+			if pool > 0 && pool < threads {
+				connections = pool
+			}
+			process.Connections += dyno.Quantity * processes * connections
+			if i < len(budgets) {
+				budgets[i].Connections += dyno.Quantity * processes * connections
+			}
+		}
+		if kind == project.KindSidekiq {
+			settings := a.sidekiqSettings(dyno.Type)
+			process.ThreadsSource = settings.Source
+			process.Queues = settings.Queues
+		}
+
+		usage = append(usage, process)
+	}
+
+	return usage, budgets
+}
+
+// processThreads estimates how many processes one dyno of a process type runs
+// and how many threads in each of them may check out a database connection
+func (a *Analyzer) processThreads(dynoType string, kind project.ProcessKind) (int, int) {
+	switch kind {
+	case project.KindWeb:
+		// Puma workers × threads per worker
+		return a.webConcurrency().Value, a.railsMaxThreads().Value
+	case project.KindSidekiq:
+		return 1, a.sidekiqConcurrency(dynoType)
+	case project.KindGoodJob:
+		// GoodJob execution threads plus its LISTEN/NOTIFY and cron connections
+		return 1, a.getEnvVarInt("GOOD_JOB_MAX_THREADS", 5) + 2
+	case project.KindSolidQueue:
+		// Solid Queue forks JOB_CONCURRENCY workers running 3 threads each, plus a dispatcher
+		return a.getEnvVarInt("JOB_CONCURRENCY", 1) + 1, 3
+	case project.KindRelease:
+		// Release phase only runs during deploys
+		return 0, 0
+	default:
+		// Single-threaded processes: Resque, Delayed Job, clock, rake and others
+		return 1, 1
+	}
+}
+
+// sidekiqConcurrency returns the Sidekiq thread count for a process type
+func (a *Analyzer) sidekiqConcurrency(dynoType string) int {
+	return a.sidekiqSettings(dynoType).Concurrency
+}
@@ -0,0 +1,106 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/leaharmstrong/heroku-calc/internal/project"
+)
+
+const (
+	defaultWebConcurrency  = 2
+	defaultRailsMaxThreads = 5
+)
+
+// pumaSetting is a resolved Puma concurrency value and where it came from
+type pumaSetting struct {
+	Value   int
+	Source  string // Env var name, "config/puma.rb" or "default"
+	Warning string // Set when config/puma.rb could not be interpreted
+}
+
+// IsDefault returns true when neither the env nor config/puma.rb provided the value
+func (s pumaSetting) IsDefault() bool {
+	return s.Source == "default"
+}
+
+// Describe returns a short description of the value and its source
+func (s pumaSetting) Describe() string {
+	if s.IsDefault() {
+		return fmt.Sprintf("default %d", s.Value)
+	}
+	return fmt.Sprintf("%d from %s", s.Value, s.Source)
+}
+
+// pumaConfig returns the project's parsed config/puma.rb, or nil
+func (a *Analyzer) pumaConfig() *project.PumaConfig {
+	if a.project == nil {
+		return nil
+	}
+	return a.project.Puma
+}
+
+// webConcurrency resolves the number of Puma processes per web dyno
+// Single mode (no workers) counts as one process
+func (a *Analyzer) webConcurrency() pumaSetting {
+	return a.resolvePumaSetting("WEB_CONCURRENCY", defaultWebConcurrency, "workers", func(puma *project.PumaConfig) (int, bool) {
+		workers, ok := puma.Workers(a.envVars)
+		if ok && workers < 1 {
+			workers = 1
+		}
+		return workers, ok
+	}, func(puma *project.PumaConfig) string { return puma.WorkersExpr })
+}
+
+// railsMaxThreads resolves the maximum Puma threads per process
+// Single-threaded web servers always run one thread
+func (a *Analyzer) railsMaxThreads() pumaSetting {
+	if !a.webServerThreaded() {
+		return pumaSetting{Value: 1, Source: a.stack().WebServer}
+	}
+	return a.resolvePumaSetting("RAILS_MAX_THREADS", defaultRailsMaxThreads, "threads", func(puma *project.PumaConfig) (int, bool) {
+		return puma.MaxThreads(a.envVars)
+	}, func(puma *project.PumaConfig) string { return puma.MaxThreadsExpr })
+}
+
+// resolvePumaSetting prefers config/puma.rb (evaluated against the app's env vars),
+// then the env var itself, then the built-in default
+func (a *Analyzer) resolvePumaSetting(envVar string, defaultValue int, directive string,
+	evaluate func(*project.PumaConfig) (int, bool), expr func(*project.PumaConfig) string) pumaSetting {
+
+	if puma := a.pumaConfig(); puma != nil {
+		hasDirective := expr(puma) != "" || directive == "workers"
+		if hasDirective {
+			if value, ok := evaluate(puma); ok {
+				source := project.PumaConfigPath
+				if a.hasEnvVar(envVar) && puma.ReadsEnv(envVar) {
+					source = envVar
+				}
+				return pumaSetting{Value: value, Source: source}
+			}
+
+			setting := a.envOrDefault(envVar, defaultValue)
+			setting.Warning = fmt.Sprintf("%s: could not interpret `%s %s` (using %s)",
+				project.PumaConfigPath, directive, expr(puma), setting.Describe())
+			return setting
+		}
+	}
+
+	return a.envOrDefault(envVar, defaultValue)
+}
+
+// envOrDefault returns the env var as a setting, or the default
+func (a *Analyzer) envOrDefault(envVar string, defaultValue int) pumaSetting {
+	if a.hasEnvVar(envVar) {
+		return pumaSetting{Value: a.getEnvVarInt(envVar, defaultValue), Source: envVar}
+	}
+	return pumaSetting{Value: defaultValue, Source: "default"}
+}
+
+// pumaEnvVarEffective returns true if setting envVar would change Puma's configuration
+func (a *Analyzer) pumaEnvVarEffective(envVar string) bool {
+	if envVar == "RAILS_MAX_THREADS" && !a.webServerThreaded() {
+		return false
+	}
+	puma := a.pumaConfig()
+	return puma == nil || puma.ReadsEnv(envVar)
+}
@@ -4,24 +4,26 @@ import (
 	"fmt"
 
 	"github.com/leaharmstrong/heroku-calc/internal/config"
+	"github.com/leaharmstrong/heroku-calc/internal/project"
 )
 
 // generateRecommendations creates actionable recommendations based on analysis
+// The first database and Redis analyses are the primary ones, behind DATABASE_URL and REDIS_URL
 func (a *Analyzer) generateRecommendations(
-	dbAnalysis *config.DatabaseAnalysis,
-	redisAnalysis *config.RedisAnalysis,
+	dbAnalyses []*config.DatabaseAnalysis,
+	redisAnalyses []*config.RedisAnalysis,
 	webAnalysis *config.WebTierAnalysis,
 ) []config.Recommendation {
 	recommendations := []config.Recommendation{}
 
 	// Database recommendations
-	if dbAnalysis != nil {
-		recommendations = append(recommendations, a.generateDatabaseRecommendations(dbAnalysis)...)
+	for i, dbAnalysis := range dbAnalyses {
+		recommendations = append(recommendations, forDatastore(a.generateDatabaseRecommendations(dbAnalysis, i == 0), dbAnalysis.Datastore, i == 0)...)
 	}
 
 	// Redis recommendations
-	if redisAnalysis != nil {
-		recommendations = append(recommendations, a.generateRedisRecommendations(redisAnalysis)...)
+	for i, redisAnalysis := range redisAnalyses {
+		recommendations = append(recommendations, forDatastore(a.generateRedisRecommendations(redisAnalysis, i == 0), redisAnalysis.Datastore, i == 0)...)
 	}
 
 	// Web tier recommendations
@@ -29,10 +31,27 @@ func (a *Analyzer) generateRecommendations(
 		recommendations = append(recommendations, a.generateWebTierRecommendations(webAnalysis)...)
 	}
 
+	// Cost recommendations for resources the app pays for but doesn't use
+	recommendations = append(recommendations, a.generateCostRecommendations(dbAnalyses, redisAnalyses)...)
+
+	return recommendations
+}
+
+// forDatastore names the datastore in the titles of recommendations for a secondary one,
+// e.g. "Upgrade Postgres Plan (HEROKU_POSTGRESQL_ROSE_URL)"
+func forDatastore(recommendations []config.Recommendation, store *config.Datastore, primary bool) []config.Recommendation {
+	if primary || store == nil {
+		return recommendations
+	}
+	for i := range recommendations {
+		recommendations[i].Title += fmt.Sprintf(" (%s)", store.ConfigVars[0])
+	}
 	return recommendations
 }
 
-func (a *Analyzer) generateDatabaseRecommendations(analysis *config.DatabaseAnalysis) []config.Recommendation {
+// generateDatabaseRecommendations recommends plan and pool changes for a database
+// Connection and pool settings only apply to the primary database the formation is modelled against
+func (a *Analyzer) generateDatabaseRecommendations(analysis *config.DatabaseAnalysis, primary bool) []config.Recommendation {
 	recommendations := []config.Recommendation{}
 
 	if analysis.Status == config.StatusCritical || analysis.Status == config.StatusWarning {
@@ -63,7 +82,7 @@ func (a *Analyzer) generateDatabaseRecommendations(analysis *config.DatabaseAnal
 		}
 
 		// Recommend reducing connections
-		if analysis.BufferPercent < 20 {
+		if primary && analysis.BufferPercent < 20 {
 			recommendations = append(recommendations, config.Recommendation{
 				Category:    "database",
 				Severity:    config.SeverityHigh,
@@ -77,18 +96,45 @@ func (a *Analyzer) generateDatabaseRecommendations(analysis *config.DatabaseAnal
 		}
 	}
 
+	// Recommend raising the pool when threads outnumber it
+	maxThreads := 0
+	for _, usage := range analysis.Processes {
+		if usage.Pool > 0 && usage.Pool < usage.ThreadsPerProcess() && usage.ThreadsPerProcess() > maxThreads {
+			maxThreads = usage.ThreadsPerProcess()
+		}
+	}
+	if primary && maxThreads > 0 {
+		rec := config.Recommendation{
+			Category:    "database",
+			Severity:    config.SeverityHigh,
+			Title:       "Raise Database Pool Size",
+			Description: fmt.Sprintf("Threads wait for a connection when the pool in %s is smaller than the thread count", project.DatabaseConfigPath),
+			Current:     fmt.Sprintf("pool %d", a.primaryPool()),
+			Suggested:   fmt.Sprintf("%d", maxThreads),
+			Impact:      "Prevents ActiveRecord::ConnectionTimeoutError under load",
+			AutoApply:   false,
+		}
+		if a.databaseReadsEnv("DB_POOL") {
+			rec.EnvVarName = "DB_POOL"
+			rec.AutoApply = true
+		}
+		recommendations = append(recommendations, rec)
+	}
+
 	return recommendations
 }
 
-func (a *Analyzer) generateRedisRecommendations(analysis *config.RedisAnalysis) []config.Recommendation {
+// generateRedisRecommendations recommends pool and plan changes for a Redis datastore
+// REDIS_POOL_SIZE is only recommended once, for the primary
+func (a *Analyzer) generateRedisRecommendations(analysis *config.RedisAnalysis, primary bool) []config.Recommendation {
 	recommendations := []config.Recommendation{}
 
 	// Recommend setting REDIS_POOL_SIZE if not set
-	if analysis.RedisURL != "unknown" && !a.hasEnvVar("REDIS_POOL_SIZE") {
+	if primary && analysis.RedisURL != "unknown" && !a.hasEnvVar("REDIS_POOL_SIZE") && a.webUsesRedis() {
 		webDynos := a.getDynosByType("web")
 		if webDynos != nil {
 			// Recommend explicit pool size based on concurrency
-			threadsPerWorker := a.getEnvVarInt("RAILS_MAX_THREADS", 5)
+			threadsPerWorker := a.railsMaxThreads().Value
 			suggestedPoolSize := threadsPerWorker + 2 // Add small buffer
 
 			recommendations = append(recommendations, config.Recommendation{
@@ -106,11 +152,12 @@ func (a *Analyzer) generateRedisRecommendations(analysis *config.RedisAnalysis)
 	}
 
 	// Recommend upgrading Redis plan if near capacity
-	if analysis.MaxConnections > 0 && analysis.EstimatedUsage > 0 {
-		utilizationPercent := float64(analysis.EstimatedUsage) / float64(analysis.MaxConnections) * 100
+	if analysis.MaxConnections > 0 && analysis.Usage() > 0 {
+		utilizationPercent := analysis.Utilization()
 
 		if utilizationPercent > 80 {
-			suggestedPlan := a.suggestNextRedisPlan(analysis.RedisPlan, analysis.EstimatedUsage)
+			service := redisService(analysis.Datastore)
+			suggestedPlan := a.suggestNextRedisPlan(service, analysis.Usage())
 
 			if suggestedPlan != "" {
 				recommendations = append(recommendations, config.Recommendation{
@@ -120,7 +167,7 @@ func (a *Analyzer) generateRedisRecommendations(analysis *config.RedisAnalysis)
 					Description: fmt.Sprintf("Redis utilization at %.1f%% - upgrade for more connection capacity", utilizationPercent),
 					Current:     analysis.RedisPlan,
 					Suggested:   suggestedPlan,
-					Impact:      a.calculateRedisCostImpact(analysis.RedisPlan, suggestedPlan),
+					Impact:      a.calculateRedisCostImpact(service, analysis.RedisPlan, suggestedPlan),
 					AutoApply:   false,
 				})
 			}
@@ -133,14 +180,14 @@ func (a *Analyzer) generateRedisRecommendations(analysis *config.RedisAnalysis)
 func (a *Analyzer) generateWebTierRecommendations(analysis *config.WebTierAnalysis) []config.Recommendation {
 	recommendations := []config.Recommendation{}
 
-	// Recommend setting WEB_CONCURRENCY if not set
-	if !a.hasEnvVar("WEB_CONCURRENCY") {
+	// Recommend setting WEB_CONCURRENCY if not set and config/puma.rb reads it
+	if !a.hasEnvVar("WEB_CONCURRENCY") && a.pumaEnvVarEffective("WEB_CONCURRENCY") {
 		recommendations = append(recommendations, config.Recommendation{
 			Category:    "web",
 			Severity:    config.SeverityMedium,
 			Title:       "Set WEB_CONCURRENCY",
 			Description: "Explicitly configure Puma worker count for better performance tuning",
-			Current:     "not set (using default 2)",
+			Current:     fmt.Sprintf("not set (using %s)", a.webConcurrency().Describe()),
 			Suggested:   a.suggestWebConcurrency(analysis.DynoMemoryMB),
 			EnvVarName:  "WEB_CONCURRENCY",
 			Impact:      "Optimizes worker count for dyno size",
@@ -148,21 +195,34 @@ func (a *Analyzer) generateWebTierRecommendations(analysis *config.WebTierAnalys
 		})
 	}
 
-	// Recommend setting RAILS_MAX_THREADS if not set
-	if !a.hasEnvVar("RAILS_MAX_THREADS") {
+	// Recommend setting RAILS_MAX_THREADS if not set and config/puma.rb reads it
+	if !a.hasEnvVar("RAILS_MAX_THREADS") && a.pumaEnvVarEffective("RAILS_MAX_THREADS") {
 		recommendations = append(recommendations, config.Recommendation{
 			Category:    "web",
 			Severity:    config.SeverityMedium,
 			Title:       "Set RAILS_MAX_THREADS",
 			Description: "Explicitly configure Puma thread count per worker",
-			Current:     "not set (using default 5)",
-			Suggested:   "5",
+			Current:     fmt.Sprintf("not set (using %s)", a.railsMaxThreads().Describe()),
+			Suggested:   fmt.Sprintf("%d", a.railsMaxThreads().Value),
 			EnvVarName:  "RAILS_MAX_THREADS",
 			Impact:      "Prevents unexpected behavior from default changes",
 			AutoApply:   true,
 		})
 	}
 
+	// Scale the web formation to the traffic
+	if recommendation := capacityRecommendation(analysis); recommendation != nil {
+		recommendations = append(recommendations, *recommendation)
+	}
+
+	// With measured memory, size the worker count from what each worker actually uses
+	if memory := analysis.Memory; memory != nil && memory.Samples > 0 {
+		if recommendation := a.measuredConcurrencyRecommendation(analysis); recommendation != nil {
+			recommendations = append(recommendations, *recommendation)
+		}
+		return recommendations
+	}
+
 	// Recommend adjusting concurrency if memory per thread is too low
 	if analysis.MemoryPerThread > 0 && analysis.MemoryPerThread < 50 {
 		suggestedThreads := analysis.DynoMemoryMB / 80 // 80MB per thread target
@@ -185,6 +245,46 @@ func (a *Analyzer) generateWebTierRecommendations(analysis *config.WebTierAnalys
 	return recommendations
 }
 
+// measuredConcurrencyRecommendation suggests a WEB_CONCURRENCY that keeps the measured
+// p95 memory under the warning threshold, or nil when the current workers fit
+func (a *Analyzer) measuredConcurrencyRecommendation(analysis *config.WebTierAnalysis) *config.Recommendation {
+	memory := analysis.Memory
+	if memory.QuotaMB == 0 || analysis.WebConcurrency < 1 {
+		return nil
+	}
+
+	perWorker := memory.MemoryP95MB / float64(analysis.WebConcurrency)
+	if perWorker <= 0 {
+		return nil
+	}
+	fits := int(memory.QuotaMB * memoryWarningPercent / 100 / perWorker)
+	if fits >= analysis.WebConcurrency {
+		return nil
+	}
+
+	severity := config.SeverityHigh
+	if memory.R14Errors+memory.R15Errors > 0 {
+		severity = config.SeverityCritical
+	}
+
+	suggested := fmt.Sprintf("%d", fits)
+	if fits < 1 {
+		suggested = "1 on a larger dyno size"
+	}
+
+	return &config.Recommendation{
+		Category:    "web",
+		Severity:    severity,
+		Title:       "Reduce WEB_CONCURRENCY",
+		Description: fmt.Sprintf("Measured p95 memory is %.0f MB of %.0f MB (~%.0f MB per worker)", memory.MemoryP95MB, memory.QuotaMB, perWorker),
+		Current:     fmt.Sprintf("%d", analysis.WebConcurrency),
+		Suggested:   suggested,
+		EnvVarName:  "WEB_CONCURRENCY",
+		Impact:      "Keeps web dynos under their memory quota, preventing R14 swapping",
+		AutoApply:   fits >= 1,
+	}
+}
+
 // Helper functions to suggest next tier plans
 
 func (a *Analyzer) suggestNextPostgresPlan(currentPlan string, requiredConnections int) string {
@@ -204,13 +304,12 @@ func (a *Analyzer) suggestNextPostgresPlan(currentPlan string, requiredConnectio
 	return ""
 }
 
-func (a *Analyzer) suggestNextRedisPlan(currentPlan string, requiredConnections int) string {
-	plans := []string{"mini", "premium-0", "premium-1", "premium-2", "premium-3", "premium-4", "premium-5"}
-
+// suggestNextRedisPlan returns the cheapest plan of the add-on's provider with room for the connections
+func (a *Analyzer) suggestNextRedisPlan(service string, requiredConnections int) string {
 	targetConnections := int(float64(requiredConnections) * 1.5) // 50% buffer
 
-	for _, plan := range plans {
-		if price, err := a.pricingData.GetRedisPrice(plan); err == nil {
+	for _, plan := range a.pricingData.RedisPlans(service) {
+		if price, err := a.pricingData.GetRedisPlanPrice(service, plan); err == nil {
 			if price.MaxConnections >= targetConnections {
 				return plan
 			}
@@ -246,9 +345,9 @@ func (a *Analyzer) calculatePostgresCostImpact(currentPlan, suggestedPlan string
 	return fmt.Sprintf("+$%.2f/month ($%.2f → $%.2f)", diff, currentPrice.PriceMonthly, suggestedPrice.PriceMonthly)
 }
 
-func (a *Analyzer) calculateRedisCostImpact(currentPlan, suggestedPlan string) string {
-	currentPrice, err1 := a.pricingData.GetRedisPrice(currentPlan)
-	suggestedPrice, err2 := a.pricingData.GetRedisPrice(suggestedPlan)
+func (a *Analyzer) calculateRedisCostImpact(service, currentPlan, suggestedPlan string) string {
+	currentPrice, err1 := a.pricingData.GetRedisPlanPrice(service, currentPlan)
+	suggestedPrice, err2 := a.pricingData.GetRedisPlanPrice(service, suggestedPlan)
 
 	if err1 != nil || err2 != nil {
 		return "Cost impact unknown"
@@ -4,12 +4,80 @@ import (
 	"fmt"
 
 	"github.com/leaharmstrong/heroku-calc/internal/config"
+	"github.com/leaharmstrong/heroku-calc/internal/pricing"
+	"github.com/leaharmstrong/heroku-calc/internal/project"
 )
 
-// analyzeRedis analyzes Redis/cache connection configuration
-func (a *Analyzer) analyzeRedis() *config.RedisAnalysis {
+// analyzeRedisInstances analyzes every Redis datastore, the one behind REDIS_URL first
+// Without one, the first analysis reports REDIS_URL as not configured
+func (a *Analyzer) analyzeRedisInstances() []*config.RedisAnalysis {
+	analyses := []*config.RedisAnalysis{}
+	for _, store := range a.redisStores {
+		store := store
+		analyses = append(analyses, a.analyzeRedis(&store))
+	}
+	if len(analyses) == 0 || !analyses[0].Datastore.HasConfigVar("REDIS_URL") {
+		analyses = append([]*config.RedisAnalysis{a.analyzeRedis(nil)}, analyses...)
+	}
+	return analyses
+}
+
+// redisService returns the add-on service behind a Redis datastore ("" if unknown)
+func redisService(store *config.Datastore) string {
+	if store == nil {
+		return ""
+	}
+	return store.Service
+}
+
+// redisClients are the app's Redis clients connecting to one datastore
+type redisClients struct {
+	sidekiq bool // Sidekiq, through REDIS_PROVIDER or REDIS_URL
+	resque  bool // Resque, through REDIS_URL
+	web     bool // Cache store and other gems in web processes
+	cable   bool // Action Cable's Redis adapter
+}
+
+// any returns true if any modelled client connects
+func (c redisClients) any() bool {
+	return c.sidekiq || c.resque || c.web || c.cable
+}
+
+// redisClientsOf returns the clients connecting to the datastore (nil for the one behind REDIS_URL
+// when it matches no add-on)
+func (a *Analyzer) redisClientsOf(store *config.Datastore) redisClients {
+	reads := func(name string) bool {
+		if store == nil {
+			return name == "REDIS_URL"
+		}
+		return store.HasConfigVar(name)
+	}
+	stack := a.stack()
+
+	// Sidekiq connects through the config var REDIS_PROVIDER names, if set
+	sidekiqVar := "REDIS_URL"
+	if provider := a.envVars["REDIS_PROVIDER"]; provider != "" {
+		sidekiqVar = provider
+	}
+	cableVar := "REDIS_URL"
+	if stack.CableURLEnv != "" {
+		cableVar = stack.CableURLEnv
+	}
+
+	return redisClients{
+		sidekiq: reads(sidekiqVar),
+		resque:  reads("REDIS_URL"),
+		web:     a.webUsesRedis() && (reads("REDIS_URL") || (stack.CacheURLEnv != "" && reads(stack.CacheURLEnv))),
+		cable:   stack.CableAdapter == "redis" && reads(cableVar),
+	}
+}
+
+// analyzeRedis analyzes the connections the app's clients open to a Redis datastore
+// The datastore is nil when REDIS_URL is missing or matches no add-on
+func (a *Analyzer) analyzeRedis(store *config.Datastore) *config.RedisAnalysis {
 	analysis := &config.RedisAnalysis{
 		RedisURL:           "unknown",
+		Datastore:          store,
 		RedisPlan:          "unknown",
 		MaxConnections:     0,
 		SidekiqConcurrency: 0,
@@ -20,7 +88,7 @@ func (a *Analyzer) analyzeRedis() *config.RedisAnalysis {
 	}
 
 	// Check if REDIS_URL exists
-	if !a.hasEnvVar("REDIS_URL") {
+	if store == nil && !a.hasEnvVar("REDIS_URL") {
 		// Redis is optional for Rails apps
 		analysis.Status = config.StatusOptimal
 		analysis.Issues = append(analysis.Issues, "REDIS_URL not configured (optional)")
@@ -28,33 +96,59 @@ func (a *Analyzer) analyzeRedis() *config.RedisAnalysis {
 	}
 
 	analysis.RedisURL = "present"
+	primary := store == nil || store.HasConfigVar("REDIS_URL")
 
-	// Get Redis plan
-	redisPlan := a.extractRedisPlan()
-	analysis.RedisPlan = redisPlan
-
-	// Get max connections from pricing data
-	if redisPlan != "unknown" {
-		if redisPrice, err := a.pricingData.GetRedisPrice(redisPlan); err == nil {
+	// Get max connections from the server when it reports them, otherwise from pricing data
+	service := redisService(store)
+	if store != nil {
+		analysis.RedisPlan = store.Plan
+		analysis.Measured = a.redisInfo[store.ConfigVars[0]]
+	}
+	if service != "" && service != pricing.HerokuRedisService {
+		analysis.Provider = a.pricingData.RedisProviderName(service)
+	}
+	if analysis.Measured != nil && analysis.Measured.MaxClients > 0 {
+		analysis.MaxConnections = analysis.Measured.MaxClients
+	} else if analysis.RedisPlan != "unknown" {
+		if redisPrice, err := a.pricingData.GetRedisPlanPrice(service, analysis.RedisPlan); err == nil {
 			analysis.MaxConnections = redisPrice.MaxConnections
+		} else if analysis.Provider != "" {
+			analysis.Issues = append(analysis.Issues, fmt.Sprintf("Unknown %s plan: %s", analysis.Provider, analysis.RedisPlan))
 		} else {
-			analysis.Issues = append(analysis.Issues, fmt.Sprintf("Unknown Redis plan: %s", redisPlan))
+			analysis.Issues = append(analysis.Issues, fmt.Sprintf("Unknown Redis plan: %s", analysis.RedisPlan))
 		}
 	}
 
 	// Calculate connection requirements
+	clients := a.redisClientsOf(store)
 
-	// Sidekiq connections
-	workerDynos := a.getDynosByType("worker")
-	if workerDynos != nil {
-		concurrency := a.getEnvVarInt("SIDEKIQ_CONCURRENCY", 10)
-		analysis.SidekiqConcurrency = workerDynos.Quantity * concurrency
+	// Sidekiq connections across every process type running Sidekiq
+	if clients.sidekiq {
+		for _, workerDynos := range a.getDynosByKind(project.KindSidekiq) {
+			concurrency := a.sidekiqConcurrency(workerDynos.Type)
+			analysis.SidekiqConcurrency += workerDynos.Quantity * concurrency
+		}
 		analysis.EstimatedUsage += analysis.SidekiqConcurrency
 	}
 
+	// Each Resque worker holds a single connection
+	if clients.resque {
+		for _, workerDynos := range a.getDynosByKind(project.KindResque) {
+			analysis.EstimatedUsage += workerDynos.Quantity
+		}
+	}
+
+	switch {
+	case primary && !a.webUsesRedis():
+		analysis.Issues = append(analysis.Issues, "REDIS_URL is set but nothing in Gemfile.lock, the cache store or Action Cable uses Redis")
+	case !primary && !clients.any():
+		analysis.Issues = append(analysis.Issues, fmt.Sprintf(
+			"Neither Sidekiq, the cache store nor Action Cable reads %s (used outside the app?)", store.ConfigVars[0]))
+	}
+
 	// Web dynos using Redis (for cache, sessions, etc.)
 	webDynos := a.getDynosByType("web")
-	if webDynos != nil {
+	if webDynos != nil && clients.web {
 		// Check if REDIS_POOL_SIZE is set
 		redisPoolSize := a.getEnvVarInt("REDIS_POOL_SIZE", 0)
 
@@ -63,7 +157,7 @@ func (a *Analyzer) analyzeRedis() *config.RedisAnalysis {
 			analysis.EstimatedUsage += webDynos.Quantity * redisPoolSize
 		} else {
 			// Default Redis pool size is typically 5 per web process
-			workersPerDyno := a.getEnvVarInt("WEB_CONCURRENCY", 2)
+			workersPerDyno := a.webConcurrency().Value
 			defaultPoolPerProcess := 5
 			analysis.RedisPoolSize = defaultPoolPerProcess
 			analysis.EstimatedUsage += webDynos.Quantity * workersPerDyno * defaultPoolPerProcess
@@ -72,13 +166,22 @@ func (a *Analyzer) analyzeRedis() *config.RedisAnalysis {
 		}
 	}
 
-	// Determine status
+	// Action Cable's Redis adapter holds a subscription connection in every web process
+	if webDynos != nil && clients.cable {
+		analysis.EstimatedUsage += webDynos.Quantity * a.webConcurrency().Value
+	}
+
+	// Determine status from connected clients when measured, otherwise from the estimate
+	usageSource := "estimated"
+	if analysis.Measured != nil && analysis.Measured.Live {
+		usageSource = "connected"
+	}
 	if analysis.MaxConnections > 0 {
-		utilizationPercent := float64(analysis.EstimatedUsage) / float64(analysis.MaxConnections) * 100
+		utilizationPercent := analysis.Utilization()
 
-		if analysis.EstimatedUsage >= analysis.MaxConnections {
+		if analysis.Usage() >= analysis.MaxConnections {
 			analysis.Status = config.StatusCritical
-			analysis.Issues = append(analysis.Issues, fmt.Sprintf("Redis connection exhaustion: %d estimated >= %d max", analysis.EstimatedUsage, analysis.MaxConnections))
+			analysis.Issues = append(analysis.Issues, fmt.Sprintf("Redis connection exhaustion: %d %s >= %d max", analysis.Usage(), usageSource, analysis.MaxConnections))
 		} else if utilizationPercent > 80 {
 			analysis.Status = config.StatusWarning
 			analysis.Issues = append(analysis.Issues, fmt.Sprintf("High Redis utilization: %.1f%% (recommend <80%%)", utilizationPercent))
@@ -87,5 +190,38 @@ func (a *Analyzer) analyzeRedis() *config.RedisAnalysis {
 		}
 	}
 
+	if analysis.Measured != nil {
+		a.checkRedisMemory(analysis)
+	}
+
 	return analysis
 }
+
+// checkRedisMemory flags memory pressure and eviction policies measured by redis:info
+func (a *Analyzer) checkRedisMemory(analysis *config.RedisAnalysis) {
+	measured := analysis.Measured
+	noEviction := measured.MaxmemoryPolicy == "" || measured.MaxmemoryPolicy == "noeviction"
+
+	// Sidekiq stores jobs in Redis: an eviction policy can silently drop them
+	if !noEviction && (a.stack().HasJobBackend("sidekiq") || len(a.getDynosByKind(project.KindSidekiq)) > 0) {
+		analysis.Issues = append(analysis.Issues, fmt.Sprintf(
+			"maxmemory-policy is %s: Sidekiq needs noeviction so jobs are not silently evicted", measured.MaxmemoryPolicy))
+	}
+
+	memoryPercent := measured.MemoryPercent()
+	switch {
+	case memoryPercent >= 90 && noEviction:
+		analysis.Status = config.StatusCritical
+		analysis.Issues = append(analysis.Issues, fmt.Sprintf("Redis memory at %.1f%% of maxmemory with noeviction: writes will fail when full", memoryPercent))
+	case memoryPercent >= 90:
+		if analysis.Status != config.StatusCritical {
+			analysis.Status = config.StatusWarning
+		}
+		analysis.Issues = append(analysis.Issues, fmt.Sprintf("Redis memory at %.1f%% of maxmemory: keys are being evicted (%s)", memoryPercent, measured.MaxmemoryPolicy))
+	case memoryPercent >= 75:
+		if analysis.Status == config.StatusOptimal {
+			analysis.Status = config.StatusWarning
+		}
+		analysis.Issues = append(analysis.Issues, fmt.Sprintf("Redis memory at %.1f%% of maxmemory", memoryPercent))
+	}
+}
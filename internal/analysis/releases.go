@@ -0,0 +1,87 @@
+package analysis
+
+import (
+	"strings"
+	"time"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+// Release correlation
+const (
+	recentChangeWindow = 30 * 24 * time.Hour // Older releases are unlikely to explain a current issue
+	maxRecentChanges   = 5                   // Changes shown per component
+)
+
+// componentVars are the config vars that affect each analyzed component
+var componentVars = map[string]map[string]bool{
+	"web": {
+		"WEB_CONCURRENCY":   true,
+		"RAILS_MAX_THREADS": true,
+		"RAILS_MIN_THREADS": true,
+		"MALLOC_ARENA_MAX":  true,
+	},
+	"database": {
+		"WEB_CONCURRENCY":     true,
+		"RAILS_MAX_THREADS":   true,
+		"DB_POOL":             true,
+		"DATABASE_POOL":       true,
+		"SIDEKIQ_CONCURRENCY": true,
+		"DATABASE_URL":        true,
+	},
+	"redis": {
+		"WEB_CONCURRENCY":     true,
+		"RAILS_MAX_THREADS":   true,
+		"SIDEKIQ_CONCURRENCY": true,
+		"REDIS_URL":           true,
+	},
+}
+
+// componentAddons are substrings of add-on release descriptions that affect each component
+var componentAddons = map[string][]string{
+	"database": {"postgres", "database"},
+	"redis":    {"redis"},
+}
+
+// recentChanges returns the recent releases that touched a component, newest first,
+// when the component needs attention
+// Changes to the config vars pointing at the component's datastore (nil for none) count too
+func (a *Analyzer) recentChanges(component string, status config.AnalysisStatus, store *config.Datastore) []config.ReleaseChange {
+	if a.releases == nil || (status != config.StatusWarning && status != config.StatusCritical) {
+		return nil
+	}
+
+	var changes []config.ReleaseChange
+	cutoff := time.Now().Add(-recentChangeWindow)
+	for _, change := range a.releases.Changes {
+		if change.CreatedAt.Before(cutoff) {
+			break
+		}
+		if affects(component, change) || (change.Kind == config.ReleaseConfig && store.HasConfigVar(change.Subject)) {
+			changes = append(changes, change)
+			if len(changes) == maxRecentChanges {
+				break
+			}
+		}
+	}
+	return changes
+}
+
+// affects reports whether a release change can explain an issue in the component
+func affects(component string, change config.ReleaseChange) bool {
+	switch change.Kind {
+	case config.ReleaseConfig:
+		return componentVars[component][change.Subject]
+	case config.ReleaseAddon:
+		description := strings.ToLower(change.Description)
+		for _, service := range componentAddons[component] {
+			if strings.Contains(description, service) {
+				return true
+			}
+		}
+	case config.ReleaseRollback:
+		// Rollbacks restore an earlier release's config vars along with its code
+		return true
+	}
+	return false
+}
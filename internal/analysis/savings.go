@@ -0,0 +1,346 @@
+package analysis
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+	"github.com/leaharmstrong/heroku-calc/internal/pricing"
+	"github.com/leaharmstrong/heroku-calc/internal/project"
+)
+
+// Thresholds for cost recommendations
+const (
+	downsizePercent       = 50   // Usage must fit in this share of the smaller plan's limits
+	lowLoadAverage        = 1.0  // p95 load below which a Performance dyno's dedicated CPU sits idle
+	idleLoadAverage       = 0.05 // Peak load at or below which a process did no work
+	minIdleHours          = 1.0  // Shortest log window that can tell an idle process from a quiet spell
+	dynoMemoryHeadroom    = 80   // Percent of a smaller dyno's memory the measured peak may use
+	nonProductionDynoSize = "standard-2x"
+)
+
+// nonProductionNameRegex matches app names of staging, review and other non-production apps,
+// e.g. "shop-staging" or "shop-pr-123"
+var nonProductionNameRegex = regexp.MustCompile(`(^|[-_])(staging|stage|dev|develop|development|qa|test|testing|review|sandbox|uat|preview|demo|pr-\d+)([-_]|$)`)
+
+// generateCostRecommendations recommends savings on resources the app pays for but doesn't use:
+// datastore plans far bigger than their usage, idle workers, Performance dynos with low load
+// and production-grade tiers on non-production apps
+func (a *Analyzer) generateCostRecommendations(
+	dbAnalyses []*config.DatabaseAnalysis,
+	redisAnalyses []*config.RedisAnalysis,
+) []config.Recommendation {
+	recommendations := []config.Recommendation{}
+	if a.pricingData == nil {
+		return recommendations
+	}
+	nonProduction := a.nonProductionReason()
+
+	for i, dbAnalysis := range dbAnalyses {
+		if rec := a.postgresSavings(dbAnalysis, nonProduction); rec != nil {
+			recommendations = append(recommendations, forDatastore([]config.Recommendation{*rec}, dbAnalysis.Datastore, i == 0)...)
+		}
+	}
+	for i, redisAnalysis := range redisAnalyses {
+		if rec := a.redisSavings(redisAnalysis); rec != nil {
+			recommendations = append(recommendations, forDatastore([]config.Recommendation{*rec}, redisAnalysis.Datastore, i == 0)...)
+		}
+	}
+	for _, dyno := range a.dynos {
+		if rec := a.dynoSavings(dyno, nonProduction); rec != nil {
+			recommendations = append(recommendations, *rec)
+		}
+	}
+
+	return recommendations
+}
+
+// nonProductionReason explains why the app looks like a non-production one ("" if it doesn't)
+func (a *Analyzer) nonProductionReason() string {
+	if env := a.railsEnv(); env != "production" {
+		return fmt.Sprintf("The app runs in the %s environment", env)
+	}
+	if name := strings.ToLower(a.source.AppName()); nonProductionNameRegex.MatchString(name) {
+		return fmt.Sprintf("The app name %s suggests a non-production app", name)
+	}
+	return ""
+}
+
+// postgresSavings recommends a cheaper plan for a database billed to this app: Standard instead
+// of Premium outside production, otherwise the next plan down when usage is far below its limits
+func (a *Analyzer) postgresSavings(analysis *config.DatabaseAnalysis, nonProduction string) *config.Recommendation {
+	store := analysis.Datastore
+	if store == nil || store.Addon == "" || store.OwnerApp != "" {
+		return nil
+	}
+	plan := strings.ToLower(store.Plan)
+	current, err := a.pricingData.GetPostgresPrice(plan)
+	if err != nil {
+		return nil
+	}
+
+	// Standard plans have the same limits as Premium ones, without high availability
+	if tier, ok := strings.CutPrefix(plan, "premium-"); ok && nonProduction != "" {
+		if standard, err := a.pricingData.GetPostgresPrice("standard-" + tier); err == nil {
+			return &config.Recommendation{
+				Category: "cost",
+				Severity: config.SeverityLow,
+				Title:    "Use a Standard Postgres Plan",
+				Description: fmt.Sprintf("%s; standard-%s has the same %d connections and %d GB of storage without Premium's high availability",
+					nonProduction, tier, standard.MaxConnections, standard.StorageGB),
+				Current:   plan,
+				Suggested: "standard-" + tier,
+				Impact:    formatSavingImpact(current.PriceMonthly, standard.PriceMonthly),
+				AutoApply: false,
+			}
+		}
+	}
+
+	if analysis.Status != config.StatusOptimal {
+		return nil
+	}
+	smaller, smallerPrice := a.smallerPostgresPlan(plan, current)
+	if smaller == "" {
+		return nil
+	}
+
+	connections := analysis.TotalRequired
+	if analysis.Measured != nil && analysis.Measured.Connections > connections {
+		connections = analysis.Measured.Connections
+	}
+	if connections*100 > smallerPrice.MaxConnections*downsizePercent {
+		return nil
+	}
+
+	storage := fmt.Sprintf("check the data fits its %d GB (pg:info unavailable)", smallerPrice.StorageGB)
+	if analysis.Measured != nil {
+		if dataGB, ok := parseDataSizeGB(analysis.Measured.DataSize); ok {
+			if dataGB*100 > float64(smallerPrice.StorageGB*downsizePercent) {
+				return nil
+			}
+			storage = fmt.Sprintf("%s of data fits its %d GB", analysis.Measured.DataSize, smallerPrice.StorageGB)
+		}
+	}
+
+	return &config.Recommendation{
+		Category: "cost",
+		Severity: config.SeverityLow,
+		Title:    "Downsize Postgres Plan",
+		Description: fmt.Sprintf("%d connections would use %.0f%% of %s's %d; %s",
+			connections, float64(connections)/float64(smallerPrice.MaxConnections)*100, smaller, smallerPrice.MaxConnections, storage),
+		Current:   plan,
+		Suggested: smaller,
+		Impact:    formatSavingImpact(current.PriceMonthly, smallerPrice.PriceMonthly),
+		AutoApply: false,
+	}
+}
+
+// smallerPostgresPlan returns the most expensive plan of the same tier (standard, premium, ...)
+// cheaper than the current one ("" if there is none); plans without a known connection limit are skipped
+func (a *Analyzer) smallerPostgresPlan(plan string, current *pricing.PostgresPrice) (string, *pricing.PostgresPrice) {
+	tier, _, _ := strings.Cut(plan, "-")
+
+	smaller := ""
+	var smallerPrice *pricing.PostgresPrice
+	for name, price := range a.pricingData.Postgres {
+		if candidate, _, _ := strings.Cut(name, "-"); candidate != tier || price.PriceMonthly >= current.PriceMonthly || price.MaxConnections <= 0 {
+			continue
+		}
+		if smallerPrice == nil || price.PriceMonthly > smallerPrice.PriceMonthly {
+			price := price
+			smaller, smallerPrice = name, &price
+		}
+	}
+	return smaller, smallerPrice
+}
+
+// redisSavings recommends the provider's next plan down for a Redis billed to this app
+// when connections and memory are far below its limits
+func (a *Analyzer) redisSavings(analysis *config.RedisAnalysis) *config.Recommendation {
+	store := analysis.Datastore
+	if store == nil || store.Addon == "" || store.OwnerApp != "" || analysis.Status != config.StatusOptimal {
+		return nil
+	}
+	service := redisService(store)
+	plan := strings.ToLower(store.Plan)
+	current, err := a.pricingData.GetRedisPlanPrice(service, plan)
+	if err != nil {
+		return nil
+	}
+
+	smaller, smallerPrice := a.smallerRedisPlan(service, plan, current)
+	if smaller == "" {
+		return nil
+	}
+
+	usage := analysis.Usage()
+	if usage*100 > smallerPrice.MaxConnections*downsizePercent {
+		return nil
+	}
+
+	memory := fmt.Sprintf("check the data fits its %d MB (redis:info unavailable)", smallerPrice.MaxMemoryMB)
+	if measured := analysis.Measured; measured != nil && measured.Live {
+		usedMB := float64(measured.UsedMemory) / 1024 / 1024
+		if smallerPrice.MaxMemoryMB > 0 && usedMB*100 > float64(smallerPrice.MaxMemoryMB*downsizePercent) {
+			return nil
+		}
+		memory = fmt.Sprintf("%.0f MB used fits its %d MB", usedMB, smallerPrice.MaxMemoryMB)
+	}
+
+	return &config.Recommendation{
+		Category: "cost",
+		Severity: config.SeverityLow,
+		Title:    "Downsize Redis Plan",
+		Description: fmt.Sprintf("%d connections would use %.0f%% of %s's %d; %s",
+			usage, float64(usage)/float64(smallerPrice.MaxConnections)*100, smaller, smallerPrice.MaxConnections, memory),
+		Current:   plan,
+		Suggested: smaller,
+		Impact:    formatSavingImpact(current.PriceMonthly, smallerPrice.PriceMonthly),
+		AutoApply: false,
+	}
+}
+
+// smallerRedisPlan returns the provider's next plan down from the current one ("" if there is none);
+// plans without a known connection limit are skipped
+func (a *Analyzer) smallerRedisPlan(service, plan string, current *pricing.RedisPrice) (string, *pricing.RedisPrice) {
+	plans := a.pricingData.RedisPlans(service)
+	for i, name := range plans {
+		if name != plan {
+			continue
+		}
+		for j := i - 1; j >= 0; j-- {
+			price, err := a.pricingData.GetRedisPlanPrice(service, plans[j])
+			if err == nil && price.PriceMonthly < current.PriceMonthly && price.MaxConnections > 0 {
+				return plans[j], price
+			}
+		}
+	}
+	return "", nil
+}
+
+// dynoSavings recommends scaling down an idle process type, or moving Performance dynos to
+// Standard ones outside production or when their measured load leaves the dedicated CPU idle
+// Private Space dynos are left alone since Standard dynos can't run in a Private Space
+func (a *Analyzer) dynoSavings(dyno config.DynoFormation, nonProduction string) *config.Recommendation {
+	if dyno.Quantity == 0 {
+		return nil
+	}
+	current, err := a.pricingData.GetDynoPrice(dyno.Size)
+	if err != nil {
+		return nil
+	}
+	process := a.processRuntime(dyno.Type)
+
+	if rec := a.idleDynoSavings(dyno, current, process); rec != nil {
+		return rec
+	}
+	if !strings.HasPrefix(strings.ToLower(dyno.Size), "performance-") {
+		return nil
+	}
+
+	if nonProduction != "" {
+		standard, err := a.pricingData.GetDynoPrice(nonProductionDynoSize)
+		if err != nil {
+			return nil
+		}
+		return &config.Recommendation{
+			Category:    "cost",
+			Severity:    config.SeverityLow,
+			Title:       fmt.Sprintf("Use Standard Dynos (%s)", dyno.Type),
+			Description: fmt.Sprintf("%s; Standard-2X dynos are enough outside production (check the process fits their %d MB)", nonProduction, standard.MemoryMB),
+			Current:     fmt.Sprintf("%s=%d × %s", dyno.Type, dyno.Quantity, dyno.Size),
+			Suggested:   fmt.Sprintf("heroku ps:type %s=%s", dyno.Type, nonProductionDynoSize),
+			Impact:      formatSavingImpact(current.PriceMonthly*float64(dyno.Quantity), standard.PriceMonthly*float64(dyno.Quantity)),
+			AutoApply:   false,
+		}
+	}
+
+	if process == nil || process.Samples == 0 || process.LoadSamples == 0 || process.LoadAvgP95 >= lowLoadAverage {
+		return nil
+	}
+	for _, size := range []string{"standard-1x", "standard-2x"} {
+		standard, err := a.pricingData.GetDynoPrice(size)
+		if err != nil || float64(standard.MemoryMB*dynoMemoryHeadroom)/100 < process.MemoryPeakMB {
+			continue
+		}
+		return &config.Recommendation{
+			Category: "cost",
+			Severity: config.SeverityLow,
+			Title:    fmt.Sprintf("Use Smaller Dynos (%s)", dyno.Type),
+			Description: fmt.Sprintf("p95 load of %.2f leaves the dedicated CPU mostly idle and the %.0f MB memory peak fits a %s's %d MB",
+				process.LoadAvgP95, process.MemoryPeakMB, size, standard.MemoryMB),
+			Current:   fmt.Sprintf("%s=%d × %s", dyno.Type, dyno.Quantity, dyno.Size),
+			Suggested: fmt.Sprintf("heroku ps:type %s=%s", dyno.Type, size),
+			Impact:    formatSavingImpact(current.PriceMonthly*float64(dyno.Quantity), standard.PriceMonthly*float64(dyno.Quantity)),
+			AutoApply: false,
+		}
+	}
+	return nil
+}
+
+// idleDynoSavings recommends scaling down a worker process type whose dynos did no work over
+// the log window; the router keeps web dynos busy and clocks only wake up on schedule
+func (a *Analyzer) idleDynoSavings(dyno config.DynoFormation, current *pricing.DynoPrice, process *config.ProcessRuntime) *config.Recommendation {
+	if dyno.Type == "web" || process == nil || process.LoadSamples == 0 || process.LoadAvgPeak > idleLoadAverage {
+		return nil
+	}
+	if kind := a.processKind(dyno.Type); kind == project.KindWeb || kind == project.KindClock {
+		return nil
+	}
+	window := a.runtime.To.Sub(a.runtime.From)
+	if window.Hours() < minIdleHours {
+		return nil
+	}
+	rec := &config.Recommendation{
+		Category:  "cost",
+		Severity:  config.SeverityLow,
+		Title:     fmt.Sprintf("Scale Down Idle Dynos (%s)", dyno.Type),
+		Current:   fmt.Sprintf("%s=%d × %s", dyno.Type, dyno.Quantity, dyno.Size),
+		AutoApply: false,
+	}
+	if dyno.Quantity > 1 {
+		rec.Description = fmt.Sprintf("Peak load of %.2f over %.1f hours of logs: one dyno can take the work (I/O-bound jobs barely register in load, so check the queue latency first)",
+			process.LoadAvgPeak, window.Hours())
+		rec.Suggested = fmt.Sprintf("heroku ps:scale %s=1", dyno.Type)
+		rec.Impact = formatSavingImpact(current.PriceMonthly*float64(dyno.Quantity), current.PriceMonthly)
+	} else {
+		rec.Description = fmt.Sprintf("Peak load of %.2f over %.1f hours of logs: if nothing needs it running, scale it to 0 or run its jobs from Heroku Scheduler (jobs enqueued meanwhile wait until it's scaled back up)",
+			process.LoadAvgPeak, window.Hours())
+		rec.Suggested = fmt.Sprintf("heroku ps:scale %s=0", dyno.Type)
+		rec.Impact = formatSavingImpact(current.PriceMonthly, 0)
+	}
+	return rec
+}
+
+// formatSavingImpact describes the monthly saving of moving from one price to a lower one
+func formatSavingImpact(current, suggested float64) string {
+	return fmt.Sprintf("-$%.2f/month ($%.2f → $%.2f)", current-suggested, current, suggested)
+}
+
+// parseDataSizeGB parses a pg:info size such as "1.2 GB" or "8125 kB" into gigabytes
+func parseDataSizeGB(size string) (float64, bool) {
+	fields := strings.Fields(size)
+	if len(fields) != 2 {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(strings.ReplaceAll(fields[0], ",", ""), 64)
+	if err != nil {
+		return 0, false
+	}
+
+	switch strings.ToLower(fields[1]) {
+	case "bytes", "b":
+		return value / 1024 / 1024 / 1024, true
+	case "kb":
+		return value / 1024 / 1024, true
+	case "mb":
+		return value / 1024, true
+	case "gb":
+		return value, true
+	case "tb":
+		return value * 1024, true
+	}
+	return 0, false
+}
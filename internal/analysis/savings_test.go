@@ -0,0 +1,357 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+	"github.com/leaharmstrong/heroku-calc/internal/pricing"
+)
+
+func TestPostgresSavings(t *testing.T) {
+	store := func(plan string) *config.Datastore {
+		return &config.Datastore{Kind: config.DatastorePostgres, Addon: "pg-main", Service: "heroku-postgresql", Plan: plan, ConfigVars: []string{"DATABASE_URL"}}
+	}
+
+	tests := []struct {
+		name          string
+		analysis      config.DatabaseAnalysis
+		nonProduction string
+		unlimitedPlan bool // Add a standard-1 plan without a known connection limit
+		wantTitle     string
+		wantSuggested string
+		wantImpact    string
+	}{
+		{
+			name:          "far below the next plan down",
+			analysis:      config.DatabaseAnalysis{Datastore: store("standard-2"), Status: config.StatusOptimal, TotalRequired: 30},
+			wantTitle:     "Downsize Postgres Plan",
+			wantSuggested: "standard-0",
+			wantImpact:    "-$150.00/month ($200.00 → $50.00)",
+		},
+		{
+			name:          "plans without a connection limit are skipped",
+			analysis:      config.DatabaseAnalysis{Datastore: store("standard-2"), Status: config.StatusOptimal, TotalRequired: 30},
+			unlimitedPlan: true,
+			wantTitle:     "Downsize Postgres Plan",
+			wantSuggested: "standard-0",
+		},
+		{
+			name:     "too busy for the next plan down",
+			analysis: config.DatabaseAnalysis{Datastore: store("standard-2"), Status: config.StatusOptimal, TotalRequired: 61},
+		},
+		{
+			name: "measured connections count",
+			analysis: config.DatabaseAnalysis{
+				Datastore: store("standard-2"), Status: config.StatusOptimal, TotalRequired: 30,
+				Measured: &config.PostgresInfo{Connections: 90},
+			},
+		},
+		{
+			name: "data too large for the next plan down",
+			analysis: config.DatabaseAnalysis{
+				Datastore: store("standard-2"), Status: config.StatusOptimal, TotalRequired: 30,
+				Measured: &config.PostgresInfo{Connections: 10, DataSize: "40 GB"},
+			},
+		},
+		{
+			name: "data fits",
+			analysis: config.DatabaseAnalysis{
+				Datastore: store("standard-2"), Status: config.StatusOptimal, TotalRequired: 30,
+				Measured: &config.PostgresInfo{Connections: 10, DataSize: "8125 MB"},
+			},
+			wantTitle:     "Downsize Postgres Plan",
+			wantSuggested: "standard-0",
+		},
+		{
+			name:     "smallest plan of the tier",
+			analysis: config.DatabaseAnalysis{Datastore: store("standard-0"), Status: config.StatusOptimal, TotalRequired: 5},
+		},
+		{
+			name:     "needs attention",
+			analysis: config.DatabaseAnalysis{Datastore: store("standard-2"), Status: config.StatusWarning, TotalRequired: 30},
+		},
+		{
+			name: "billed to another app",
+			analysis: config.DatabaseAnalysis{
+				Datastore: &config.Datastore{Addon: "pg-shared", Plan: "standard-2", OwnerApp: "billing", ConfigVars: []string{"DATABASE_URL"}},
+				Status:    config.StatusOptimal, TotalRequired: 5,
+			},
+		},
+		{
+			name:     "external database",
+			analysis: config.DatabaseAnalysis{Datastore: &config.Datastore{Plan: "unknown", ConfigVars: []string{"DATABASE_URL"}}, Status: config.StatusOptimal},
+		},
+		{
+			name:          "Premium outside production",
+			analysis:      config.DatabaseAnalysis{Datastore: store("premium-2"), Status: config.StatusWarning, TotalRequired: 200},
+			nonProduction: "The app runs in the staging environment",
+			wantTitle:     "Use a Standard Postgres Plan",
+			wantSuggested: "standard-2",
+			wantImpact:    "-$150.00/month ($350.00 → $200.00)",
+		},
+		{
+			name:          "Premium in production",
+			analysis:      config.DatabaseAnalysis{Datastore: store("premium-2"), Status: config.StatusOptimal, TotalRequired: 200},
+			wantSuggested: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := newTestAnalyzer(t, testApp{})
+			if tt.unlimitedPlan {
+				analyzer.pricingData.Postgres["standard-1"] = pricing.PostgresPrice{Name: "Standard 1", PriceMonthly: 100, StorageGB: 128}
+			}
+
+			rec := analyzer.postgresSavings(&tt.analysis, tt.nonProduction)
+			checkSaving(t, rec, tt.wantTitle, tt.wantSuggested, tt.wantImpact)
+		})
+	}
+}
+
+func TestRedisSavings(t *testing.T) {
+	store := func(service, plan string) *config.Datastore {
+		return &config.Datastore{Kind: config.DatastoreRedis, Addon: "redis-main", Service: service, Plan: plan, ConfigVars: []string{"REDIS_URL"}}
+	}
+
+	tests := []struct {
+		name          string
+		analysis      config.RedisAnalysis
+		unlimitedPlan bool // Add a premium-05 plan without a known connection limit
+		wantSuggested string
+		wantImpact    string
+	}{
+		{
+			name:          "far below the next plan down",
+			analysis:      config.RedisAnalysis{Datastore: store("heroku-redis", "premium-2"), Status: config.StatusOptimal, EstimatedUsage: 30},
+			wantSuggested: "premium-1",
+			wantImpact:    "-$50.00/month ($100.00 → $50.00)",
+		},
+		{
+			name:          "plans without a connection limit are skipped",
+			analysis:      config.RedisAnalysis{Datastore: store("heroku-redis", "premium-1"), Status: config.StatusOptimal, EstimatedUsage: 10},
+			unlimitedPlan: true,
+			wantSuggested: "premium-0",
+		},
+		{
+			name:     "too busy for the next plan down",
+			analysis: config.RedisAnalysis{Datastore: store("heroku-redis", "premium-2"), Status: config.StatusOptimal, EstimatedUsage: 51},
+		},
+		{
+			name: "live clients count",
+			analysis: config.RedisAnalysis{
+				Datastore: store("heroku-redis", "premium-2"), Status: config.StatusOptimal, EstimatedUsage: 10,
+				Measured: &config.RedisInfo{Live: true, ConnectedClients: 80},
+			},
+		},
+		{
+			name: "memory too large for the next plan down",
+			analysis: config.RedisAnalysis{
+				Datastore: store("heroku-redis", "premium-2"), Status: config.StatusOptimal, EstimatedUsage: 10,
+				Measured: &config.RedisInfo{Live: true, ConnectedClients: 10, UsedMemory: 600 << 20},
+			},
+		},
+		{
+			name:     "cheapest plan",
+			analysis: config.RedisAnalysis{Datastore: store("heroku-redis", "mini"), Status: config.StatusOptimal, EstimatedUsage: 1},
+		},
+		{
+			name:     "needs attention",
+			analysis: config.RedisAnalysis{Datastore: store("heroku-redis", "premium-2"), Status: config.StatusWarning, EstimatedUsage: 10},
+		},
+		{
+			name:     "unknown provider",
+			analysis: config.RedisAnalysis{Datastore: store("keydb-cloud", "large"), Status: config.StatusOptimal, EstimatedUsage: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := newTestAnalyzer(t, testApp{})
+			if tt.unlimitedPlan {
+				analyzer.pricingData.Redis["premium-05"] = pricing.RedisPrice{Name: "Premium 0.5", PriceMonthly: 30, MaxMemoryMB: 512}
+			}
+
+			rec := analyzer.redisSavings(&tt.analysis)
+			title := ""
+			if tt.wantSuggested != "" {
+				title = "Downsize Redis Plan"
+			}
+			checkSaving(t, rec, title, tt.wantSuggested, tt.wantImpact)
+		})
+	}
+}
+
+func TestDynoSavings(t *testing.T) {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	runtime := func(hours float64, processes ...*config.ProcessRuntime) *config.RuntimeMetrics {
+		metrics := &config.RuntimeMetrics{From: start, To: start.Add(time.Duration(hours * float64(time.Hour))), Processes: map[string]*config.ProcessRuntime{}}
+		for _, process := range processes {
+			metrics.Processes[process.Type] = process
+		}
+		return metrics
+	}
+	idleWorker := &config.ProcessRuntime{Type: "worker", Samples: 100, LoadSamples: 100, LoadAvgPeak: 0.01, MemoryPeakMB: 200}
+	quietWeb := &config.ProcessRuntime{Type: "web", Samples: 100, LoadSamples: 100, LoadAvgP95: 0.4, LoadAvgPeak: 0.9, MemoryPeakMB: 700}
+
+	tests := []struct {
+		name          string
+		app           string
+		env           map[string]string
+		dyno          config.DynoFormation
+		runtime       *config.RuntimeMetrics
+		wantTitle     string
+		wantSuggested string
+		wantImpact    string
+	}{
+		{
+			name:          "idle workers",
+			dyno:          config.DynoFormation{Type: "worker", Quantity: 3, Size: "Standard-1X"},
+			runtime:       runtime(6, idleWorker),
+			wantTitle:     "Scale Down Idle Dynos (worker)",
+			wantSuggested: "heroku ps:scale worker=1",
+			wantImpact:    "-$50.00/month ($75.00 → $25.00)",
+		},
+		{
+			name:          "idle single worker",
+			dyno:          config.DynoFormation{Type: "worker", Quantity: 1, Size: "Standard-1X"},
+			runtime:       runtime(6, idleWorker),
+			wantTitle:     "Scale Down Idle Dynos (worker)",
+			wantSuggested: "heroku ps:scale worker=0",
+		},
+		{
+			name:    "log window too short to call a worker idle",
+			dyno:    config.DynoFormation{Type: "worker", Quantity: 3, Size: "Standard-1X"},
+			runtime: runtime(0.5, idleWorker),
+		},
+		{
+			name:    "worker without load samples",
+			dyno:    config.DynoFormation{Type: "worker", Quantity: 3, Size: "Standard-1X"},
+			runtime: runtime(6, &config.ProcessRuntime{Type: "worker", Samples: 100, MemoryPeakMB: 200}),
+		},
+		{
+			name:          "Performance dynos with low load",
+			dyno:          config.DynoFormation{Type: "web", Quantity: 2, Size: "Performance-M"},
+			runtime:       runtime(6, quietWeb),
+			wantTitle:     "Use Smaller Dynos (web)",
+			wantSuggested: "heroku ps:type web=standard-2x",
+			wantImpact:    "-$400.00/month ($500.00 → $100.00)",
+		},
+		{
+			name:    "Performance dynos under load",
+			dyno:    config.DynoFormation{Type: "web", Quantity: 2, Size: "Performance-M"},
+			runtime: runtime(6, &config.ProcessRuntime{Type: "web", Samples: 100, LoadSamples: 100, LoadAvgP95: 2.5, LoadAvgPeak: 3, MemoryPeakMB: 700}),
+		},
+		{
+			name:    "Performance dynos using their memory",
+			dyno:    config.DynoFormation{Type: "web", Quantity: 2, Size: "Performance-M"},
+			runtime: runtime(6, &config.ProcessRuntime{Type: "web", Samples: 100, LoadSamples: 100, LoadAvgP95: 0.4, MemoryPeakMB: 1500}),
+		},
+		{
+			name:          "Performance dynos on a staging app",
+			app:           "shop-staging",
+			dyno:          config.DynoFormation{Type: "web", Quantity: 1, Size: "Performance-M"},
+			wantTitle:     "Use Standard Dynos (web)",
+			wantSuggested: "heroku ps:type web=standard-2x",
+			wantImpact:    "-$200.00/month ($250.00 → $50.00)",
+		},
+		{
+			name:          "Performance dynos outside production",
+			env:           map[string]string{"RAILS_ENV": "staging"},
+			dyno:          config.DynoFormation{Type: "web", Quantity: 1, Size: "Performance-M"},
+			wantTitle:     "Use Standard Dynos (web)",
+			wantSuggested: "heroku ps:type web=standard-2x",
+		},
+		{
+			name: "Private Space dynos",
+			app:  "shop-staging",
+			dyno: config.DynoFormation{Type: "web", Quantity: 1, Size: "Private-M"},
+		},
+		{
+			name: "scaled to zero",
+			dyno: config.DynoFormation{Type: "worker", Quantity: 0, Size: "Performance-M"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := newTestAnalyzer(t, testApp{name: tt.app, env: tt.env, dynos: []config.DynoFormation{tt.dyno}})
+			analyzer.SetMeasurements(Measurements{Runtime: tt.runtime})
+
+			rec := analyzer.dynoSavings(tt.dyno, analyzer.nonProductionReason())
+			checkSaving(t, rec, tt.wantTitle, tt.wantSuggested, tt.wantImpact)
+		})
+	}
+}
+
+func TestNonProductionReason(t *testing.T) {
+	tests := []struct {
+		app        string
+		env        map[string]string
+		production bool
+	}{
+		{app: "shop", production: true},
+		{app: "shop-production", production: true},
+		{app: "testimonials", production: true},
+		{app: "shop-staging"},
+		{app: "shop-pr-123"},
+		{app: "qa_shop"},
+		{app: "shop", env: map[string]string{"RAILS_ENV": "staging"}},
+		{app: "shop", env: map[string]string{"RACK_ENV": "development"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.app, func(t *testing.T) {
+			reason := newTestAnalyzer(t, testApp{name: tt.app, env: tt.env}).nonProductionReason()
+			if (reason == "") != tt.production {
+				t.Errorf("nonProductionReason() = %q; want production: %v", reason, tt.production)
+			}
+		})
+	}
+}
+
+func TestParseDataSizeGB(t *testing.T) {
+	tests := []struct {
+		size string
+		want float64
+		ok   bool
+	}{
+		{size: "1.5 GB", want: 1.5, ok: true},
+		{size: "2048 MB", want: 2, ok: true},
+		{size: "1,048,576 kB", want: 1, ok: true},
+		{size: "2 TB", want: 2048, ok: true},
+		{size: "1073741824 bytes", want: 1, ok: true},
+		{size: "unknown"},
+		{size: "12 PB"},
+		{size: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			got, ok := parseDataSizeGB(tt.size)
+			if ok != tt.ok || !within(got, tt.want) {
+				t.Errorf("parseDataSizeGB(%q) = %v, %v; want %v, %v", tt.size, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+// checkSaving compares a cost recommendation with the expected title, suggestion and impact
+// An empty suggestion expects no recommendation; an empty title or impact isn't checked
+func checkSaving(t *testing.T, rec *config.Recommendation, title, suggested, impact string) {
+	t.Helper()
+	if suggested == "" {
+		if rec != nil {
+			t.Errorf("got %q suggesting %q; want no recommendation", rec.Title, rec.Suggested)
+		}
+		return
+	}
+	if rec == nil {
+		t.Fatalf("got no recommendation; want %q", suggested)
+	}
+	if rec.Category != "cost" || rec.Suggested != suggested || (title != "" && rec.Title != title) || (impact != "" && rec.Impact != impact) {
+		t.Errorf("got %s %q suggesting %q (%s); want %q suggesting %q (%s)",
+			rec.Category, rec.Title, rec.Suggested, rec.Impact, title, suggested, impact)
+	}
+}
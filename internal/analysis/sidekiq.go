@@ -0,0 +1,94 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/leaharmstrong/heroku-calc/internal/project"
+)
+
+// defaultSidekiqConcurrency is used when neither the command, the config file nor the env set concurrency
+// and the Sidekiq version is unknown
+const defaultSidekiqConcurrency = 10
+
+// sidekiqDefaultConcurrency returns the built-in concurrency of the locked Sidekiq version
+func (a *Analyzer) sidekiqDefaultConcurrency() int {
+	switch major := project.MajorVersion(a.stack().SidekiqVersion); {
+	case major >= 7:
+		return 5
+	case major == 6:
+		return 10
+	case major > 0:
+		return 25
+	}
+	return defaultSidekiqConcurrency
+}
+
+// sidekiqSetting is the resolved Sidekiq configuration of one process type
+type sidekiqSetting struct {
+	Concurrency int      // Total threads, including additional capsules
+	Source      string   // "Procfile", the config file path, "SIDEKIQ_CONCURRENCY" or "default"
+	Queues      []string // Queues processed, if known
+	Warning     string   // Set when the config could not be interpreted
+}
+
+// sidekiqSettings resolves the threads a Sidekiq process type runs, preferring
+// the -c flag in its command, then its config file, then SIDEKIQ_CONCURRENCY
+func (a *Analyzer) sidekiqSettings(dynoType string) sidekiqSetting {
+	process := a.project.Process(dynoType)
+
+	args := project.SidekiqArgs{}
+	if process != nil {
+		args = project.ParseSidekiqArgs(process.Command)
+	}
+
+	env := args.Environment
+	if env == "" {
+		env = a.railsEnv()
+	}
+
+	cfg := a.project.SidekiqConfigFor(process)
+	if cfg != nil {
+		cfg = cfg.ForEnv(env)
+	}
+
+	setting := sidekiqSetting{Queues: args.Queues}
+	if value, ok := args.ConcurrencyValue(a.envVars); ok {
+		setting.Concurrency = value
+		setting.Source = a.project.ProcessFile
+	} else if args.Concurrency != "" {
+		setting.Warning = fmt.Sprintf("%s: could not interpret `-c %s` for %s", a.project.ProcessFile, args.Concurrency, dynoType)
+	}
+
+	if setting.Source == "" && cfg != nil {
+		if value, ok := cfg.Concurrency(a.envVars); ok {
+			setting.Concurrency = value
+			setting.Source = cfg.Path
+		} else if cfg.ConcurrencyExpr != "" && setting.Warning == "" {
+			setting.Warning = fmt.Sprintf("%s: could not interpret `:concurrency: %s`", cfg.Path, cfg.ConcurrencyExpr)
+		}
+	}
+
+	if setting.Source == "" {
+		if a.hasEnvVar("SIDEKIQ_CONCURRENCY") {
+			setting.Concurrency = a.getEnvVarInt("SIDEKIQ_CONCURRENCY", a.sidekiqDefaultConcurrency())
+			setting.Source = "SIDEKIQ_CONCURRENCY"
+		} else {
+			setting.Concurrency = a.sidekiqDefaultConcurrency()
+			setting.Source = "default"
+		}
+	}
+
+	if cfg != nil {
+		if len(setting.Queues) == 0 {
+			setting.Queues = cfg.Queues
+		}
+
+		// Capsules run their own threads alongside the default capsule
+		setting.Concurrency += cfg.CapsuleThreads(a.envVars, setting.Concurrency)
+		for _, capsule := range cfg.Capsules {
+			setting.Queues = append(setting.Queues, capsule.Queues...)
+		}
+	}
+
+	return setting
+}
@@ -0,0 +1,117 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+func TestSidekiqDefaultConcurrency(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  int
+	}{
+		{name: "no project", want: defaultSidekiqConcurrency},
+		{name: "no lockfile", files: map[string]string{"Procfile": "worker: bundle exec sidekiq\n"}, want: defaultSidekiqConcurrency},
+		{name: "Sidekiq not locked", files: map[string]string{"Gemfile.lock": gemfileLock("rails (7.1.3)")}, want: defaultSidekiqConcurrency},
+		{name: "Sidekiq 7", files: map[string]string{"Gemfile.lock": gemfileLock("sidekiq (7.2.4)")}, want: 5},
+		{name: "Sidekiq 8", files: map[string]string{"Gemfile.lock": gemfileLock("sidekiq (8.0.1)")}, want: 5},
+		{name: "Sidekiq 6", files: map[string]string{"Gemfile.lock": gemfileLock("sidekiq (6.5.12)")}, want: 10},
+		{name: "Sidekiq 5", files: map[string]string{"Gemfile.lock": gemfileLock("sidekiq (5.2.10)")}, want: 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := newTestAnalyzer(t, testApp{files: tt.files})
+			if got := analyzer.sidekiqDefaultConcurrency(); got != tt.want {
+				t.Errorf("sidekiqDefaultConcurrency() = %d; want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSidekiqSettings(t *testing.T) {
+	sidekiq7 := gemfileLock("sidekiq (7.2.4)")
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		files   map[string]string
+		want    sidekiqSetting
+		warning bool
+	}{
+		{
+			name:  "version default",
+			files: map[string]string{"Procfile": "worker: bundle exec sidekiq\n", "Gemfile.lock": sidekiq7},
+			want:  sidekiqSetting{Concurrency: 5, Source: "default"},
+		},
+		{
+			name:  "SIDEKIQ_CONCURRENCY",
+			env:   map[string]string{"SIDEKIQ_CONCURRENCY": "12"},
+			files: map[string]string{"Procfile": "worker: bundle exec sidekiq\n", "Gemfile.lock": sidekiq7},
+			want:  sidekiqSetting{Concurrency: 12, Source: "SIDEKIQ_CONCURRENCY"},
+		},
+		{
+			name: "config file over the env",
+			env:  map[string]string{"SIDEKIQ_CONCURRENCY": "12"},
+			files: map[string]string{
+				"Procfile":           "worker: bundle exec sidekiq\n",
+				"Gemfile.lock":       sidekiq7,
+				"config/sidekiq.yml": ":concurrency: 8\n:queues:\n  - default\n  - mailers\n",
+			},
+			want: sidekiqSetting{Concurrency: 8, Source: "config/sidekiq.yml", Queues: []string{"default", "mailers"}},
+		},
+		{
+			name: "command flag over the config file",
+			files: map[string]string{
+				"Procfile":           "worker: bundle exec sidekiq -c 20 -q critical\n",
+				"config/sidekiq.yml": ":concurrency: 8\n:queues:\n  - default\n",
+			},
+			want: sidekiqSetting{Concurrency: 20, Source: "Procfile", Queues: []string{"critical"}},
+		},
+		{
+			name: "environment section of the config file",
+			env:  map[string]string{"RAILS_ENV": "staging"},
+			files: map[string]string{
+				"Procfile":           "worker: bundle exec sidekiq\n",
+				"config/sidekiq.yml": ":concurrency: 8\n:staging:\n  :concurrency: 3\n",
+			},
+			want: sidekiqSetting{Concurrency: 3, Source: "config/sidekiq.yml"},
+		},
+		{
+			name: "capsules",
+			files: map[string]string{
+				"Procfile":           "worker: bundle exec sidekiq\n",
+				"Gemfile.lock":       sidekiq7,
+				"config/sidekiq.yml": ":concurrency: 5\n:queues:\n  - default\n:capsules:\n  :single:\n    :concurrency: 1\n    :queues:\n      - serial\n",
+			},
+			want: sidekiqSetting{Concurrency: 6, Source: "config/sidekiq.yml", Queues: []string{"default", "serial"}},
+		},
+		{
+			name:    "uninterpretable flag",
+			files:   map[string]string{"Procfile": "worker: bundle exec sidekiq -c $THREADS\n", "Gemfile.lock": sidekiq7},
+			want:    sidekiqSetting{Concurrency: 5, Source: "default"},
+			warning: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := newTestAnalyzer(t, testApp{
+				env:   tt.env,
+				dynos: []config.DynoFormation{{Type: "worker", Quantity: 1, Size: "Standard-1X"}},
+				files: tt.files,
+			})
+			got := analyzer.sidekiqSettings("worker")
+			if (got.Warning != "") != tt.warning {
+				t.Errorf("Warning = %q; want a warning: %v", got.Warning, tt.warning)
+			}
+			got.Warning = ""
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sidekiqSettings() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
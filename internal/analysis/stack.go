@@ -0,0 +1,33 @@
+package analysis
+
+import (
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+	"github.com/leaharmstrong/heroku-calc/internal/project"
+)
+
+// stack returns the stack profile detected from the project for the app's environment
+// Without a Gemfile.lock the profile is empty and analyzers fall back to assuming Sidekiq and Redis
+func (a *Analyzer) stack() *config.StackProfile {
+	return a.project.StackProfile(a.railsEnv())
+}
+
+// singleJobBackend returns the job backend when Gemfile.lock locks exactly one, or ""
+func (a *Analyzer) singleJobBackend() project.ProcessKind {
+	backends := a.stack().JobBackends
+	if len(backends) != 1 {
+		return ""
+	}
+	return project.ProcessKind(backends[0])
+}
+
+// webUsesRedis returns true if web processes open Redis connections
+// Without a Gemfile.lock this is assumed whenever REDIS_URL is set
+func (a *Analyzer) webUsesRedis() bool {
+	stack := a.stack()
+	return !stack.Detected || stack.UsesRedis()
+}
+
+// webServerThreaded returns false for web servers that run one request per process
+func (a *Analyzer) webServerThreaded() bool {
+	return a.stack().WebServer != "unicorn"
+}
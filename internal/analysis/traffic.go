@@ -0,0 +1,113 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+// Measured traffic thresholds
+const (
+	slowServiceP95MS     = 1000  // p95 above this ties up Puma threads
+	nearTimeoutP99MS     = 20000 // p99 above this is close to the router's 30s timeout
+	serverErrorPercent   = 1.0   // 5xx responses above this share of requests
+	criticalErrorPercent = 1.0   // Crashes and timeouts above this share of requests
+	slowDynoFactor       = 2.0   // A dyno's p95 this many times the overall p95
+	minRequestsPerDyno   = 20    // Fewer requests say nothing about a dyno's latency
+)
+
+// routerErrors describes the router error codes, and whether they point at the app rather than the client
+var routerErrors = map[string]struct {
+	description string
+	critical    bool
+}{
+	"H10": {"App crashed", true},
+	"H11": {"Backlog too deep", true},
+	"H12": {"Request timeout", true},
+	"H13": {"Connection closed without response", false},
+	"H14": {"No web dynos running", true},
+	"H15": {"Idle connection", false},
+	"H18": {"Server request interrupted", false},
+	"H20": {"App boot timeout", true},
+	"H27": {"Client request interrupted", false},
+	"H28": {"Client connection idle", false},
+	"H80": {"Maintenance mode", false},
+}
+
+// clientErrors are raised by the client going away and say nothing about capacity
+var clientErrors = map[string]bool{"H27": true, "H28": true, "H80": true}
+
+// routerErrorDescription returns the name of a router error code, e.g. "Request timeout" for H12
+func routerErrorDescription(code string) string {
+	if known, ok := routerErrors[code]; ok {
+		return known.description
+	}
+	return "Router error"
+}
+
+// analyzeTraffic reports request rate, latency and router errors measured from router logs
+func (a *Analyzer) analyzeTraffic() *config.TrafficAnalysis {
+	if a.router == nil {
+		return nil
+	}
+
+	metrics := a.router
+	analysis := &config.TrafficAnalysis{
+		Metrics: metrics,
+		Status:  config.StatusOptimal,
+		Issues:  []string{},
+	}
+	raise := func(status config.AnalysisStatus, issue string) {
+		analysis.Status = worseStatus(analysis.Status, status)
+		analysis.Issues = append(analysis.Issues, issue)
+	}
+
+	// Router errors, most frequent first
+	codes := make([]string, 0, len(metrics.ErrorCodes))
+	for code := range metrics.ErrorCodes {
+		if !clientErrors[code] {
+			codes = append(codes, code)
+		}
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		if metrics.ErrorCodes[codes[i]] != metrics.ErrorCodes[codes[j]] {
+			return metrics.ErrorCodes[codes[i]] > metrics.ErrorCodes[codes[j]]
+		}
+		return codes[i] < codes[j]
+	})
+	for _, code := range codes {
+		count := metrics.ErrorCodes[code]
+		percent := float64(count) / float64(metrics.Requests) * 100
+		status := config.StatusWarning
+		if routerErrors[code].critical && percent >= criticalErrorPercent {
+			status = config.StatusCritical
+		}
+		raise(status, fmt.Sprintf("%s %s: %d (%.1f%% of requests)", code, routerErrorDescription(code), count, percent))
+	}
+
+	if serverErrors := metrics.StatusClasses["5xx"]; serverErrors > 0 {
+		if percent := float64(serverErrors) / float64(metrics.Requests) * 100; percent >= serverErrorPercent {
+			raise(config.StatusWarning, fmt.Sprintf("%.1f%% of requests returned 5xx", percent))
+		}
+	}
+
+	// Latency
+	switch {
+	case metrics.ServiceP99MS >= nearTimeoutP99MS:
+		raise(config.StatusWarning, fmt.Sprintf("p99 service time %.0f ms is close to the 30s router timeout", metrics.ServiceP99MS))
+	case metrics.ServiceP95MS >= slowServiceP95MS:
+		raise(config.StatusWarning, fmt.Sprintf("p95 service time %.0f ms: slow requests hold Puma threads and queue others", metrics.ServiceP95MS))
+	}
+
+	// Dynos much slower than the rest (memory swapping, noisy neighbours)
+	if len(metrics.Dynos) > 1 && metrics.ServiceP95MS > 0 {
+		for _, dyno := range metrics.Dynos {
+			if dyno.Requests >= minRequestsPerDyno && dyno.ServiceP95MS >= slowDynoFactor*metrics.ServiceP95MS {
+				raise(config.StatusWarning, fmt.Sprintf("%s p95 service time %.0f ms vs %.0f ms overall", dyno.Dyno, dyno.ServiceP95MS, metrics.ServiceP95MS))
+			}
+		}
+	}
+
+	return analysis
+}
@@ -29,6 +29,12 @@ func (a *Analyzer) analyzeWebTier() *config.WebTierAnalysis {
 
 	analysis.DynoType = webDynos.Size
 
+	if webDynos.Quantity == 0 {
+		analysis.Issues = append(analysis.Issues, "Web process type is scaled to zero")
+		analysis.Status = config.StatusWarning
+		return analysis
+	}
+
 	// Get dyno memory from pricing data
 	if dynoPrice, err := a.pricingData.GetDynoPrice(webDynos.Size); err == nil {
 		analysis.DynoMemoryMB = dynoPrice.MemoryMB
@@ -36,9 +42,21 @@ func (a *Analyzer) analyzeWebTier() *config.WebTierAnalysis {
 		analysis.Issues = append(analysis.Issues, fmt.Sprintf("Unknown dyno type: %s", webDynos.Size))
 	}
 
-	// Get concurrency settings
-	analysis.WebConcurrency = a.getEnvVarInt("WEB_CONCURRENCY", 2)
-	analysis.RailsMaxThreads = a.getEnvVarInt("RAILS_MAX_THREADS", 5)
+	// Get concurrency settings from config/puma.rb, env vars or defaults
+	webConcurrency := a.webConcurrency()
+	railsMaxThreads := a.railsMaxThreads()
+	analysis.WebConcurrency = webConcurrency.Value
+	analysis.RailsMaxThreads = railsMaxThreads.Value
+	analysis.ConcurrencySource = webConcurrency.Source
+	analysis.ThreadsSource = railsMaxThreads.Source
+	if puma := a.pumaConfig(); puma != nil {
+		analysis.PreloadApp = puma.PreloadApp
+	}
+	for _, setting := range []pumaSetting{webConcurrency, railsMaxThreads} {
+		if setting.Warning != "" {
+			analysis.Issues = append(analysis.Issues, setting.Warning)
+		}
+	}
 
 	// Calculate total threads per dyno
 	analysis.TotalThreads = analysis.WebConcurrency * analysis.RailsMaxThreads
@@ -48,31 +66,46 @@ func (a *Analyzer) analyzeWebTier() *config.WebTierAnalysis {
 		analysis.MemoryPerThread = analysis.DynoMemoryMB / analysis.TotalThreads
 	}
 
+	// Measured memory from runtime metrics replaces the per-thread rules of thumb
+	analysis.Memory = a.processRuntime("web")
+	measured := analysis.Memory != nil && (analysis.Memory.Samples > 0 || analysis.Memory.R14Errors+analysis.Memory.R15Errors > 0)
+
 	// Analyze configuration
-	if analysis.DynoMemoryMB > 0 {
-		// Recommended minimum memory per thread for Rails apps
-		const minMemoryPerThread = 50 // MB
-		const recommendedMemoryPerThread = 80 // MB
+	// Recommended minimum memory per thread for Rails apps
+	const minMemoryPerThread = 50         // MB
+	const recommendedMemoryPerThread = 80 // MB
 
-		if analysis.MemoryPerThread < minMemoryPerThread {
-			analysis.Status = config.StatusCritical
-			analysis.Issues = append(analysis.Issues, fmt.Sprintf("Too many threads for dyno size: %d MB per thread (recommend minimum %d MB)", analysis.MemoryPerThread, minMemoryPerThread))
-		} else if analysis.MemoryPerThread < recommendedMemoryPerThread {
-			analysis.Status = config.StatusWarning
-			analysis.Issues = append(analysis.Issues, fmt.Sprintf("Tight memory allocation: %d MB per thread (recommend %d+ MB)", analysis.MemoryPerThread, recommendedMemoryPerThread))
-		} else {
-			analysis.Status = config.StatusOptimal
+	switch {
+	case measured:
+		status, issue := memoryRisk(analysis.Memory)
+		analysis.Status = status
+		if issue != "" {
+			analysis.Issues = append(analysis.Issues, issue)
 		}
+	case analysis.DynoMemoryMB == 0:
+		// Nothing to compare against
+	case analysis.MemoryPerThread < minMemoryPerThread:
+		analysis.Status = config.StatusCritical
+		analysis.Issues = append(analysis.Issues, fmt.Sprintf("Too many threads for dyno size: %d MB per thread (recommend minimum %d MB)", analysis.MemoryPerThread, minMemoryPerThread))
+	case analysis.MemoryPerThread < recommendedMemoryPerThread:
+		analysis.Status = config.StatusWarning
+		analysis.Issues = append(analysis.Issues, fmt.Sprintf("Tight memory allocation: %d MB per thread (recommend %d+ MB)", analysis.MemoryPerThread, recommendedMemoryPerThread))
+	default:
+		analysis.Status = config.StatusOptimal
+	}
 
+	if analysis.DynoMemoryMB > 0 {
 		// Check if WEB_CONCURRENCY or RAILS_MAX_THREADS are not set
 		if !a.hasEnvVar("WEB_CONCURRENCY") {
-			analysis.Issues = append(analysis.Issues, "WEB_CONCURRENCY not explicitly set (using default 2)")
+			analysis.Issues = append(analysis.Issues, fmt.Sprintf("WEB_CONCURRENCY not explicitly set (using %s)", webConcurrency.Describe()))
 		}
-		if !a.hasEnvVar("RAILS_MAX_THREADS") {
-			analysis.Issues = append(analysis.Issues, "RAILS_MAX_THREADS not explicitly set (using default 5)")
+		if !a.hasEnvVar("RAILS_MAX_THREADS") && a.webServerThreaded() {
+			analysis.Issues = append(analysis.Issues, fmt.Sprintf("RAILS_MAX_THREADS not explicitly set (using %s)", railsMaxThreads.Describe()))
 		}
+	}
 
-		// Recommendations for specific dyno types
+	// Recommendations for specific dyno types, which measured memory makes unnecessary
+	if analysis.DynoMemoryMB > 0 && !measured {
 		switch webDynos.Size {
 		case "eco", "basic":
 			if analysis.TotalThreads > 5 {
@@ -92,5 +125,26 @@ func (a *Analyzer) analyzeWebTier() *config.WebTierAnalysis {
 		}
 	}
 
+	// Throughput: are there enough threads for the requests in flight?
+	analysis.Capacity = a.estimateCapacity(webDynos, analysis.TotalThreads)
+	if capacity := analysis.Capacity; capacity != nil {
+		switch {
+		case capacity.CurrentUtilization >= 100:
+			analysis.Status = config.StatusCritical
+			analysis.Issues = append(analysis.Issues, fmt.Sprintf("Traffic needs %.1f threads busy but %d web dynos have %d: requests queue at the router (need %d dynos)",
+				capacity.InFlight, capacity.CurrentDynos, capacity.CurrentDynos*capacity.ThreadsPerDyno, capacity.RequiredDynos))
+		case capacity.CurrentUtilization >= capacity.TargetUtilization:
+			analysis.Status = worseStatus(analysis.Status, config.StatusWarning)
+			analysis.Issues = append(analysis.Issues, fmt.Sprintf("Web threads %.0f%% busy at peak (target %.0f%%): need %d dynos",
+				capacity.CurrentUtilization, capacity.TargetUtilization, capacity.RequiredDynos))
+		}
+	}
+
+	// Crashed dynos reduce the capacity actually serving traffic
+	if crashed := webDynos.States["crashed"]; crashed > 0 {
+		analysis.Status = config.StatusCritical
+		analysis.Issues = append(analysis.Issues, fmt.Sprintf("%d of %d web dynos crashed", crashed, webDynos.Quantity))
+	}
+
 	return analysis
 }
@@ -1,6 +1,10 @@
 package config
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // Config represents the .heroku-calc.yml configuration file
 type Config struct {
@@ -25,36 +29,166 @@ type Config struct {
 
 // HerokuEnvVar represents a single environment variable
 type HerokuEnvVar struct {
-	Name  string
-	Value string
+	Name  string `json:"name"`
+	Value string `json:"value"`
 }
 
 // DynoFormation represents the dyno configuration
 type DynoFormation struct {
-	Type     string // "web", "worker", etc.
-	Quantity int
-	Size     string // "Standard-1X", "Performance-M", etc.
+	Type     string         `json:"type"` // "web", "worker", etc.
+	Quantity int            `json:"quantity"`
+	Size     string         `json:"size"`             // "Standard-1X", "Performance-M", etc.
+	States   map[string]int `json:"states,omitempty"` // Running processes by state ("up", "crashed", ...)
+}
+
+// Dyno represents a single running dyno process
+type Dyno struct {
+	Name  string `json:"name"` // "web.1", "run.2345", etc.
+	Type  string `json:"type"` // Process type, "run" for one-off dynos
+	Size  string `json:"size"`
+	State string `json:"state"` // "up", "starting", "crashed", "idle", "down"
+}
+
+// IsOneOff returns true for one-off dynos started with `heroku run`
+func (d Dyno) IsOneOff() bool {
+	return d.Type == "run" || strings.HasPrefix(d.Name, "run.")
 }
 
 // Addon represents a Heroku addon
 type Addon struct {
-	Name    string
-	Plan    string
-	Price   string
-	AddedAt time.Time
+	Name       string    `json:"name"`
+	Plan       string    `json:"plan"`
+	Price      string    `json:"price"`
+	AddedAt    time.Time `json:"added_at"`
+	Service    string    `json:"service,omitempty"`     // Add-on service, e.g. "heroku-postgresql"
+	OwnerApp   string    `json:"owner_app,omitempty"`   // App that owns the add-on ("" when it is this app)
+	ConfigVars []string  `json:"config_vars,omitempty"` // Config vars its attachments set on this app
+}
+
+// PlanName returns the plan without the service prefix, e.g. "standard-0" for "heroku-postgresql:standard-0"
+func (a Addon) PlanName() string {
+	if _, plan, ok := strings.Cut(a.Plan, ":"); ok {
+		return plan
+	}
+	return a.Plan
+}
+
+// ServiceName returns the add-on service, taken from a "service:plan" plan when not recorded
+func (a Addon) ServiceName() string {
+	if a.Service != "" {
+		return a.Service
+	}
+	if service, _, ok := strings.Cut(a.Plan, ":"); ok {
+		return service
+	}
+	return ""
+}
+
+// Datastore kinds
+const (
+	DatastorePostgres = "postgres"
+	DatastoreRedis    = "redis"
+)
+
+// Datastore is a Postgres or Redis instance that one or more of the app's config vars point at
+type Datastore struct {
+	Kind       string   `json:"kind"`
+	Addon      string   `json:"addon,omitempty"` // Add-on name ("" for URLs that aren't an attached add-on)
+	Service    string   `json:"service,omitempty"`
+	Plan       string   `json:"plan"`                // Plan without the service prefix ("unknown" if not an add-on)
+	OwnerApp   string   `json:"owner_app,omitempty"` // App the add-on is attached from ("" when it is this app)
+	ConfigVars []string `json:"config_vars"`         // Config vars pointing at it, primary first
+}
+
+// Name describes the datastore by add-on and config vars, e.g. "postgresql-rigid-123 (DATABASE_URL, HEROKU_POSTGRESQL_ROSE_URL)"
+func (d *Datastore) Name() string {
+	name := d.Addon
+	if name == "" {
+		name = "external"
+	}
+	if d.OwnerApp != "" {
+		name += " from " + d.OwnerApp
+	}
+	if len(d.ConfigVars) > 0 {
+		name += " (" + strings.Join(d.ConfigVars, ", ") + ")"
+	}
+	return name
+}
+
+// HasConfigVar returns true if the named config var points at the datastore
+func (d *Datastore) HasConfigVar(name string) bool {
+	if d == nil {
+		return false
+	}
+	for _, configVar := range d.ConfigVars {
+		if configVar == name {
+			return true
+		}
+	}
+	return false
 }
 
 // AnalysisResult represents the output of configuration analysis
 type AnalysisResult struct {
-	DatabaseAnalysis *DatabaseAnalysis
-	RedisAnalysis    *RedisAnalysis
+	DatabaseAnalysis *DatabaseAnalysis   // Database behind DATABASE_URL (or the first one found)
+	RedisAnalysis    *RedisAnalysis      // Redis behind REDIS_URL (or the first one found)
+	Databases        []*DatabaseAnalysis // Every Postgres datastore, DatabaseAnalysis first
+	RedisInstances   []*RedisAnalysis    // Every Redis datastore, RedisAnalysis first
 	WebTierAnalysis  *WebTierAnalysis
 	Recommendations  []Recommendation
+	ProjectWarnings  []string // Problems reading project files (Procfile, etc.)
+	Stack            *StackProfile
+	MemoryAnalysis   *MemoryAnalysis  // Measured dyno memory (nil without runtime metrics)
+	TrafficAnalysis  *TrafficAnalysis // Measured router traffic (nil without router logs)
+	ReleaseHistory   *ReleaseHistory  // Recent releases (nil when the source has none)
+
+	// Monthly bill at list price (nil without pricing data)
+	Cost *CostAnalysis
+}
+
+// StackProfile describes the app's stack as detected from Gemfile.lock and its Rails config
+type StackProfile struct {
+	Detected         bool // False when the project has no Gemfile.lock
+	RubyVersion      string
+	RailsVersion     string
+	WebServer        string // "puma", "unicorn", "passenger" or "falcon" ("" if unknown)
+	WebServerVersion string
+	JobBackends      []string // "sidekiq", "good_job", "solid_queue", "resque", "delayed_job"
+	SidekiqVersion   string
+	RedisGems        []string // Locked gems that connect to Redis
+	CacheStore       string   // Cache store configured for the environment ("" if not found)
+	CableAdapter     string   // Action Cable adapter for the environment ("" if not found)
+
+	// Env vars the cache store and Action Cable read their Redis URL from ("" if not found)
+	CacheURLEnv string
+	CableURLEnv string
+}
+
+// HasJobBackend returns true if the named job backend's gem is locked
+func (s *StackProfile) HasJobBackend(name string) bool {
+	if s == nil {
+		return false
+	}
+	for _, backend := range s.JobBackends {
+		if backend == name {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesRedis returns true if a locked gem, the cache store or Action Cable connects to Redis
+func (s *StackProfile) UsesRedis() bool {
+	if s == nil {
+		return false
+	}
+	return len(s.RedisGems) > 0 || s.CacheStore == "redis_cache_store" || s.CableAdapter == "redis"
 }
 
 // DatabaseAnalysis contains database connection analysis
 type DatabaseAnalysis struct {
 	DatabaseURL      string
+	Datastore        *Datastore // Add-on and config vars analyzed (nil if none)
 	PostgresPlan     string
 	MaxConnections   int
 	CurrentUsage     int
@@ -63,34 +197,432 @@ type DatabaseAnalysis struct {
 	ThreadsPerWorker int
 	SidekiqDynos     int
 	SidekiqThreads   int
+	Processes        []ProcessUsage
+	Environment      string         // Rails environment used to read config/database.yml
+	Pools            []DatabasePool // Databases from config/database.yml (empty if absent)
+	Measured         *PostgresInfo  // Live numbers from pg:info (nil if unavailable)
 	TotalRequired    int
 	BufferPercent    float64
 	Status           AnalysisStatus
 	Issues           []string
+	RecentChanges    []ReleaseChange // Releases that touched this component, when it needs attention
+
+	// PgBouncers between the processes and Postgres; TotalRequired counts their server
+	// connections instead of the pooled processes' client connections
+	Poolers []*ConnectionPooler
+
+	// Connections per config/database.yml database reaching this datastore (empty without database.yml)
+	Budgets []DatabaseBudget
+}
+
+// Connection pooler kinds
+const (
+	PoolerHeroku    = "heroku"    // Heroku Postgres connection pooling, attached as DATABASE_CONNECTION_POOL_URL
+	PoolerPgBouncer = "pgbouncer" // PgBouncer run in each dyno by the pgbouncer buildpack
+)
+
+// ConnectionPooler models a PgBouncer the app's processes connect through
+type ConnectionPooler struct {
+	Kind                 string
+	Mode                 string   // Pool mode: "transaction", "session" or "statement"
+	Processes            []string // Process types connecting through it
+	ClientConnections    int      // Connections those processes open to the pooler
+	ServerConnections    int      // Connections the pooler opens to Postgres for them
+	MaxServerConnections int      // Server connections the pooler is allowed to open (0 if unknown)
+}
+
+// Describe names the pooler and its mode, e.g. "Heroku connection pooling (transaction mode)"
+func (p *ConnectionPooler) Describe() string {
+	name := "PgBouncer buildpack"
+	if p.Kind == PoolerHeroku {
+		name = "Heroku connection pooling"
+	}
+	return fmt.Sprintf("%s (%s mode)", name, p.Mode)
+}
+
+// WebConnections returns the connections held by web dynos
+func (d *DatabaseAnalysis) WebConnections() int {
+	for _, process := range d.Processes {
+		if process.Type == "web" {
+			return process.Connections
+		}
+	}
+	return d.WebDynos * d.WorkersPerDyno * d.ThreadsPerWorker
+}
+
+// Primary returns true for the database behind DATABASE_URL, which the formation's connections are modelled against
+func (d *DatabaseAnalysis) Primary() bool {
+	return d.Datastore == nil || d.Datastore.HasConfigVar("DATABASE_URL")
+}
+
+// Modelled returns true if the formation's connections are estimated for this database:
+// the primary, or one that config/database.yml databases connect to
+func (d *DatabaseAnalysis) Modelled() bool {
+	return d.Primary() || len(d.Budgets) > 0
+}
+
+// Significant gap between measured and estimated connections
+const (
+	unaccountedMinConnections = 5
+	unaccountedRatio          = 1.5
+)
+
+// UnaccountedConnections returns how many measured connections the estimate doesn't explain,
+// or 0 unless the gap is large enough to suggest leaked or idle connections
+func (d *DatabaseAnalysis) UnaccountedConnections() int {
+	if d.Measured == nil {
+		return 0
+	}
+	gap := d.Measured.Connections - d.TotalRequired
+	if gap < unaccountedMinConnections || float64(d.Measured.Connections) < float64(d.TotalRequired)*unaccountedRatio {
+		return 0
+	}
+	return gap
+}
+
+// ProcessUsage is the estimated connection usage of one process type
+type ProcessUsage struct {
+	Type        string // Process type name from the formation ("web", "sidekiq_critical", ...)
+	Kind        string // What the process runs ("web", "sidekiq", "good_job", "clock", ...)
+	Dynos       int
+	Processes   int // Processes per dyno (Puma workers)
+	Threads     int // Threads per dyno that may need a connection
+	Pool        int // ActiveRecord pool per process of the first database (0 if unknown)
+	Connections int // Dynos × processes × min(pool, threads per process), summed over the databases
+
+	ThreadsSource string   // Where the thread count came from, for Sidekiq ("" if not tracked)
+	Queues        []string // Queues processed, for Sidekiq (empty if unknown)
+}
+
+// ThreadsPerProcess returns the threads in each process of a dyno
+func (u ProcessUsage) ThreadsPerProcess() int {
+	if u.Processes == 0 {
+		return u.Threads
+	}
+	return u.Threads / u.Processes
+}
+
+// DatabaseBudget is the share of a datastore's connections one config/database.yml database takes:
+// every process keeps a separate pool for each database it connects to
+type DatabaseBudget struct {
+	Name        string // Database key ("primary", "primary_replica", "animals", ...)
+	URLEnv      string // Env var holding its URL ("" if it has none)
+	Replica     bool
+	Pool        int // Evaluated pool size (0 if unknown)
+	Connections int // Connections across the formation
+}
+
+// DatabasePool is the ActiveRecord pool configuration of one database
+type DatabasePool struct {
+	Name    string // Database key ("primary", "cache", "queue", ...)
+	Pool    int    // Evaluated pool size (0 if it could not be interpreted)
+	PoolSet bool   // False when database.yml leaves the pool at ActiveRecord's default
+	URLEnv  string // Env var holding the database URL ("" if not from the env)
+	Replica bool
+}
+
+// PostgresInfo is live information about a Heroku Postgres database, as reported by pg:info
+type PostgresInfo struct {
+	Addon           string `json:"addon"`
+	Plan            string `json:"plan"` // Display name, e.g. "Standard 0"
+	Status          string `json:"status"`
+	Version         string `json:"version"`
+	Connections     int    `json:"connections"`      // Connections open when measured
+	ConnectionLimit int    `json:"connection_limit"` // 0 if not reported
+	DataSize        string `json:"data_size"`        // e.g. "1.2 GB" ("" if not reported)
+	DataLimit       string `json:"data_limit"`       // Plan storage limit ("" if not reported)
 }
 
 // RedisAnalysis contains Redis/cache analysis
 type RedisAnalysis struct {
 	RedisURL           string
+	Datastore          *Datastore // Add-on and config vars analyzed (nil if none)
 	RedisPlan          string
 	MaxConnections     int
 	SidekiqConcurrency int
 	RedisPoolSize      int
 	EstimatedUsage     int
+	Measured           *RedisInfo // Live numbers from redis:info (nil if unavailable)
 	Status             AnalysisStatus
 	Issues             []string
+	RecentChanges      []ReleaseChange // Releases that touched this component, when it needs attention
+
+	// Marketplace provider of the add-on, e.g. "Redis Cloud" ("" for Heroku Data for Redis)
+	Provider string
+}
+
+// Usage returns the connected clients when they were measured, otherwise the estimate
+func (r *RedisAnalysis) Usage() int {
+	if r.Measured != nil && r.Measured.Live {
+		return r.Measured.ConnectedClients
+	}
+	return r.EstimatedUsage
+}
+
+// Primary returns true for the Redis behind REDIS_URL
+func (r *RedisAnalysis) Primary() bool {
+	return r.Datastore == nil || r.Datastore.HasConfigVar("REDIS_URL")
+}
+
+// Utilization returns Usage as a percentage of MaxConnections (0 if the limit is unknown)
+func (r *RedisAnalysis) Utilization() float64 {
+	if r.MaxConnections == 0 {
+		return 0
+	}
+	return float64(r.Usage()) / float64(r.MaxConnections) * 100
+}
+
+// RedisInfo is live information about a Heroku Redis instance, from redis:info
+type RedisInfo struct {
+	Addon           string `json:"addon"`
+	Plan            string `json:"plan"` // Display name, e.g. "Premium 0"
+	Version         string `json:"version"`
+	MaxmemoryPolicy string `json:"maxmemory_policy"`
+
+	// Live is true when the fields below were measured; redis:info doesn't report them, so they are
+	// zero unless a snapshot or fixture supplies them
+	Live             bool  `json:"live"`
+	ConnectedClients int   `json:"connected_clients"`
+	MaxClients       int   `json:"max_clients"` // 0 if not reported
+	UsedMemory       int64 `json:"used_memory"` // Bytes
+	Maxmemory        int64 `json:"maxmemory"`   // Bytes (0 if not reported)
+}
+
+// MemoryPercent returns used memory as a percentage of maxmemory (0 if unknown)
+func (r *RedisInfo) MemoryPercent() float64 {
+	if r == nil || r.Maxmemory == 0 {
+		return 0
+	}
+	return float64(r.UsedMemory) / float64(r.Maxmemory) * 100
 }
 
 // WebTierAnalysis contains web tier concurrency analysis
 type WebTierAnalysis struct {
-	DynoType         string
-	DynoMemoryMB     int
-	WebConcurrency   int
-	RailsMaxThreads  int
-	TotalThreads     int
-	MemoryPerThread  int
-	Status           AnalysisStatus
-	Issues           []string
+	DynoType          string
+	DynoMemoryMB      int
+	WebConcurrency    int
+	RailsMaxThreads   int
+	ConcurrencySource string // Where WebConcurrency came from: env var, "config/puma.rb" or "default"
+	ThreadsSource     string // Where RailsMaxThreads came from
+	PreloadApp        bool   // preload_app! in config/puma.rb
+	TotalThreads      int
+	MemoryPerThread   int
+	Memory            *ProcessRuntime   // Measured web dyno memory (nil without runtime metrics)
+	Capacity          *CapacityEstimate // Dynos needed for the traffic (nil without traffic numbers)
+	Status            AnalysisStatus
+	Issues            []string
+	RecentChanges     []ReleaseChange // Releases that touched this component, when it needs attention
+}
+
+// TrafficInput is request rate and service time supplied by the user instead of measured
+type TrafficInput struct {
+	RequestsPerMinute float64
+	ServiceTimeMS     float64 // Mean time a request spends in the app
+}
+
+// CapacityEstimate is the web dyno count the traffic needs, by Little's law:
+// requests in flight = arrival rate × mean service time
+type CapacityEstimate struct {
+	Source             string  // "router logs" or "user-supplied"
+	RequestsPerMinute  float64 // Peak rate the estimate is sized for
+	ServiceTimeMS      float64 // Mean service time
+	InFlight           float64 // Concurrent requests at that rate
+	ThreadsPerDyno     int     // WEB_CONCURRENCY × RAILS_MAX_THREADS
+	TargetUtilization  float64 // Share of threads kept busy, in percent
+	CurrentDynos       int
+	RequiredDynos      int
+	CurrentUtilization float64 // Busy share of today's threads, in percent
+	MonthlyCostDelta   float64 // Cost of scaling to RequiredDynos (negative saves)
+	CostKnown          bool    // Whether the dyno size is in the pricing data
+}
+
+// RuntimeMetrics is dyno memory and load measured from log-runtime-metrics lines
+type RuntimeMetrics struct {
+	Source    string // Where the log lines came from ("heroku logs" or a file path)
+	From      time.Time
+	To        time.Time
+	Processes map[string]*ProcessRuntime // By process type
+}
+
+// ProcessRuntime aggregates the runtime metrics of one process type's dynos
+type ProcessRuntime struct {
+	Type         string
+	Dynos        int // Distinct dynos that reported samples
+	Samples      int // Memory samples
+	MemoryPeakMB float64
+	MemoryP95MB  float64
+	RSSPeakMB    float64
+	QuotaMB      float64 // Reported memory_quota (0 if not seen)
+	LoadAvgP95   float64
+	LoadAvgPeak  float64
+	R14Errors    int // Memory quota exceeded
+	R15Errors    int // Memory quota vastly exceeded
+
+	// Load average samples (the load fields are 0 without any)
+	LoadSamples int
+}
+
+// PeakPercent returns peak memory as a percentage of the quota (0 if the quota is unknown)
+func (p *ProcessRuntime) PeakPercent() float64 {
+	if p.QuotaMB == 0 {
+		return 0
+	}
+	return p.MemoryPeakMB / p.QuotaMB * 100
+}
+
+// P95Percent returns p95 memory as a percentage of the quota (0 if the quota is unknown)
+func (p *ProcessRuntime) P95Percent() float64 {
+	if p.QuotaMB == 0 {
+		return 0
+	}
+	return p.MemoryP95MB / p.QuotaMB * 100
+}
+
+// MemoryAnalysis contains measured dyno memory and R14 risk per process type
+type MemoryAnalysis struct {
+	Source    string
+	From      time.Time
+	To        time.Time
+	Processes []ProcessRuntime // Sorted by process type
+	Status    AnalysisStatus
+	Issues    []string
+}
+
+// RouterMetrics is request throughput, latency and errors measured from heroku[router] lines
+type RouterMetrics struct {
+	Source        string // Where the log lines came from ("heroku logs" or a file path)
+	From          time.Time
+	To            time.Time
+	Requests      int
+	ServiceMeanMS float64
+	ServiceP50MS  float64
+	ServiceP95MS  float64
+	ServiceP99MS  float64
+	ServiceMaxMS  float64
+	ConnectP95MS  float64
+	StatusClasses map[string]int // "2xx", "3xx", "4xx", "5xx"
+	ErrorCodes    map[string]int // Router error codes ("H12": 3)
+	Dynos         []DynoTraffic  // Sorted by process type and dyno number
+
+	PeakRequestsPerMinute float64 // Busiest calendar minute
+}
+
+// Duration returns the period covered by the router lines
+func (r *RouterMetrics) Duration() time.Duration {
+	return r.To.Sub(r.From)
+}
+
+// RequestsPerMinute returns the request rate (0 if the lines span no time)
+func (r *RouterMetrics) RequestsPerMinute() float64 {
+	minutes := r.Duration().Minutes()
+	if minutes <= 0 {
+		return 0
+	}
+	return float64(r.Requests) / minutes
+}
+
+// ErrorCount returns the number of router errors (H10, H12, ...)
+func (r *RouterMetrics) ErrorCount() int {
+	total := 0
+	for _, count := range r.ErrorCodes {
+		total += count
+	}
+	return total
+}
+
+// DynoTraffic is the share of requests one dyno served
+type DynoTraffic struct {
+	Dyno         string
+	Requests     int
+	ServiceP95MS float64
+	Errors       int // Router errors attributed to this dyno
+}
+
+// TrafficAnalysis contains measured router traffic and the problems it shows
+type TrafficAnalysis struct {
+	Metrics *RouterMetrics
+	Status  AnalysisStatus
+	Issues  []string
+}
+
+// Release is one entry of `heroku releases`
+type Release struct {
+	Version     int       `json:"version"`
+	Description string    `json:"description"`
+	User        string    `json:"user"` // Email of whoever made the release
+	CreatedAt   time.Time `json:"created_at"`
+	Status      string    `json:"status"`
+	Current     bool      `json:"current"`
+}
+
+// Release change kinds
+const (
+	ReleaseConfig   = "config"
+	ReleaseAddon    = "addon"
+	ReleaseDeploy   = "deploy"
+	ReleaseRollback = "rollback"
+	ReleaseOther    = "other"
+)
+
+// ReleaseChange is one thing a release changed: a config var, an add-on or the code
+type ReleaseChange struct {
+	Version     int       `json:"version"`
+	User        string    `json:"user"`
+	CreatedAt   time.Time `json:"created_at"`
+	Kind        string    `json:"kind"`
+	Subject     string    `json:"subject,omitempty"`   // Config var name or add-on attachment
+	Removed     bool      `json:"removed,omitempty"`   // Config var unset
+	OldValue    string    `json:"old_value,omitempty"` // Config var values, kept only for numeric settings
+	NewValue    string    `json:"new_value,omitempty"`
+	Description string    `json:"description"`
+}
+
+// Summary describes the change, e.g. "RAILS_MAX_THREADS changed from 5 to 10 in v412 by alice@example.com, 3 days ago"
+func (c ReleaseChange) Summary(now time.Time) string {
+	var what string
+	switch {
+	case c.Kind != ReleaseConfig:
+		what = c.Description
+	case c.Removed:
+		what = c.Subject + " removed"
+	case c.OldValue != "" && c.NewValue != "":
+		what = fmt.Sprintf("%s changed from %s to %s", c.Subject, c.OldValue, c.NewValue)
+	case c.NewValue != "":
+		what = fmt.Sprintf("%s set to %s", c.Subject, c.NewValue)
+	default:
+		what = c.Subject + " changed"
+	}
+
+	summary := fmt.Sprintf("%s in v%d", what, c.Version)
+	if c.User != "" {
+		summary += " by " + c.User
+	}
+	return summary + ", " + FormatAge(now.Sub(c.CreatedAt))
+}
+
+// FormatAge describes how long ago something happened ("3 days ago")
+func FormatAge(age time.Duration) string {
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", unit)
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return plural(int(age.Minutes()), "minute")
+	case age < 24*time.Hour:
+		return plural(int(age.Hours()), "hour")
+	}
+	return plural(int(age.Hours()/24), "day")
+}
+
+// ReleaseHistory is an app's recent releases and the changes they made
+type ReleaseHistory struct {
+	Releases []Release       `json:"releases"` // Newest first
+	Changes  []ReleaseChange `json:"changes"`  // Newest first
 }
 
 // Recommendation represents a suggested configuration change
@@ -126,3 +658,45 @@ const (
 	SeverityLow      RecommendationSeverity = "low"
 	SeverityInfo     RecommendationSeverity = "info"
 )
+
+// CostAnalysis is the app's monthly bill at list price: the formation and the add-ons it owns
+type CostAnalysis struct {
+	Items    []CostItem
+	Unpriced []string // Dyno sizes and add-on plans missing from the pricing data
+}
+
+// CostItem is one line of the monthly bill
+type CostItem struct {
+	Category string // "Dynos", "Data" or the marketplace category, e.g. "Logging"
+	Name     string // Process type or add-on name
+	Detail   string // Quantity and size, or service and plan
+	Monthly  float64
+}
+
+// Total returns the monthly bill
+func (c *CostAnalysis) Total() float64 {
+	if c == nil {
+		return 0
+	}
+	total := 0.0
+	for _, item := range c.Items {
+		total += item.Monthly
+	}
+	return total
+}
+
+// CategoryTotals returns the categories in bill order with the monthly total of each
+func (c *CostAnalysis) CategoryTotals() ([]string, map[string]float64) {
+	categories := []string{}
+	totals := make(map[string]float64)
+	if c == nil {
+		return categories, totals
+	}
+	for _, item := range c.Items {
+		if _, ok := totals[item.Category]; !ok {
+			categories = append(categories, item.Category)
+		}
+		totals[item.Category] += item.Monthly
+	}
+	return categories, totals
+}
@@ -0,0 +1,72 @@
+package drain
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leaharmstrong/heroku-calc/internal/logs"
+)
+
+// maxFrameBytes bounds a single syslog message; Logplex messages are at most 10 KB
+const maxFrameBytes = 64 * 1024
+
+// ReadFrames reads the octet-counted syslog messages of a Logplex request body
+// Each frame is "<length> <message>", e.g. "83 <40>1 2012-11-30T06:45:29+00:00 host app web.3 - State changed..."
+func ReadFrames(r io.Reader) ([]string, error) {
+	reader := bufio.NewReader(r)
+	var messages []string
+
+	for {
+		// Frames may be separated by newlines when posted by hand
+		b, err := reader.ReadByte()
+		if err == io.EOF {
+			return messages, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read frame: %w", err)
+		}
+		if b == '\n' || b == '\r' || b == ' ' {
+			continue
+		}
+		if err := reader.UnreadByte(); err != nil {
+			return nil, fmt.Errorf("failed to read frame: %w", err)
+		}
+
+		prefix, err := reader.ReadString(' ')
+		if err != nil {
+			return nil, fmt.Errorf("failed to read frame length: %w", err)
+		}
+		length, err := strconv.Atoi(strings.TrimSuffix(prefix, " "))
+		if err != nil || length < 0 || length > maxFrameBytes {
+			return nil, fmt.Errorf("invalid frame length %q", strings.TrimSuffix(prefix, " "))
+		}
+
+		message := make([]byte, length)
+		if _, err := io.ReadFull(reader, message); err != nil {
+			return nil, fmt.Errorf("failed to read frame: %w", err)
+		}
+		messages = append(messages, string(message))
+	}
+}
+
+// ParseSyslog converts an RFC 5424 message from Logplex into a log line
+// "<158>1 2024-05-01T12:00:00.123456+00:00 host heroku router - at=info ..." becomes
+// the line `heroku logs` would print for it
+func ParseSyslog(message string) (logs.Line, bool) {
+	// PRI+VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID MSG
+	parts := strings.SplitN(strings.TrimRight(message, "\r\n"), " ", 7)
+	if len(parts) < 6 || !strings.HasPrefix(parts[0], "<") {
+		return logs.Line{}, false
+	}
+
+	line := logs.Line{Source: parts[3], Dyno: parts[4]}
+	if len(parts) == 7 {
+		line.Message = parts[6]
+	}
+	line.Time, _ = time.Parse(time.RFC3339Nano, parts[1])
+	return line, true
+}
@@ -0,0 +1,120 @@
+package drain
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leaharmstrong/heroku-calc/internal/logs"
+)
+
+// frame octet-counts a syslog message the way Logplex does
+func frame(message string) string {
+	return fmt.Sprintf("%d %s", len(message), message)
+}
+
+const (
+	routerMessage  = `<158>1 2024-05-01T12:00:00.123456+00:00 host heroku router - at=info method=GET path="/" host=demo.herokuapp.com dyno=web.1 connect=1ms service=42ms status=200 bytes=512`
+	runtimeMessage = `<45>1 2024-05-01T12:00:01+00:00 host heroku web.1 - source=web.1 dyno=heroku.1.abc sample#memory_total=184.54MB sample#memory_quota=512.00MB`
+	appMessage     = `<190>1 2024-05-01T12:00:02+00:00 host app web.1 - Started GET "/"`
+)
+
+func TestReadFrames(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "empty", body: ""},
+		{name: "one frame", body: frame(routerMessage), want: []string{routerMessage}},
+		{
+			name: "back to back",
+			body: frame(routerMessage) + frame(runtimeMessage) + frame(appMessage),
+			want: []string{routerMessage, runtimeMessage, appMessage},
+		},
+		{
+			name: "separated by newlines",
+			body: frame(routerMessage) + "\n" + frame(appMessage) + "\r\n",
+			want: []string{routerMessage, appMessage},
+		},
+		{name: "truncated", body: frame(routerMessage)[:60], wantErr: true},
+		{name: "truncated after a good frame", body: frame(appMessage) + frame(routerMessage)[:60], wantErr: true},
+		{name: "length only", body: "83", wantErr: true},
+		{name: "not a length", body: "abc " + routerMessage, wantErr: true},
+		{name: "negative length", body: "-1 x", wantErr: true},
+		{name: "too long", body: fmt.Sprintf("%d x", maxFrameBytes+1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadFrames(strings.NewReader(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadFrames() error = %v; wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadFrames() = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSyslog(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    logs.Line
+		wantOK  bool
+	}{
+		{
+			name:    "router",
+			message: routerMessage,
+			want: logs.Line{
+				Time:    time.Date(2024, 5, 1, 12, 0, 0, 123456000, time.UTC),
+				Source:  "heroku",
+				Dyno:    "router",
+				Message: `at=info method=GET path="/" host=demo.herokuapp.com dyno=web.1 connect=1ms service=42ms status=200 bytes=512`,
+			},
+			wantOK: true,
+		},
+		{
+			name:    "app output with trailing newline",
+			message: appMessage + "\n",
+			want: logs.Line{
+				Time:    time.Date(2024, 5, 1, 12, 0, 2, 0, time.UTC),
+				Source:  "app",
+				Dyno:    "web.1",
+				Message: `Started GET "/"`,
+			},
+			wantOK: true,
+		},
+		{
+			name:    "no message",
+			message: "<158>1 2024-05-01T12:00:00+00:00 host heroku router -",
+			want:    logs.Line{Time: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), Source: "heroku", Dyno: "router"},
+			wantOK:  true,
+		},
+		{
+			name:    "bad timestamp",
+			message: "<158>1 yesterday host heroku router - at=info",
+			want:    logs.Line{Source: "heroku", Dyno: "router", Message: "at=info"},
+			wantOK:  true,
+		},
+		{name: "no priority", message: "2024-05-01T12:00:00+00:00 host heroku router - at=info"},
+		{name: "too few fields", message: "<158>1 2024-05-01T12:00:00+00:00 host"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseSyslog(tt.message)
+			if ok != tt.wantOK {
+				t.Fatalf("ParseSyslog() ok = %v; want %v", ok, tt.wantOK)
+			}
+			if !got.Time.Equal(tt.want.Time) || got.Source != tt.want.Source || got.Dyno != tt.want.Dyno || got.Message != tt.want.Message {
+				t.Errorf("ParseSyslog() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package drain
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// routerFrame frames a router request logged at the given time
+func routerFrame(at time.Time) string {
+	return frame("<158>1 " + at.UTC().Format(time.RFC3339Nano) +
+		` host heroku router - at=info method=GET path="/" dyno=web.1 connect=1ms service=42ms status=200 bytes=512`)
+}
+
+func TestHandler(t *testing.T) {
+	now := time.Now()
+	good := routerFrame(now) + routerFrame(now.Add(time.Second)) + frame(appMessage)
+
+	tests := []struct {
+		name         string
+		method       string
+		user         string
+		password     string
+		body         string
+		wantStatus   int
+		wantRequests int
+	}{
+		{name: "good frames", user: "drain", password: "secret", body: good, wantStatus: http.StatusNoContent, wantRequests: 2},
+		{name: "truncated frame", user: "drain", password: "secret", body: routerFrame(now) + routerFrame(now)[:40], wantStatus: http.StatusBadRequest},
+		{name: "wrong password", user: "drain", password: "guess", body: good, wantStatus: http.StatusUnauthorized},
+		{name: "no auth", body: good, wantStatus: http.StatusUnauthorized},
+		{name: "not a POST", method: http.MethodGet, user: "drain", password: "secret", wantStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			store, err := OpenStore(dir, DefaultRetention)
+			if err != nil {
+				t.Fatal(err)
+			}
+			server := httptest.NewServer(NewHandler(store, "drain", "secret", log.New(io.Discard, "", 0)))
+			defer server.Close()
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			req, err := http.NewRequest(method, server.URL+"/logs", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/logplex-1")
+			if tt.password != "" {
+				req.SetBasicAuth(tt.user, tt.password)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d; want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := recordedRequests(t, store, dir); got != tt.wantRequests {
+				t.Errorf("recorded %d router requests; want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestHandlerRecordsNothingWhenStoreFails(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenStore(dir, DefaultRetention)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Yesterday's aggregate can't be loaded, so a batch spanning midnight fails after today's line parsed
+	now := time.Now()
+	yesterday := now.AddDate(0, 0, -1)
+	if err := os.WriteFile(dayPath(dir, yesterday.UTC().Format(dayFormat)), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := NewHandler(store, "", "", log.New(io.Discard, "", 0))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(routerFrame(now)+routerFrame(yesterday))))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d; want %d", rec.Code, http.StatusInternalServerError)
+	}
+	// Logplex retries the whole request, so none of it may have been counted
+	if collector := store.days[now.UTC().Format(dayFormat)]; collector != nil {
+		if metrics := collector.RouterMetrics("drain"); metrics != nil {
+			t.Errorf("recorded %d router requests from a failed request", metrics.Requests)
+		}
+	}
+}
+
+// recordedRequests flushes the store and returns the router requests in its aggregates
+func recordedRequests(t *testing.T, store *Store, dir string) int {
+	t.Helper()
+	if err := store.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	collector, err := Load(dir, DefaultRetention)
+	if err != nil {
+		return 0
+	}
+	metrics := collector.RouterMetrics("drain")
+	if metrics == nil {
+		return 0
+	}
+	return metrics.Requests
+}
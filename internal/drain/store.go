@@ -0,0 +1,239 @@
+package drain
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leaharmstrong/heroku-calc/internal/logs"
+)
+
+const (
+	storeDir         = ".heroku-calc"
+	storeSubdir      = "drain"
+	dayFormat        = "2006-01-02"
+	aggregateSuffix  = ".json"
+	DefaultRetention = 7 // Days of aggregates kept on disk
+)
+
+// DefaultDir returns the default aggregate directory, ~/.heroku-calc/drain
+func DefaultDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, storeDir, storeSubdir), nil
+}
+
+// Store keeps one aggregate file per UTC day of drained log lines
+// Lines are buffered in memory and written by Flush; days past the retention period are deleted
+type Store struct {
+	dir       string
+	retention int
+
+	mu    sync.Mutex
+	days  map[string]*logs.Collector // Loaded days, by date
+	dirty map[string]bool
+}
+
+// OpenStore creates the aggregate directory if needed
+func OpenStore(dir string, retention int) (*Store, error) {
+	if retention < 1 {
+		retention = DefaultRetention
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create drain directory: %w", err)
+	}
+
+	return &Store{
+		dir:       dir,
+		retention: retention,
+		days:      make(map[string]*logs.Collector),
+		dirty:     make(map[string]bool),
+	}, nil
+}
+
+// Add records a batch of log lines in the aggregates of their days
+// The batch is recorded entirely or not at all, so a request retried after an error isn't counted twice
+// Lines without a timestamp count towards today; lines older than the retention period are dropped
+func (s *Store) Add(lines ...logs.Line) error {
+	cutoff := oldestDay(s.retention)
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Load every day the batch touches before recording any line
+	days := make([]string, len(lines))
+	loaded := make(map[string]*logs.Collector)
+	for i, line := range lines {
+		when := line.Time
+		if when.IsZero() {
+			when = now
+		}
+		day := when.UTC().Format(dayFormat)
+		days[i] = day
+		if day < cutoff || s.days[day] != nil || loaded[day] != nil {
+			continue
+		}
+		collector, err := loadDay(s.dir, day)
+		if err != nil {
+			return err
+		}
+		loaded[day] = collector
+	}
+
+	for day, collector := range loaded {
+		s.days[day] = collector
+	}
+	for i, line := range lines {
+		if days[i] < cutoff {
+			continue
+		}
+		s.days[days[i]].Add(line)
+		s.dirty[days[i]] = true
+	}
+	return nil
+}
+
+// Flush writes the days with new lines, keeps only today's aggregate in memory
+// and deletes aggregates past the retention period
+func (s *Store) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for day := range s.dirty {
+		if err := saveDay(s.dir, day, s.days[day]); err != nil {
+			return err
+		}
+		delete(s.dirty, day)
+	}
+
+	today := time.Now().UTC().Format(dayFormat)
+	for day := range s.days {
+		if day != today {
+			delete(s.days, day)
+		}
+	}
+
+	return s.prune()
+}
+
+// prune deletes aggregate files older than the retention period
+func (s *Store) prune() error {
+	days, err := listDays(s.dir)
+	if err != nil {
+		return err
+	}
+
+	cutoff := oldestDay(s.retention)
+	for _, day := range days {
+		if day < cutoff {
+			if err := os.Remove(dayPath(s.dir, day)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove old aggregate: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// oldestDay returns the first of the last days days, today included
+func oldestDay(days int) string {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	return today.AddDate(0, 0, -(days - 1)).Format(dayFormat)
+}
+
+// Load merges the aggregates of the last days days in dir (today included)
+// Returns an error when there are none, e.g. because the drain hasn't run yet
+func Load(dir string, days int) (*logs.Collector, error) {
+	available, err := listDays(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := oldestDay(days)
+	merged := logs.NewCollector()
+	found := 0
+	for _, day := range available {
+		if day < cutoff {
+			continue
+		}
+		collector, err := loadDay(dir, day)
+		if err != nil {
+			return nil, err
+		}
+		merged.Merge(collector)
+		found++
+	}
+
+	if found == 0 {
+		return nil, fmt.Errorf("no drain aggregates for the last %d days in %s", days, dir)
+	}
+	return merged, nil
+}
+
+// listDays returns the days with an aggregate file in dir, oldest first
+func listDays(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read drain directory: %w", err)
+	}
+
+	var days []string
+	for _, entry := range entries {
+		day, ok := strings.CutSuffix(entry.Name(), aggregateSuffix)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		if _, err := time.Parse(dayFormat, day); err == nil {
+			days = append(days, day)
+		}
+	}
+	sort.Strings(days)
+	return days, nil
+}
+
+// dayPath returns the aggregate file of a day
+func dayPath(dir, day string) string {
+	return filepath.Join(dir, day+aggregateSuffix)
+}
+
+// loadDay reads a day's aggregate, or returns an empty collector if there is none yet
+func loadDay(dir, day string) (*logs.Collector, error) {
+	collector := logs.NewCollector()
+
+	data, err := os.ReadFile(dayPath(dir, day))
+	if os.IsNotExist(err) {
+		return collector, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read aggregate: %w", err)
+	}
+
+	if err := json.Unmarshal(data, collector); err != nil {
+		return nil, fmt.Errorf("failed to parse aggregate %s: %w", dayPath(dir, day), err)
+	}
+	return collector, nil
+}
+
+// saveDay writes a day's aggregate, replacing the file atomically
+func saveDay(dir, day string, collector *logs.Collector) error {
+	data, err := json.Marshal(collector)
+	if err != nil {
+		return fmt.Errorf("failed to marshal aggregate: %w", err)
+	}
+
+	tmp := dayPath(dir, day) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write aggregate: %w", err)
+	}
+	if err := os.Rename(tmp, dayPath(dir, day)); err != nil {
+		return fmt.Errorf("failed to write aggregate: %w", err)
+	}
+	return nil
+}
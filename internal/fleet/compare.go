@@ -0,0 +1,218 @@
+package fleet
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ComparedEnvVars are the env vars that change capacity, compared across apps
+var ComparedEnvVars = []string{
+	"WEB_CONCURRENCY",
+	"RAILS_MAX_THREADS",
+	"DB_POOL",
+	"SIDEKIQ_CONCURRENCY",
+	"GOOD_JOB_MAX_THREADS",
+	"JOB_CONCURRENCY",
+	"REDIS_POOL_SIZE",
+	"RAILS_ENV",
+}
+
+// Comparison is a side-by-side view of several apps
+type Comparison struct {
+	Apps []AppResult
+	Rows []ComparisonRow
+}
+
+// ComparisonRow is one compared setting, with a value per app
+type ComparisonRow struct {
+	Section string // "Formation", "Plans", "Env Vars", "Capacity" or "Recommendations"
+	Name    string
+	Values  []string // One per app, in the order of Comparison.Apps
+
+	// Differs is true when the apps don't all have the same value
+	Differs bool
+
+	// Capacity is true for rows whose differences change connection or thread capacity,
+	// so staging stops being a faithful rehearsal for production
+	Capacity bool
+}
+
+// notSet is shown for values an app doesn't have
+const notSet = "-"
+
+// Compare builds the side-by-side rows for the analyzed apps
+// Apps that failed to load are kept so their error can be shown, but contribute no values
+func Compare(results []AppResult) *Comparison {
+	cmp := &Comparison{Apps: results}
+
+	// Formation: every process type any app runs
+	processTypes := []string{}
+	seen := map[string]bool{}
+	for _, r := range results {
+		if r.Snapshot == nil {
+			continue
+		}
+		for _, dyno := range r.Snapshot.Dynos {
+			if !seen[dyno.Type] {
+				seen[dyno.Type] = true
+				processTypes = append(processTypes, dyno.Type)
+			}
+		}
+	}
+	sort.Strings(processTypes)
+
+	for _, processType := range processTypes {
+		cmp.addRow("Formation", processType, true, func(r AppResult) string {
+			for _, dyno := range r.Snapshot.Dynos {
+				if dyno.Type == processType {
+					return fmt.Sprintf("%d × %s", dyno.Quantity, dyno.Size)
+				}
+			}
+			return notSet
+		})
+	}
+
+	// Plans of every datastore, the primary first
+	cmp.addRow("Plans", "Postgres", true, func(r AppResult) string {
+		var plans []string
+		for _, db := range r.Result.Databases {
+			if db.Datastore != nil {
+				plans = append(plans, db.PostgresPlan)
+			}
+		}
+		return joinOrNotSet(plans)
+	})
+	cmp.addRow("Plans", "Redis", true, func(r AppResult) string {
+		var plans []string
+		for _, redis := range r.Result.RedisInstances {
+			if redis.Datastore != nil {
+				plans = append(plans, redis.RedisPlan)
+			}
+		}
+		return joinOrNotSet(plans)
+	})
+
+	// Concurrency env vars
+	for _, name := range ComparedEnvVars {
+		cmp.addRow("Env Vars", name, name != "RAILS_ENV", func(r AppResult) string {
+			for _, ev := range r.Snapshot.EnvVars {
+				if ev.Name == name {
+					return ev.Value
+				}
+			}
+			return notSet
+		})
+	}
+
+	// Capacity derived by the analysis
+	cmp.addRow("Capacity", "DB connections", true, func(r AppResult) string {
+		db := r.Result.DatabaseAnalysis
+		if db == nil || db.MaxConnections == 0 {
+			return notSet
+		}
+		return fmt.Sprintf("%d / %d", db.TotalRequired, db.MaxConnections)
+	})
+	cmp.addRow("Capacity", "Redis connections", true, func(r AppResult) string {
+		redis := r.Result.RedisAnalysis
+		if redis == nil || redis.MaxConnections == 0 {
+			return notSet
+		}
+		return fmt.Sprintf("%d / %d", redis.Usage(), redis.MaxConnections)
+	})
+	cmp.addRow("Capacity", "Web threads per dyno", true, func(r AppResult) string {
+		web := r.Result.WebTierAnalysis
+		if web == nil || web.TotalThreads == 0 {
+			return notSet
+		}
+		return strconv.Itoa(web.TotalThreads)
+	})
+	cmp.addRow("Capacity", "Database status", false, func(r AppResult) string {
+		if r.Result.DatabaseAnalysis == nil {
+			return notSet
+		}
+		return string(r.Result.DatabaseAnalysis.Status)
+	})
+	cmp.addRow("Capacity", "Redis status", false, func(r AppResult) string {
+		if r.Result.RedisAnalysis == nil {
+			return notSet
+		}
+		return string(r.Result.RedisAnalysis.Status)
+	})
+	cmp.addRow("Capacity", "Web tier status", false, func(r AppResult) string {
+		if r.Result.WebTierAnalysis == nil {
+			return notSet
+		}
+		return string(r.Result.WebTierAnalysis.Status)
+	})
+
+	// Recommendations
+	titles := []string{}
+	seen = map[string]bool{}
+	for _, r := range results {
+		if r.Result == nil {
+			continue
+		}
+		for _, rec := range r.Result.Recommendations {
+			if !seen[rec.Title] {
+				seen[rec.Title] = true
+				titles = append(titles, rec.Title)
+			}
+		}
+	}
+	for _, title := range titles {
+		cmp.addRow("Recommendations", title, false, func(r AppResult) string {
+			for _, rec := range r.Result.Recommendations {
+				if rec.Title == title {
+					return string(rec.Severity)
+				}
+			}
+			return notSet
+		})
+	}
+
+	return cmp
+}
+
+// addRow appends a row, evaluating value only for apps that loaded
+func (c *Comparison) addRow(section, name string, capacity bool, value func(AppResult) string) {
+	row := ComparisonRow{Section: section, Name: name, Values: make([]string, len(c.Apps))}
+
+	first := -1
+	for i, r := range c.Apps {
+		if !r.Loaded() {
+			row.Values[i] = "error"
+			continue
+		}
+		row.Values[i] = value(r)
+
+		if first < 0 {
+			first = i
+		} else if row.Values[i] != row.Values[first] {
+			row.Differs = true
+		}
+	}
+
+	row.Capacity = capacity && row.Differs
+	c.Rows = append(c.Rows, row)
+}
+
+// CapacityDifferences returns the rows whose differences matter for capacity
+func (c *Comparison) CapacityDifferences() []ComparisonRow {
+	rows := []ComparisonRow{}
+	for _, row := range c.Rows {
+		if row.Capacity {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// joinOrNotSet lists values, or notSet when there are none
+func joinOrNotSet(values []string) string {
+	if len(values) == 0 {
+		return notSet
+	}
+	return strings.Join(values, ", ")
+}
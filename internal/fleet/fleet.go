@@ -0,0 +1,95 @@
+package fleet
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/leaharmstrong/heroku-calc/internal/analysis"
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+	"github.com/leaharmstrong/heroku-calc/internal/heroku"
+	"github.com/leaharmstrong/heroku-calc/internal/pricing"
+	"github.com/leaharmstrong/heroku-calc/internal/project"
+	"github.com/leaharmstrong/heroku-calc/internal/snapshot"
+)
+
+// DefaultParallelism is the number of apps analyzed at once unless configured otherwise
+const DefaultParallelism = 4
+
+// AppResult is the analysis of one app in a multi-app run
+type AppResult struct {
+	App      string
+	Stage    string             // Pipeline stage ("" outside pipeline mode)
+	Snapshot *snapshot.Snapshot // Data the analysis ran on
+	Result   *config.AnalysisResult
+	Err      error
+}
+
+// Loaded returns true if the app's data was captured and analyzed
+func (r AppResult) Loaded() bool {
+	return r.Err == nil && r.Result != nil && r.Snapshot != nil
+}
+
+// SourceFactory creates the source for an app
+type SourceFactory func(appName string) (heroku.Source, error)
+
+// AnalyzeApp captures an app's configuration once and analyzes it
+// proj may be nil when no Rails project applies to the app
+func AnalyzeApp(source heroku.Source, pricingData *pricing.Data, proj *project.Project) AppResult {
+	result := AppResult{App: source.AppName()}
+
+	snap, err := snapshot.Capture(source, pricingData)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Snapshot = snap
+
+	analyzer := analysis.NewAnalyzer(snap.Source(), pricingData)
+	analyzer.SetMeasurements(analysis.Measurements{
+		Runtime:  snap.RuntimeMetrics,
+		Router:   snap.RouterMetrics,
+		Releases: snap.ReleaseHistory,
+	})
+	if proj != nil {
+		analyzer.SetProject(proj)
+	}
+	if err := analyzer.LoadData(); err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.Result, result.Err = analyzer.Analyze()
+	return result
+}
+
+// AnalyzeApps analyzes apps concurrently, with at most parallelism in flight
+// Results are returned in the order of apps; failures are reported per app
+func AnalyzeApps(apps []string, newSource SourceFactory, pricingData *pricing.Data, proj *project.Project, parallelism int) []AppResult {
+	if parallelism < 1 {
+		parallelism = DefaultParallelism
+	}
+
+	results := make([]AppResult, len(apps))
+	slots := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+
+	for i, app := range apps {
+		wg.Add(1)
+		slots <- struct{}{}
+
+		go func(i int, app string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			source, err := newSource(app)
+			if err != nil {
+				results[i] = AppResult{App: app, Err: fmt.Errorf("failed to create Heroku client: %w", err)}
+				return
+			}
+			results[i] = AnalyzeApp(source, pricingData, proj)
+		}(i, app)
+	}
+
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,127 @@
+package fleet
+
+import (
+	"sort"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+// ScanEntry is one app's line in a team scan
+type ScanEntry struct {
+	App   string `json:"app"`
+	Error string `json:"error,omitempty"`
+
+	Critical int `json:"critical"` // Components (each database and Redis, web tier) in critical status
+	Warnings int `json:"warnings"` // Components in warning status
+	Issues   int `json:"issues"`   // Issues reported across all components
+
+	DatabaseUtilisation float64 `json:"database_utilisation"` // Percent of Postgres connections used, highest across databases (0 if unknown)
+	RedisUtilisation    float64 `json:"redis_utilisation"`    // Percent of Redis connections used, measured when possible, highest across instances (0 if unknown)
+
+	PostgresPlan string  `json:"postgres_plan,omitempty"` // Plan behind DATABASE_URL
+	RedisPlan    string  `json:"redis_plan,omitempty"`    // Plan behind REDIS_URL
+	Dynos        int     `json:"dynos"`
+	MonthlyCost  float64 `json:"monthly_cost"` // Dynos plus the add-ons the app owns, at list price
+
+	Recommendations []ScanRecommendation `json:"recommendations,omitempty"`
+}
+
+// ScanRecommendation is a recommendation listed in a team scan
+type ScanRecommendation struct {
+	Title    string                        `json:"title"`
+	Severity config.RecommendationSeverity `json:"severity"`
+}
+
+// Utilisation returns the higher of the database and Redis connection utilisation
+func (e ScanEntry) Utilisation() float64 {
+	if e.DatabaseUtilisation > e.RedisUtilisation {
+		return e.DatabaseUtilisation
+	}
+	return e.RedisUtilisation
+}
+
+// Scan summarizes analyzed apps and ranks them by risk, then by cost
+// Apps with the most critical components come first; ties are broken by warnings,
+// connection utilisation and monthly cost. Apps that failed to load are listed last
+func Scan(results []AppResult) []ScanEntry {
+	entries := make([]ScanEntry, len(results))
+	for i, r := range results {
+		entries[i] = scanEntry(r)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if (a.Error == "") != (b.Error == "") {
+			return a.Error == ""
+		}
+		if a.Critical != b.Critical {
+			return a.Critical > b.Critical
+		}
+		if a.Warnings != b.Warnings {
+			return a.Warnings > b.Warnings
+		}
+		if a.Utilisation() != b.Utilisation() {
+			return a.Utilisation() > b.Utilisation()
+		}
+		if a.MonthlyCost != b.MonthlyCost {
+			return a.MonthlyCost > b.MonthlyCost
+		}
+		return a.App < b.App
+	})
+
+	return entries
+}
+
+// scanEntry summarizes one app
+func scanEntry(r AppResult) ScanEntry {
+	entry := ScanEntry{App: r.App}
+	if !r.Loaded() {
+		entry.Error = "no analysis"
+		if r.Err != nil {
+			entry.Error = r.Err.Error()
+		}
+		return entry
+	}
+
+	count := func(status config.AnalysisStatus, issues []string) {
+		switch status {
+		case config.StatusCritical:
+			entry.Critical++
+		case config.StatusWarning:
+			entry.Warnings++
+		}
+		entry.Issues += len(issues)
+	}
+
+	// Every datastore counts toward risk; the primary's plan is listed
+	for _, db := range r.Result.Databases {
+		count(db.Status, db.Issues)
+		if db.MaxConnections > 0 {
+			entry.DatabaseUtilisation = max(entry.DatabaseUtilisation, float64(db.TotalRequired)/float64(db.MaxConnections)*100)
+		}
+	}
+	if db := r.Result.DatabaseAnalysis; db != nil {
+		entry.PostgresPlan = db.PostgresPlan
+	}
+	for _, redis := range r.Result.RedisInstances {
+		count(redis.Status, redis.Issues)
+		entry.RedisUtilisation = max(entry.RedisUtilisation, redis.Utilization())
+	}
+	if redis := r.Result.RedisAnalysis; redis != nil {
+		entry.RedisPlan = redis.RedisPlan
+	}
+	if web := r.Result.WebTierAnalysis; web != nil {
+		count(web.Status, web.Issues)
+	}
+
+	for _, dyno := range r.Snapshot.Dynos {
+		entry.Dynos += dyno.Quantity
+	}
+	entry.MonthlyCost = r.Result.Cost.Total()
+
+	for _, rec := range r.Result.Recommendations {
+		entry.Recommendations = append(entry.Recommendations, ScanRecommendation{Title: rec.Title, Severity: rec.Severity})
+	}
+
+	return entry
+}
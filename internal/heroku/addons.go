@@ -3,31 +3,32 @@ package heroku
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os/exec"
+	"strings"
 	"time"
 
 	"github.com/leaharmstrong/heroku-calc/internal/config"
 )
 
 // GetAddons retrieves all addons for the app
-func (c *Client) GetAddons() ([]config.Addon, error) {
-	if c.useCLI {
-		return c.getAddonsCLI()
-	}
-	return c.getAddonsAPI()
-}
-
-func (c *Client) getAddonsCLI() ([]config.Addon, error) {
+func (c *CLISource) GetAddons() ([]config.Addon, error) {
 	cmd := exec.Command("heroku", "addons", "-a", c.appName, "--json")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get addons via CLI: %w", err)
 	}
+	return parseCLIAddons(output, c.appName)
+}
 
+// parseCLIAddons decodes `heroku addons --json` output, keeping the config vars of the attachments on appName
+func parseCLIAddons(output []byte, appName string) ([]config.Addon, error) {
+	// The CLI groups the app's attachments under each add-on
 	var addons []struct {
-		Name      string    `json:"name"`
-		PlanName  string    `json:"plan_name"`
-		CreatedAt time.Time `json:"created_at"`
+		addonJSON
+		PlanName    string           `json:"plan_name"`
+		Attachments []attachmentJSON `json:"attachments"`
 		// Note: Price is not directly available from heroku addons command
 		// We'll need to look it up from pricing data
 	}
@@ -38,20 +39,116 @@ func (c *Client) getAddonsCLI() ([]config.Addon, error) {
 
 	result := make([]config.Addon, len(addons))
 	for i, addon := range addons {
-		result[i] = config.Addon{
-			Name:    addon.Name,
-			Plan:    addon.PlanName,
-			Price:   "unknown", // Will be populated from pricing data
-			AddedAt: addon.CreatedAt,
+		result[i] = addon.toAddon(appName)
+		if result[i].Plan == "" {
+			result[i].Plan = addon.PlanName
+		}
+		for _, attachment := range addon.Attachments {
+			if attachment.App.Name == appName {
+				result[i].ConfigVars = appendMissing(result[i].ConfigVars, addon.attachmentConfigVars(attachment, appName)...)
+			}
 		}
 	}
 
 	return result, nil
 }
 
-func (c *Client) getAddonsAPI() ([]config.Addon, error) {
-	// TODO: Implement direct API call
-	return nil, fmt.Errorf("API mode not yet implemented - please install Heroku CLI")
+// addonJSON is an add-on as returned by the Platform API and `heroku addons --json`
+type addonJSON struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	AddonService struct {
+		Name string `json:"name"`
+	} `json:"addon_service"`
+	Plan struct {
+		Name string `json:"name"`
+	} `json:"plan"`
+	App struct {
+		Name string `json:"name"`
+	} `json:"app"`
+	ConfigVars []string  `json:"config_vars"` // Config vars it sets on the app that owns it
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// attachmentJSON is an add-on attachment, with the config vars it sets when the API includes them
+type attachmentJSON struct {
+	Name  string `json:"name"`
+	Addon struct {
+		ID string `json:"id"`
+	} `json:"addon"`
+	App struct {
+		Name string `json:"name"`
+	} `json:"app"`
+	ConfigVars []string `json:"config_vars"`
+}
+
+// toAddon converts an API add-on; its config vars come from the app's attachments
+func (a addonJSON) toAddon(appName string) config.Addon {
+	addon := config.Addon{
+		Name:    a.Name,
+		Plan:    a.Plan.Name,
+		Price:   "unknown", // Will be populated from pricing data
+		AddedAt: a.CreatedAt,
+		Service: a.AddonService.Name,
+	}
+	if a.App.Name != appName {
+		addon.OwnerApp = a.App.Name
+	}
+	return addon
+}
+
+// attachmentConfigVars returns the config vars an attachment sets on the app: the ones listed for
+// the attachment, else the add-on's own when the app owns it
+// An add-on attached from another app without listed config vars has none the analysis can use
+func (a addonJSON) attachmentConfigVars(attachment attachmentJSON, appName string) []string {
+	if len(attachment.ConfigVars) > 0 {
+		return attachment.ConfigVars
+	}
+	if a.App.Name == appName {
+		return a.ConfigVars
+	}
+	return nil
+}
+
+// appendMissing appends the names not already in list
+func appendMissing(list []string, names ...string) []string {
+	for _, name := range names {
+		found := false
+		for _, existing := range list {
+			found = found || existing == name
+		}
+		if !found {
+			list = append(list, name)
+		}
+	}
+	return list
+}
+
+// GetAddons retrieves all addons for the app
+func (c *APISource) GetAddons() ([]config.Addon, error) {
+	var addons []addonJSON
+	if err := c.doAPIRequest(http.MethodGet, c.appPath("/addons"), nil, &addons); err != nil {
+		return nil, fmt.Errorf("failed to get addons via API: %w", err)
+	}
+
+	// Attachments carry the config vars each add-on sets on this app when asked to include them
+	var attachments []attachmentJSON
+	headers := map[string]string{"Accept-Inclusion": "config_vars"}
+	if err := c.doRequest(http.MethodGet, c.apiBaseURL, c.appPath("/addon-attachments"), headers, nil, &attachments); err != nil {
+		return nil, fmt.Errorf("failed to get addon attachments via API: %w", err)
+	}
+
+	result := make([]config.Addon, len(addons))
+	for i, addon := range addons {
+		result[i] = addon.toAddon(c.appName)
+		for _, attachment := range attachments {
+			if attachment.Addon.ID == addon.ID {
+				result[i].ConfigVars = appendMissing(result[i].ConfigVars, addon.attachmentConfigVars(attachment, c.appName)...)
+			}
+		}
+	}
+
+	return result, nil
 }
 
 // AddonPlanInfo contains detailed information about an addon plan
@@ -62,14 +159,7 @@ type AddonPlanInfo struct {
 }
 
 // GetAddonPlanInfo retrieves detailed information about a specific addon plan
-func (c *Client) GetAddonPlanInfo(addonName string) (*AddonPlanInfo, error) {
-	if c.useCLI {
-		return c.getAddonPlanInfoCLI(addonName)
-	}
-	return c.getAddonPlanInfoAPI(addonName)
-}
-
-func (c *Client) getAddonPlanInfoCLI(addonName string) (*AddonPlanInfo, error) {
+func (c *CLISource) GetAddonPlanInfo(addonName string) (*AddonPlanInfo, error) {
 	cmd := exec.Command("heroku", "addons:info", addonName, "-a", c.appName, "--json")
 	output, err := cmd.Output()
 	if err != nil {
@@ -97,7 +187,70 @@ func (c *Client) getAddonPlanInfoCLI(addonName string) (*AddonPlanInfo, error) {
 	}, nil
 }
 
-func (c *Client) getAddonPlanInfoAPI(addonName string) (*AddonPlanInfo, error) {
-	// TODO: Implement direct API call
-	return nil, fmt.Errorf("API mode not yet implemented - please install Heroku CLI")
+// GetAddonPlanInfo retrieves detailed information about a specific addon plan
+func (c *APISource) GetAddonPlanInfo(addonName string) (*AddonPlanInfo, error) {
+	var info struct {
+		AddonService struct {
+			Name string `json:"name"`
+		} `json:"addon_service"`
+		Plan struct {
+			Name string `json:"name"`
+		} `json:"plan"`
+	}
+	if err := c.doAPIRequest(http.MethodGet, c.appPath("/addons/"+url.PathEscape(addonName)), nil, &info); err != nil {
+		return nil, fmt.Errorf("failed to get addon info via API: %w", err)
+	}
+
+	return &AddonPlanInfo{
+		Service: info.AddonService.Name,
+		Plan:    info.Plan.Name,
+		Limits:  make(map[string]interface{}),
+	}, nil
+}
+
+// findAddonID returns the ID of the addon attached as configVar,
+// or else the app's addon of the given service listing configVar, or its first one
+func (c *APISource) findAddonID(service, configVar string) (string, error) {
+	// The attachment setting configVar names the add-on, including one attached from another app
+	if attachmentName, ok := strings.CutSuffix(configVar, "_URL"); ok {
+		var attachment struct {
+			Addon struct {
+				ID string `json:"id"`
+			} `json:"addon"`
+		}
+		err := c.doAPIRequest(http.MethodGet, c.appPath("/addon-attachments/"+url.PathEscape(attachmentName)), nil, &attachment)
+		if err == nil && attachment.Addon.ID != "" {
+			return attachment.Addon.ID, nil
+		}
+	}
+
+	var addons []struct {
+		ID           string `json:"id"`
+		AddonService struct {
+			Name string `json:"name"`
+		} `json:"addon_service"`
+		ConfigVars []string `json:"config_vars"`
+	}
+	if err := c.doAPIRequest(http.MethodGet, c.appPath("/addons"), nil, &addons); err != nil {
+		return "", fmt.Errorf("failed to get addons via API: %w", err)
+	}
+
+	addonID := ""
+	for _, addon := range addons {
+		if addon.AddonService.Name != service {
+			continue
+		}
+		if addonID == "" {
+			addonID = addon.ID
+		}
+		for _, name := range addon.ConfigVars {
+			if name == configVar {
+				addonID = addon.ID
+			}
+		}
+	}
+	if addonID == "" {
+		return "", fmt.Errorf("app %s has no %s addon", c.appName, service)
+	}
+	return addonID, nil
 }
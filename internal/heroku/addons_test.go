@@ -0,0 +1,141 @@
+package heroku
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// cliAddonsJSON is trimmed `heroku addons -a demo --json` output
+const cliAddonsJSON = `[
+  {
+    "id": "a1",
+    "name": "postgresql-rigid-1",
+    "addon_service": {"name": "heroku-postgresql"},
+    "plan": {"name": "heroku-postgresql:standard-0"},
+    "app": {"name": "demo"},
+    "config_vars": ["DATABASE_URL", "HEROKU_POSTGRESQL_ROSE_URL"],
+    "attachments": [
+      {"name": "DATABASE", "app": {"name": "demo"}},
+      {"name": "HEROKU_POSTGRESQL_ROSE", "app": {"name": "demo"}}
+    ]
+  },
+  {
+    "id": "a2",
+    "name": "ah-redis-stackhero-2",
+    "addon_service": {"name": "ah-redis-stackhero"},
+    "plan": {"name": "ah-redis-stackhero:ecalli"},
+    "app": {"name": "demo"},
+    "config_vars": ["STACKHERO_REDIS_HOST", "STACKHERO_REDIS_PASSWORD", "STACKHERO_REDIS_URL_CLEAR", "STACKHERO_REDIS_URL_TLS"],
+    "attachments": [
+      {"name": "STACKHERO_REDIS", "app": {"name": "demo"}}
+    ]
+  },
+  {
+    "id": "a3",
+    "name": "postgresql-shared-3",
+    "addon_service": {"name": "heroku-postgresql"},
+    "plan": {"name": "heroku-postgresql:essential-0"},
+    "app": {"name": "other-app"},
+    "config_vars": ["DATABASE_URL"],
+    "attachments": [
+      {"name": "DATABASE", "app": {"name": "other-app"}},
+      {"name": "SHARED_DB", "app": {"name": "demo"}, "config_vars": ["SHARED_DB_URL"]}
+    ]
+  },
+  {
+    "id": "a4",
+    "name": "redis-elsewhere-4",
+    "addon_service": {"name": "heroku-redis"},
+    "plan": {"name": "heroku-redis:mini"},
+    "app": {"name": "other-app"},
+    "config_vars": ["REDIS_URL"],
+    "attachments": [
+      {"name": "CACHE_REDIS", "app": {"name": "demo"}}
+    ]
+  }
+]`
+
+func TestParseCLIAddons(t *testing.T) {
+	addons, err := parseCLIAddons([]byte(cliAddonsJSON), "demo")
+	if err != nil {
+		t.Fatalf("parseCLIAddons: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		plan       string
+		owner      string
+		configVars []string
+	}{
+		{name: "postgresql-rigid-1", plan: "heroku-postgresql:standard-0", configVars: []string{"DATABASE_URL", "HEROKU_POSTGRESQL_ROSE_URL"}},
+		{name: "ah-redis-stackhero-2", plan: "ah-redis-stackhero:ecalli", configVars: []string{"STACKHERO_REDIS_HOST", "STACKHERO_REDIS_PASSWORD", "STACKHERO_REDIS_URL_CLEAR", "STACKHERO_REDIS_URL_TLS"}},
+		{name: "postgresql-shared-3", plan: "heroku-postgresql:essential-0", owner: "other-app", configVars: []string{"SHARED_DB_URL"}},
+		// Attached from another app without config vars listed: none are made up
+		{name: "redis-elsewhere-4", plan: "heroku-redis:mini", owner: "other-app"},
+	}
+
+	if len(addons) != len(tests) {
+		t.Fatalf("got %d add-ons; want %d", len(addons), len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addon := addons[i]
+			if addon.Name != tt.name || addon.Plan != tt.plan || addon.OwnerApp != tt.owner {
+				t.Errorf("got %s %s owned by %q; want %s %s owned by %q", addon.Name, addon.Plan, addon.OwnerApp, tt.name, tt.plan, tt.owner)
+			}
+			if !reflect.DeepEqual(addon.ConfigVars, tt.configVars) {
+				t.Errorf("ConfigVars = %v; want %v", addon.ConfigVars, tt.configVars)
+			}
+		})
+	}
+}
+
+func TestAPISourceGetAddonsConfigVars(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apps/demo/addons":
+			w.Write([]byte(`[
+				{"id": "a1", "name": "postgresql-rigid-1", "addon_service": {"name": "heroku-postgresql"},
+				 "plan": {"name": "heroku-postgresql:standard-0"}, "app": {"name": "demo"},
+				 "config_vars": ["DATABASE_URL", "HEROKU_POSTGRESQL_ROSE_URL"]},
+				{"id": "a3", "name": "postgresql-shared-3", "addon_service": {"name": "heroku-postgresql"},
+				 "plan": {"name": "heroku-postgresql:essential-0"}, "app": {"name": "other-app"},
+				 "config_vars": ["DATABASE_URL"]}
+			]`))
+		case "/apps/demo/addon-attachments":
+			if got := r.Header.Get("Accept-Inclusion"); got != "config_vars" {
+				t.Errorf("Accept-Inclusion = %q; want config_vars", got)
+			}
+			w.Write([]byte(`[
+				{"name": "DATABASE", "addon": {"id": "a1"}, "app": {"name": "demo"}, "config_vars": ["DATABASE_URL"]},
+				{"name": "HEROKU_POSTGRESQL_ROSE", "addon": {"id": "a1"}, "app": {"name": "demo"}, "config_vars": ["HEROKU_POSTGRESQL_ROSE_URL"]},
+				{"name": "SHARED_DB", "addon": {"id": "a3"}, "app": {"name": "demo"}, "config_vars": ["SHARED_DB_URL"]}
+			]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	source := NewAPISource("demo", "token")
+	source.SetAPIBaseURL(server.URL)
+	addons, err := source.GetAddons()
+	if err != nil {
+		t.Fatalf("GetAddons: %v", err)
+	}
+
+	want := map[string][]string{
+		"postgresql-rigid-1":  {"DATABASE_URL", "HEROKU_POSTGRESQL_ROSE_URL"},
+		"postgresql-shared-3": {"SHARED_DB_URL"},
+	}
+	if len(addons) != len(want) {
+		t.Fatalf("got %d add-ons; want %d", len(addons), len(want))
+	}
+	for _, addon := range addons {
+		if !reflect.DeepEqual(addon.ConfigVars, want[addon.Name]) {
+			t.Errorf("%s ConfigVars = %v; want %v", addon.Name, addon.ConfigVars, want[addon.Name])
+		}
+	}
+}
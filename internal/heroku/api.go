@@ -0,0 +1,267 @@
+package heroku
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+const (
+	defaultAPIBaseURL = "https://api.heroku.com"
+	defaultDataAPIURL = "https://api.data.heroku.com"
+	apiAcceptHeader   = "application/vnd.heroku+json; version=3"
+	apiTimeout        = 30 * time.Second
+)
+
+// APISource reads app data from the Heroku Platform API v3
+type APISource struct {
+	appName    string
+	apiToken   string
+	apiBaseURL string
+	dataAPIURL string // Data API base URL, used for pg:info
+	httpClient *http.Client
+}
+
+// NewAPISource creates a source backed by the Platform API, authenticated with token
+func NewAPISource(appName, token string) *APISource {
+	return &APISource{
+		appName:    appName,
+		apiToken:   token,
+		apiBaseURL: defaultAPIBaseURL,
+		dataAPIURL: defaultDataAPIURL,
+		httpClient: &http.Client{Timeout: apiTimeout},
+	}
+}
+
+// SetAPIToken sets the API token for direct API calls
+func (c *APISource) SetAPIToken(token string) {
+	c.apiToken = token
+}
+
+// SetAPIBaseURL overrides the Platform API base URL (e.g. for a local stand-in)
+func (c *APISource) SetAPIBaseURL(baseURL string) {
+	c.apiBaseURL = strings.TrimRight(baseURL, "/")
+}
+
+// SetDataAPIURL overrides the Data API base URL used for database info
+func (c *APISource) SetDataAPIURL(baseURL string) {
+	c.dataAPIURL = strings.TrimRight(baseURL, "/")
+}
+
+// AppName returns the app name
+func (c *APISource) AppName() string {
+	return c.appName
+}
+
+// GetEnvVars retrieves all environment variables for the app
+func (c *APISource) GetEnvVars() ([]config.HerokuEnvVar, error) {
+	var vars map[string]string
+	if err := c.doAPIRequest(http.MethodGet, c.appPath("/config-vars"), nil, &vars); err != nil {
+		return nil, fmt.Errorf("failed to get config vars via API: %w", err)
+	}
+
+	result := make([]config.HerokuEnvVar, 0, len(vars))
+	for name, value := range vars {
+		result = append(result, config.HerokuEnvVar{
+			Name:  name,
+			Value: value,
+		})
+	}
+
+	return result, nil
+}
+
+// GetDynos retrieves the configured dyno formation for the app
+func (c *APISource) GetDynos() ([]config.DynoFormation, error) {
+	var formation []formationJSON
+	if err := c.doAPIRequest(http.MethodGet, c.appPath("/formation"), nil, &formation); err != nil {
+		return nil, fmt.Errorf("failed to get dyno formation via API: %w", err)
+	}
+	return parseFormation(formation)
+}
+
+// formationJSON is a process type of the Platform API formation
+type formationJSON struct {
+	Type     string `json:"type"`
+	Quantity *int   `json:"quantity"`
+	Size     string `json:"size"`
+}
+
+// parseFormation converts the API formation, rejecting entries missing a type, quantity or size
+// so a changed response fails loudly instead of reading as an app with no dynos
+func parseFormation(formation []formationJSON) ([]config.DynoFormation, error) {
+	result := make([]config.DynoFormation, 0, len(formation))
+	for _, f := range formation {
+		if f.Type == "" || f.Quantity == nil || f.Size == "" {
+			return nil, fmt.Errorf("failed to parse dyno formation: process type %q is missing its quantity or size", f.Type)
+		}
+		result = append(result, config.DynoFormation{
+			Type:     f.Type,
+			Quantity: *f.Quantity,
+			Size:     f.Size,
+		})
+	}
+	return result, nil
+}
+
+// GetProcesses retrieves the currently running dynos, including one-off dynos
+func (c *APISource) GetProcesses() ([]config.Dyno, error) {
+	var dynos []config.Dyno
+	if err := c.doAPIRequest(http.MethodGet, c.appPath("/dynos"), nil, &dynos); err != nil {
+		return nil, fmt.Errorf("failed to get dyno info via API: %w", err)
+	}
+	return dynos, nil
+}
+
+// SetEnvVar sets an environment variable on Heroku
+func (c *APISource) SetEnvVar(name, value string) error {
+	body := map[string]string{name: value}
+	if err := c.doAPIRequest(http.MethodPatch, c.appPath("/config-vars"), body, nil); err != nil {
+		return fmt.Errorf("failed to set config var %s: %w", name, err)
+	}
+	return nil
+}
+
+// UnsetEnvVar removes an environment variable from Heroku
+func (c *APISource) UnsetEnvVar(name string) error {
+	// A null value removes the config var
+	body := map[string]*string{name: nil}
+	if err := c.doAPIRequest(http.MethodPatch, c.appPath("/config-vars"), body, nil); err != nil {
+		return fmt.Errorf("failed to unset config var %s: %w", name, err)
+	}
+	return nil
+}
+
+// TestConnection tests if we can connect to Heroku
+func (c *APISource) TestConnection() error {
+	if err := c.doAPIRequest(http.MethodGet, c.appPath(""), nil, nil); err != nil {
+		return fmt.Errorf("failed to connect to Heroku app %s: %w", c.appName, err)
+	}
+	return nil
+}
+
+// GetAppInfo retrieves basic app information
+func (c *APISource) GetAppInfo() (*AppInfo, error) {
+	var app struct {
+		Name   string `json:"name"`
+		Region struct {
+			Name string `json:"name"`
+		} `json:"region"`
+		Stack struct {
+			Name string `json:"name"`
+		} `json:"stack"`
+	}
+	if err := c.doAPIRequest(http.MethodGet, c.appPath(""), nil, &app); err != nil {
+		return nil, fmt.Errorf("failed to get app info via API: %w", err)
+	}
+
+	return &AppInfo{
+		Name:   app.Name,
+		Region: app.Region.Name,
+		Stack:  app.Stack.Name,
+	}, nil
+}
+
+// apiError is the error body returned by the Heroku Platform API
+type apiError struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+// doAPIRequest performs a Platform API request and decodes the JSON response into out
+func (c *APISource) doAPIRequest(method, path string, body interface{}, out interface{}) error {
+	return c.doRequest(method, c.apiBaseURL, path, nil, body, out)
+}
+
+// doAPIRangeRequest lists a Platform API collection with a Range header, e.g. "version ..; order=desc, max=50"
+func (c *APISource) doAPIRangeRequest(path, rangeHeader string, out interface{}) error {
+	return c.doRequest(http.MethodGet, c.apiBaseURL, path, map[string]string{"Range": rangeHeader}, nil, out)
+}
+
+// doRequest performs an authenticated request against baseURL and decodes the JSON response into out
+func (c *APISource) doRequest(method, baseURL, path string, headers map[string]string, body interface{}, out interface{}) error {
+	if c.apiToken == "" {
+		return fmt.Errorf("no Heroku API token configured (set HEROKU_API_KEY or install the Heroku CLI)")
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request body: %w", err)
+		}
+		reqBody = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, baseURL+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to build API request: %w", err)
+	}
+	req.Header.Set("Accept", apiAcceptHeader)
+	req.Header.Set("Authorization", "Bearer "+c.apiToken)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("API request %s %s failed: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read API response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr apiError
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("API request %s %s failed (%d %s): %s", method, path, resp.StatusCode, apiErr.ID, apiErr.Message)
+		}
+		return fmt.Errorf("API request %s %s failed with status %d", method, path, resp.StatusCode)
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to parse API response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// getDataAPIInfo reads a Data API database resource's named info fields,
+// labelled like the fields of `heroku pg:info` and `heroku redis:info`
+func (c *APISource) getDataAPIInfo(path string) (map[string]string, error) {
+	var database struct {
+		Info []struct {
+			Name   string   `json:"name"`
+			Values []string `json:"values"`
+		} `json:"info"`
+	}
+	if err := c.doRequest(http.MethodGet, c.dataAPIURL, path, nil, nil, &database); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]string, len(database.Info))
+	for _, info := range database.Info {
+		fields[info.Name] = strings.Join(info.Values, ", ")
+	}
+	return fields, nil
+}
+
+// appPath returns the API path for the source's app with the given suffix
+func (c *APISource) appPath(suffix string) string {
+	return "/apps/" + url.PathEscape(c.appName) + suffix
+}
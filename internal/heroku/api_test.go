@@ -0,0 +1,295 @@
+package heroku
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+// apiRequest is a request received by a test API server
+type apiRequest struct {
+	Method        string
+	Path          string
+	Accept        string
+	Authorization string
+	ContentType   string
+	Body          string
+}
+
+// newAPIServer serves recorded Platform API responses by "METHOD path" and records every request
+// Requests without a recorded response get the API's not_found error
+func newAPIServer(t *testing.T, responses map[string]string) (*httptest.Server, *[]apiRequest) {
+	t.Helper()
+	requests := &[]apiRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		*requests = append(*requests, apiRequest{
+			Method:        r.Method,
+			Path:          r.URL.Path,
+			Accept:        r.Header.Get("Accept"),
+			Authorization: r.Header.Get("Authorization"),
+			ContentType:   r.Header.Get("Content-Type"),
+			Body:          string(body),
+		})
+
+		response, ok := responses[r.Method+" "+r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"id": "not_found", "message": "Couldn't find that app."}`))
+			return
+		}
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+// newTestAPISource returns a source for the demo app talking to server, authenticated with SetAPIToken
+func newTestAPISource(server *httptest.Server) *APISource {
+	source := NewAPISource("demo", "")
+	source.SetAPIToken("token")
+	source.SetAPIBaseURL(server.URL)
+	return source
+}
+
+func TestAPISourceHeaders(t *testing.T) {
+	server, requests := newAPIServer(t, map[string]string{
+		"GET /apps/demo":               `{"name": "demo"}`,
+		"PATCH /apps/demo/config-vars": `{}`,
+	})
+	source := newTestAPISource(server)
+
+	if err := source.TestConnection(); err != nil {
+		t.Fatalf("TestConnection: %v", err)
+	}
+	if err := source.SetEnvVar("WEB_CONCURRENCY", "3"); err != nil {
+		t.Fatalf("SetEnvVar: %v", err)
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("got %d requests; want 2", len(*requests))
+	}
+	for _, req := range *requests {
+		if req.Accept != "application/vnd.heroku+json; version=3" {
+			t.Errorf("%s %s Accept = %q; want the v3 media type", req.Method, req.Path, req.Accept)
+		}
+		if req.Authorization != "Bearer token" {
+			t.Errorf("%s %s Authorization = %q; want Bearer token", req.Method, req.Path, req.Authorization)
+		}
+	}
+	if got := (*requests)[1].ContentType; got != "application/json" {
+		t.Errorf("PATCH Content-Type = %q; want application/json", got)
+	}
+}
+
+func TestAPISourceReads(t *testing.T) {
+	server, _ := newAPIServer(t, map[string]string{
+		"GET /apps/demo": `{"name": "demo", "region": {"name": "eu"}, "stack": {"name": "heroku-24"}, "web_url": "https://demo.herokuapp.com/"}`,
+		"GET /apps/demo/config-vars": `{
+			"DATABASE_URL": "postgres://u:p@ec2-a.compute.amazonaws.com:5432/d",
+			"RAILS_MAX_THREADS": "5",
+			"WEB_CONCURRENCY": "2"
+		}`,
+	})
+	source := newTestAPISource(server)
+
+	t.Run("GetAppInfo", func(t *testing.T) {
+		info, err := source.GetAppInfo()
+		if err != nil {
+			t.Fatalf("GetAppInfo: %v", err)
+		}
+		want := &AppInfo{Name: "demo", Region: "eu", Stack: "heroku-24"}
+		if !reflect.DeepEqual(info, want) {
+			t.Errorf("GetAppInfo() = %+v; want %+v", info, want)
+		}
+	})
+
+	t.Run("GetEnvVars", func(t *testing.T) {
+		vars, err := source.GetEnvVars()
+		if err != nil {
+			t.Fatalf("GetEnvVars: %v", err)
+		}
+		sort.Slice(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+		want := []config.HerokuEnvVar{
+			{Name: "DATABASE_URL", Value: "postgres://u:p@ec2-a.compute.amazonaws.com:5432/d"},
+			{Name: "RAILS_MAX_THREADS", Value: "5"},
+			{Name: "WEB_CONCURRENCY", Value: "2"},
+		}
+		if !reflect.DeepEqual(vars, want) {
+			t.Errorf("GetEnvVars() = %+v; want %+v", vars, want)
+		}
+	})
+}
+
+func TestAPISourceConfigVarChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   func(source *APISource) error
+		wantBody string
+	}{
+		{
+			name:     "SetEnvVar",
+			change:   func(source *APISource) error { return source.SetEnvVar("WEB_CONCURRENCY", "3") },
+			wantBody: `{"WEB_CONCURRENCY":"3"}`,
+		},
+		{
+			// A null value is what removes the config var
+			name:     "UnsetEnvVar",
+			change:   func(source *APISource) error { return source.UnsetEnvVar("WEB_CONCURRENCY") },
+			wantBody: `{"WEB_CONCURRENCY":null}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newAPIServer(t, map[string]string{"PATCH /apps/demo/config-vars": `{}`})
+			if err := tt.change(newTestAPISource(server)); err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+
+			if len(*requests) != 1 {
+				t.Fatalf("got %d requests; want 1", len(*requests))
+			}
+			req := (*requests)[0]
+			if req.Method != http.MethodPatch || req.Path != "/apps/demo/config-vars" {
+				t.Errorf("request = %s %s; want PATCH /apps/demo/config-vars", req.Method, req.Path)
+			}
+			if req.Body != tt.wantBody {
+				t.Errorf("body = %s; want %s", req.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestAPISourceRequestErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apps/forbidden":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"id": "forbidden", "message": "You do not have access to the app forbidden."}`))
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("<html>Bad Gateway</html>"))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		app     string
+		token   string
+		wantErr []string
+	}{
+		{
+			name:    "API error body",
+			app:     "forbidden",
+			token:   "token",
+			wantErr: []string{"GET /apps/forbidden", "403 forbidden", "You do not have access"},
+		},
+		{
+			name:    "status without an API error body",
+			app:     "flaky",
+			token:   "token",
+			wantErr: []string{"GET /apps/flaky", "status 502"},
+		},
+		{
+			name:    "no token",
+			app:     "demo",
+			wantErr: []string{"no Heroku API token configured"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := NewAPISource(tt.app, tt.token)
+			source.SetAPIBaseURL(server.URL)
+
+			err := source.doAPIRequest(http.MethodGet, source.appPath(""), nil, nil)
+			if err == nil {
+				t.Fatal("doAPIRequest succeeded; want an error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseFormation(t *testing.T) {
+	quantity := func(n int) *int { return &n }
+
+	tests := []struct {
+		name      string
+		formation []formationJSON
+		want      []config.DynoFormation
+		wantErr   bool
+	}{
+		{
+			name: "scaled and scaled to zero",
+			formation: []formationJSON{
+				{Type: "web", Quantity: quantity(3), Size: "Standard-2X"},
+				{Type: "worker", Quantity: quantity(0), Size: "Basic"},
+			},
+			want: []config.DynoFormation{
+				{Type: "web", Quantity: 3, Size: "Standard-2X"},
+				{Type: "worker", Quantity: 0, Size: "Basic"},
+			},
+		},
+		{
+			name:      "no process types",
+			formation: []formationJSON{},
+			want:      []config.DynoFormation{},
+		},
+		{
+			name:      "missing quantity",
+			formation: []formationJSON{{Type: "web", Size: "Standard-2X"}},
+			wantErr:   true,
+		},
+		{
+			name:      "missing size",
+			formation: []formationJSON{{Type: "web", Quantity: quantity(1)}},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFormation(tt.formation)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFormation() error = %v; wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFormation() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPISourceGetDynos(t *testing.T) {
+	server, _ := newAPIServer(t, map[string]string{
+		"GET /apps/demo/formation": `[
+			{"id": "f1", "type": "web", "quantity": 3, "size": "Standard-2X", "command": "bundle exec puma -C config/puma.rb"},
+			{"id": "f2", "type": "worker", "quantity": 0, "size": "Performance-M", "command": "bundle exec sidekiq"}
+		]`,
+	})
+
+	dynos, err := newTestAPISource(server).GetDynos()
+	if err != nil {
+		t.Fatalf("GetDynos: %v", err)
+	}
+	want := []config.DynoFormation{
+		{Type: "web", Quantity: 3, Size: "Standard-2X"},
+		{Type: "worker", Quantity: 0, Size: "Performance-M"},
+	}
+	if !reflect.DeepEqual(dynos, want) {
+		t.Errorf("GetDynos() = %+v; want %+v", dynos, want)
+	}
+}
@@ -0,0 +1,85 @@
+package heroku
+
+import (
+	"fmt"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// BuildpackSource is implemented by sources that can list the app's buildpacks
+type BuildpackSource interface {
+	// GetBuildpacks returns the app's buildpacks in order, as names or URLs
+	GetBuildpacks() ([]string, error)
+}
+
+// Compile-time checks for the buildpack-capable backends
+var (
+	_ BuildpackSource = (*CLISource)(nil)
+	_ BuildpackSource = (*APISource)(nil)
+	_ BuildpackSource = (*FixtureSource)(nil)
+)
+
+// GetBuildpacks runs `heroku buildpacks`
+func (c *CLISource) GetBuildpacks() ([]string, error) {
+	output, err := exec.Command("heroku", "buildpacks", "-a", c.appName).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get buildpacks via CLI: %w", err)
+	}
+	return parseBuildpacks(string(output)), nil
+}
+
+// parseBuildpacks reads the buildpacks listed under the "=== app Buildpack URLs" header,
+// one per line and numbered when there are several
+func parseBuildpacks(output string) []string {
+	buildpacks := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "===") {
+			continue
+		}
+		if number, rest, ok := strings.Cut(line, ". "); ok && strings.Trim(number, "0123456789") == "" {
+			line = strings.TrimSpace(rest)
+		}
+		if !strings.Contains(line, " ") {
+			buildpacks = append(buildpacks, line)
+		}
+	}
+	return buildpacks
+}
+
+// GetBuildpacks reads the app's buildpack installations from the Platform API
+func (c *APISource) GetBuildpacks() ([]string, error) {
+	var installations []struct {
+		Ordinal   int `json:"ordinal"`
+		Buildpack struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"buildpack"`
+	}
+	if err := c.doAPIRequest(http.MethodGet, c.appPath("/buildpack-installations"), nil, &installations); err != nil {
+		return nil, fmt.Errorf("failed to get buildpacks via API: %w", err)
+	}
+
+	sort.Slice(installations, func(i, j int) bool {
+		return installations[i].Ordinal < installations[j].Ordinal
+	})
+
+	buildpacks := make([]string, len(installations))
+	for i, installation := range installations {
+		buildpacks[i] = installation.Buildpack.Name
+		if buildpacks[i] == "" {
+			buildpacks[i] = installation.Buildpack.URL
+		}
+	}
+	return buildpacks, nil
+}
+
+// GetBuildpacks returns the fixture's buildpacks
+func (f *FixtureSource) GetBuildpacks() ([]string, error) {
+	if f.Buildpacks == nil {
+		return nil, fmt.Errorf("fixture has no buildpacks")
+	}
+	return append([]string(nil), f.Buildpacks...), nil
+}
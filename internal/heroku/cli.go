@@ -0,0 +1,170 @@
+package heroku
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+// CLISource reads app data by shelling out to the Heroku CLI
+type CLISource struct {
+	appName string
+}
+
+// NewCLISource creates a source backed by the Heroku CLI
+func NewCLISource(appName string) *CLISource {
+	return &CLISource{appName: appName}
+}
+
+// AppName returns the app name
+func (c *CLISource) AppName() string {
+	return c.appName
+}
+
+// GetEnvVars retrieves all environment variables for the app
+func (c *CLISource) GetEnvVars() ([]config.HerokuEnvVar, error) {
+	cmd := exec.Command("heroku", "config", "-a", c.appName, "--json")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config vars via CLI: %w", err)
+	}
+
+	var vars map[string]string
+	if err := json.Unmarshal(output, &vars); err != nil {
+		return nil, fmt.Errorf("failed to parse config vars: %w", err)
+	}
+
+	result := make([]config.HerokuEnvVar, 0, len(vars))
+	for name, value := range vars {
+		result = append(result, config.HerokuEnvVar{
+			Name:  name,
+			Value: value,
+		})
+	}
+
+	return result, nil
+}
+
+// GetDynos retrieves the configured dyno formation for the app
+// The formation, including process types scaled to zero, is read from the Platform API
+// with the CLI's token since the CLI only prints it as text
+func (c *CLISource) GetDynos() ([]config.DynoFormation, error) {
+	api, err := c.apiSource()
+	if err != nil {
+		return nil, err
+	}
+	return api.GetDynos()
+}
+
+// apiSource returns a Platform API source for the app authenticated with the CLI's token
+func (c *CLISource) apiSource() (*APISource, error) {
+	output, err := exec.Command("heroku", "auth:token").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Heroku CLI token: %w", err)
+	}
+	return NewAPISource(c.appName, strings.TrimSpace(string(output))), nil
+}
+
+// GetProcesses retrieves the currently running dynos, including one-off dynos
+func (c *CLISource) GetProcesses() ([]config.Dyno, error) {
+	cmd := exec.Command("heroku", "ps", "-a", c.appName, "--json")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dyno info via CLI: %w", err)
+	}
+
+	var dynos []config.Dyno
+	if err := json.Unmarshal(output, &dynos); err != nil {
+		return nil, fmt.Errorf("failed to parse dyno info: %w", err)
+	}
+
+	return dynos, nil
+}
+
+// SetEnvVar sets an environment variable on Heroku
+func (c *CLISource) SetEnvVar(name, value string) error {
+	configStr := fmt.Sprintf("%s=%s", name, value)
+	cmd := exec.Command("heroku", "config:set", configStr, "-a", c.appName)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to set config var %s: %w\nOutput: %s", name, err, string(output))
+	}
+	return nil
+}
+
+// UnsetEnvVar removes an environment variable from Heroku
+func (c *CLISource) UnsetEnvVar(name string) error {
+	cmd := exec.Command("heroku", "config:unset", name, "-a", c.appName)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to unset config var %s: %w\nOutput: %s", name, err, string(output))
+	}
+	return nil
+}
+
+// TestConnection tests if we can connect to Heroku
+func (c *CLISource) TestConnection() error {
+	cmd := exec.Command("heroku", "apps:info", "-a", c.appName)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to connect to Heroku app %s: %w", c.appName, err)
+	}
+	return nil
+}
+
+// GetAppInfo retrieves basic app information
+func (c *CLISource) GetAppInfo() (*AppInfo, error) {
+	cmd := exec.Command("heroku", "apps:info", "-a", c.appName, "--json")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get app info via CLI: %w", err)
+	}
+
+	var info struct {
+		App struct {
+			Name   string `json:"name"`
+			Region struct {
+				Name string `json:"name"`
+			} `json:"region"`
+			Stack struct {
+				Name string `json:"name"`
+			} `json:"stack"`
+		} `json:"app"`
+	}
+
+	if err := json.Unmarshal(output, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse app info: %w", err)
+	}
+
+	return &AppInfo{
+		Name:   info.App.Name,
+		Region: info.App.Region.Name,
+		Stack:  info.App.Stack.Name,
+	}, nil
+}
+
+// infoFieldRegex matches "Name:   value" lines in pg:info and redis:info output
+var infoFieldRegex = regexp.MustCompile(`^([A-Za-z][A-Za-z -]*):\s+(.*)$`)
+
+// parseInfoFields reads the fields of the first section in `heroku pg:info` or `heroku redis:info` output
+func parseInfoFields(output string) map[string]string {
+	fields := map[string]string{}
+	sections := 0
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "===") {
+			sections++
+			if sections > 1 {
+				break
+			}
+			continue
+		}
+		if matches := infoFieldRegex.FindStringSubmatch(line); matches != nil {
+			fields[matches[1]] = strings.TrimSpace(matches[2])
+		}
+	}
+	return fields
+}
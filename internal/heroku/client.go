@@ -1,254 +1,108 @@
 package heroku
 
 import (
-	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 
 	"github.com/leaharmstrong/heroku-calc/internal/config"
 )
 
-// Client represents a Heroku API/CLI client
-type Client struct {
-	appName    string
-	useCLI     bool
-	apiToken   string
-	apiBaseURL string
-}
-
-// NewClient creates a new Heroku client
-// It will attempt to use the Heroku CLI by default, falling back to API if needed
-func NewClient(appName string) (*Client, error) {
-	client := &Client{
-		appName:    appName,
-		useCLI:     true,
-		apiBaseURL: "https://api.heroku.com",
-	}
-
-	// Check if Heroku CLI is available
-	if err := exec.Command("heroku", "version").Run(); err != nil {
-		// CLI not available, we'll need API token
-		client.useCLI = false
-	}
-
-	return client, nil
-}
+// Source is a backend that provides Heroku app data and applies config changes
+type Source interface {
+	// AppName returns the name of the app this source reads from
+	AppName() string
 
-// SetAPIToken sets the API token for direct API calls
-func (c *Client) SetAPIToken(token string) {
-	c.apiToken = token
-	if token != "" {
-		c.useCLI = false
-	}
-}
+	// TestConnection checks that the app is reachable through this source
+	TestConnection() error
 
-// IsUsingCLI returns true if the client is configured to use the Heroku CLI
-func (c *Client) IsUsingCLI() bool {
-	return c.useCLI
-}
+	// GetAppInfo retrieves basic app information
+	GetAppInfo() (*AppInfo, error)
 
-// GetEnvVars retrieves all environment variables for the app
-func (c *Client) GetEnvVars() ([]config.HerokuEnvVar, error) {
-	if c.useCLI {
-		return c.getEnvVarsCLI()
-	}
-	return c.getEnvVarsAPI()
-}
-
-func (c *Client) getEnvVarsCLI() ([]config.HerokuEnvVar, error) {
-	cmd := exec.Command("heroku", "config", "-a", c.appName, "--json")
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get config vars via CLI: %w", err)
-	}
+	// GetEnvVars retrieves all environment variables for the app
+	GetEnvVars() ([]config.HerokuEnvVar, error)
 
-	var vars map[string]string
-	if err := json.Unmarshal(output, &vars); err != nil {
-		return nil, fmt.Errorf("failed to parse config vars: %w", err)
-	}
+	// GetDynos retrieves the configured dyno formation for the app
+	GetDynos() ([]config.DynoFormation, error)
 
-	result := make([]config.HerokuEnvVar, 0, len(vars))
-	for name, value := range vars {
-		result = append(result, config.HerokuEnvVar{
-			Name:  name,
-			Value: value,
-		})
-	}
+	// GetProcesses retrieves the currently running dynos, including one-off dynos
+	GetProcesses() ([]config.Dyno, error)
 
-	return result, nil
-}
+	// GetAddons retrieves all addons for the app
+	GetAddons() ([]config.Addon, error)
 
-func (c *Client) getEnvVarsAPI() ([]config.HerokuEnvVar, error) {
-	// TODO: Implement direct API call
-	// This would use the Heroku Platform API with the token
-	return nil, fmt.Errorf("API mode not yet implemented - please install Heroku CLI")
-}
+	// SetEnvVar sets an environment variable on the app
+	SetEnvVar(name, value string) error
 
-// GetDynos retrieves dyno formation for the app
-func (c *Client) GetDynos() ([]config.DynoFormation, error) {
-	if c.useCLI {
-		return c.getDynosCLI()
-	}
-	return c.getDynosAPI()
+	// UnsetEnvVar removes an environment variable from the app
+	UnsetEnvVar(name string) error
 }
 
-func (c *Client) getDynosCLI() ([]config.DynoFormation, error) {
-	cmd := exec.Command("heroku", "ps", "-a", c.appName, "--json")
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get dyno info via CLI: %w", err)
-	}
-
-	var dynos []struct {
-		Type string `json:"type"`
-		Size string `json:"size"`
-	}
-	if err := json.Unmarshal(output, &dynos); err != nil {
-		return nil, fmt.Errorf("failed to parse dyno info: %w", err)
-	}
-
-	// Count dynos by type
-	formationMap := make(map[string]*config.DynoFormation)
-	for _, dyno := range dynos {
-		key := dyno.Type
-		if formation, exists := formationMap[key]; exists {
-			formation.Quantity++
-		} else {
-			formationMap[key] = &config.DynoFormation{
-				Type:     dyno.Type,
-				Quantity: 1,
-				Size:     dyno.Size,
-			}
-		}
-	}
-
-	result := make([]config.DynoFormation, 0, len(formationMap))
-	for _, formation := range formationMap {
-		result = append(result, *formation)
-	}
-
-	return result, nil
-}
-
-func (c *Client) getDynosAPI() ([]config.DynoFormation, error) {
-	// TODO: Implement direct API call
-	return nil, fmt.Errorf("API mode not yet implemented - please install Heroku CLI")
-}
-
-// SetEnvVar sets an environment variable on Heroku
-func (c *Client) SetEnvVar(name, value string) error {
-	if c.useCLI {
-		return c.setEnvVarCLI(name, value)
-	}
-	return c.setEnvVarAPI(name, value)
-}
+// Compile-time checks that each backend implements Source
+var (
+	_ Source = (*CLISource)(nil)
+	_ Source = (*APISource)(nil)
+	_ Source = (*FixtureSource)(nil)
+)
 
-func (c *Client) setEnvVarCLI(name, value string) error {
-	configStr := fmt.Sprintf("%s=%s", name, value)
-	cmd := exec.Command("heroku", "config:set", configStr, "-a", c.appName)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to set config var %s: %w\nOutput: %s", name, err, string(output))
+// NewSource creates the default source for an app
+// It uses the Heroku CLI when available, falling back to the Platform API with HEROKU_API_KEY
+func NewSource(appName string) (Source, error) {
+	if CLIAvailable() {
+		return NewCLISource(appName), nil
 	}
-	return nil
-}
 
-func (c *Client) setEnvVarAPI(name, value string) error {
-	// TODO: Implement direct API call
-	return fmt.Errorf("API mode not yet implemented - please install Heroku CLI")
-}
-
-// UnsetEnvVar removes an environment variable from Heroku
-func (c *Client) UnsetEnvVar(name string) error {
-	if c.useCLI {
-		return c.unsetEnvVarCLI(name)
+	token := os.Getenv("HEROKU_API_KEY")
+	if token == "" {
+		return nil, fmt.Errorf("Heroku CLI not found and HEROKU_API_KEY is not set")
 	}
-	return c.unsetEnvVarAPI(name)
-}
 
-func (c *Client) unsetEnvVarCLI(name string) error {
-	cmd := exec.Command("heroku", "config:unset", name, "-a", c.appName)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to unset config var %s: %w\nOutput: %s", name, err, string(output))
-	}
-	return nil
+	return NewAPISource(appName, token), nil
 }
 
-func (c *Client) unsetEnvVarAPI(name string) error {
-	// TODO: Implement direct API call
-	return fmt.Errorf("API mode not yet implemented - please install Heroku CLI")
+// CLIAvailable returns true if the Heroku CLI is installed
+func CLIAvailable() bool {
+	return exec.Command("heroku", "version").Run() == nil
 }
 
-// TestConnection tests if we can connect to Heroku
-func (c *Client) TestConnection() error {
-	if c.useCLI {
-		cmd := exec.Command("heroku", "apps:info", "-a", c.appName)
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("failed to connect to Heroku app %s: %w", c.appName, err)
+// AttachStates records the state of running processes on each formation entry
+func AttachStates(formation []config.DynoFormation, processes []config.Dyno) []config.DynoFormation {
+	result := make([]config.DynoFormation, len(formation))
+	for i, f := range formation {
+		f.States = make(map[string]int)
+		for _, p := range processes {
+			if p.Type == f.Type && !p.IsOneOff() {
+				f.States[p.State]++
+			}
 		}
-		return nil
+		result[i] = f
 	}
-
-	// TODO: Implement API test
-	return fmt.Errorf("API mode not yet implemented - please install Heroku CLI")
+	return result
 }
 
-// GetAppInfo retrieves basic app information
-func (c *Client) GetAppInfo() (*AppInfo, error) {
-	if c.useCLI {
-		return c.getAppInfoCLI()
-	}
-	return c.getAppInfoAPI()
-}
-
-func (c *Client) getAppInfoCLI() (*AppInfo, error) {
-	cmd := exec.Command("heroku", "apps:info", "-a", c.appName, "--json")
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get app info via CLI: %w", err)
-	}
-
-	var info struct {
-		App struct {
-			Name   string `json:"name"`
-			Region struct {
-				Name string `json:"name"`
-			} `json:"region"`
-			Stack struct {
-				Name string `json:"name"`
-			} `json:"stack"`
-		} `json:"app"`
-	}
-
-	if err := json.Unmarshal(output, &info); err != nil {
-		return nil, fmt.Errorf("failed to parse app info: %w", err)
+// OneOffDynos returns the one-off dynos among processes
+func OneOffDynos(processes []config.Dyno) []config.Dyno {
+	result := []config.Dyno{}
+	for _, p := range processes {
+		if p.IsOneOff() {
+			result = append(result, p)
+		}
 	}
-
-	return &AppInfo{
-		Name:   info.App.Name,
-		Region: info.App.Region.Name,
-		Stack:  info.App.Stack.Name,
-	}, nil
-}
-
-func (c *Client) getAppInfoAPI() (*AppInfo, error) {
-	// TODO: Implement direct API call
-	return nil, fmt.Errorf("API mode not yet implemented - please install Heroku CLI")
+	return result
 }
 
 // AppInfo contains basic Heroku app information
 type AppInfo struct {
-	Name   string
-	Region string
-	Stack  string
+	Name   string `json:"name"`
+	Region string `json:"region"`
+	Stack  string `json:"stack"`
 }
 
 // SanitizeEnvVarValue masks sensitive parts of environment variable values
 func SanitizeEnvVarValue(name, value string) string {
-	// For URLs, show only the protocol and host
-	if strings.HasPrefix(value, "postgres://") || strings.HasPrefix(value, "redis://") || strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://") {
+	// For URLs (postgres://, rediss://, amqp://, ...), show only the protocol and host
+	if strings.Contains(value, "://") {
 		parts := strings.SplitN(value, "@", 2)
 		if len(parts) == 2 {
 			protocol := strings.Split(parts[0], "://")[0]
@@ -0,0 +1,222 @@
+package heroku
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+// datastoreKind describes how a kind of datastore is recognised
+type datastoreKind struct {
+	kind       string
+	services   []datastoreService // Add-on services providing it
+	schemes    []string           // URL schemes of config vars pointing at it
+	primaryVar string             // Config var the app connects to by default
+}
+
+// datastoreService is an add-on service providing a datastore
+type datastoreService struct {
+	name      string
+	configVar string // Prefix of the config vars a marketplace provider sets ("" for Heroku's own)
+}
+
+// datastoreKinds are the datastores the analysis models
+var datastoreKinds = []datastoreKind{
+	{
+		kind:       config.DatastorePostgres,
+		services:   []datastoreService{{name: "heroku-postgresql"}},
+		schemes:    []string{"postgres", "postgresql"},
+		primaryVar: "DATABASE_URL",
+	},
+	{
+		kind: config.DatastoreRedis,
+		services: []datastoreService{
+			{name: "heroku-redis"},
+			{name: "rediscloud", configVar: "REDISCLOUD_URL"},
+			{name: "upstash-redis", configVar: "UPSTASH_REDIS_URL"},
+			{name: "memetria-redis", configVar: "MEMETRIA_REDIS_URL"},
+			{name: "ah-redis-stackhero", configVar: "STACKHERO_REDIS_URL_TLS"},
+		},
+		schemes:    []string{"redis", "rediss"},
+		primaryVar: "REDIS_URL",
+	},
+}
+
+// findDatastoreKind returns the description of a datastore kind
+func findDatastoreKind(kind string) (datastoreKind, bool) {
+	for _, spec := range datastoreKinds {
+		if spec.kind == kind {
+			return spec, true
+		}
+	}
+	return datastoreKind{}, false
+}
+
+// PrimaryConfigVar returns the config var the app connects to a kind of datastore through by default
+func PrimaryConfigVar(kind string) string {
+	spec, _ := findDatastoreKind(kind)
+	return spec.primaryVar
+}
+
+// FindDatastores maps the app's data add-ons and URL config vars to the datastores they point at
+// Each attached add-on is one datastore carrying the config vars of its attachments; other config vars
+// whose URL has the same host join it, and URLs matching no add-on are external datastores
+// Datastores are ordered with the one behind the primary config var first
+func FindDatastores(kind string, addons []config.Addon, envVars []config.HerokuEnvVar) []config.Datastore {
+	spec, ok := findDatastoreKind(kind)
+	if !ok {
+		return nil
+	}
+
+	values := make(map[string]string, len(envVars))
+	for _, ev := range envVars {
+		values[ev.Name] = ev.Value
+	}
+
+	// Add-ons also set config vars that aren't URLs (hosts, passwords, REST tokens); only the
+	// URLs of this kind set on the app can be connected through
+	var stores []config.Datastore
+	attached := false
+	for _, addon := range addons {
+		if !addonProvides(addon, spec) {
+			continue
+		}
+		attached = attached || len(addon.ConfigVars) > 0
+		store := config.Datastore{
+			Kind:     kind,
+			Addon:    addon.Name,
+			Service:  addon.ServiceName(),
+			Plan:     addon.PlanName(),
+			OwnerApp: addon.OwnerApp,
+		}
+		for _, name := range addon.ConfigVars {
+			if urlHost(values[name], spec.schemes) != "" {
+				store.ConfigVars = append(store.ConfigVars, name)
+			}
+		}
+		stores = append(stores, store)
+	}
+
+	// Snapshots from before attachments were recorded: marketplace add-ons own the config vars their
+	// provider sets, and the first other add-on backs the primary config var
+	if !attached {
+		primaryAssigned := false
+		for i := range stores {
+			if prefix := spec.providerConfigVar(stores[i].Service); prefix != "" {
+				stores[i].ConfigVars = namesWithPrefix(values, prefix)
+				continue
+			}
+			if _, ok := values[spec.primaryVar]; ok && !primaryAssigned {
+				stores[i].ConfigVars = []string{spec.primaryVar}
+				primaryAssigned = true
+			}
+		}
+	}
+
+	// Add-ons without an attachment on this app can't be connected to
+	found := stores
+	stores = stores[:0]
+	for _, store := range found {
+		if len(store.ConfigVars) > 0 {
+			stores = append(stores, store)
+		}
+	}
+
+	// Hosts of the config vars already mapped to an add-on
+	hosts := make(map[string]int)
+	claimed := make(map[string]bool)
+	for i, store := range stores {
+		for _, name := range store.ConfigVars {
+			claimed[name] = true
+			if host := urlHost(values[name], spec.schemes); host != "" {
+				hosts[host] = i
+			}
+		}
+	}
+
+	// Other config vars holding a URL of this kind, e.g. a DATABASE_URL copied from a follower
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		host := urlHost(values[name], spec.schemes)
+		if claimed[name] || host == "" {
+			continue
+		}
+		if i, ok := hosts[host]; ok {
+			stores[i].ConfigVars = append(stores[i].ConfigVars, name)
+			continue
+		}
+		hosts[host] = len(stores)
+		stores = append(stores, config.Datastore{Kind: kind, Plan: "unknown", ConfigVars: []string{name}})
+	}
+
+	// Primary config var first, within each datastore and across them
+	for i := range stores {
+		sort.SliceStable(stores[i].ConfigVars, func(a, b int) bool {
+			return stores[i].ConfigVars[a] == spec.primaryVar && stores[i].ConfigVars[b] != spec.primaryVar
+		})
+	}
+	sort.SliceStable(stores, func(a, b int) bool {
+		return stores[a].HasConfigVar(spec.primaryVar) && !stores[b].HasConfigVar(spec.primaryVar)
+	})
+
+	return stores
+}
+
+// providerConfigVar returns the config var prefix a marketplace add-on service sets, or ""
+func (spec datastoreKind) providerConfigVar(service string) string {
+	for _, known := range spec.services {
+		if known.name == service {
+			return known.configVar
+		}
+	}
+	return ""
+}
+
+// addonProvides returns true if the add-on is a datastore of the kind
+// Add-ons of unknown services are recognised by name or plan
+func addonProvides(addon config.Addon, spec datastoreKind) bool {
+	service := addon.ServiceName()
+	for _, known := range spec.services {
+		if service == known.name {
+			return true
+		}
+	}
+	if addon.Service != "" {
+		return false
+	}
+	return strings.Contains(strings.ToLower(addon.Plan), spec.kind) || strings.Contains(strings.ToLower(addon.Name), spec.kind)
+}
+
+// namesWithPrefix returns the sorted names of the config vars starting with prefix
+func namesWithPrefix(values map[string]string, prefix string) []string {
+	names := []string{}
+	for name := range values {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// urlHost returns the host name of a URL with one of the schemes, or "" for other values
+// The port is ignored so TLS and plain URLs of one instance match; sanitized snapshot
+// values keep the scheme and host, so datastores map the same way when replayed
+func urlHost(value string, schemes []string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	for _, scheme := range schemes {
+		if u.Scheme == scheme {
+			return u.Hostname()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,132 @@
+package heroku
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+func TestFindDatastores(t *testing.T) {
+	env := func(pairs ...string) []config.HerokuEnvVar {
+		vars := []config.HerokuEnvVar{}
+		for i := 0; i+1 < len(pairs); i += 2 {
+			vars = append(vars, config.HerokuEnvVar{Name: pairs[i], Value: pairs[i+1]})
+		}
+		return vars
+	}
+
+	// store summarises a datastore as "addon plan [config vars]"
+	type store struct {
+		Addon      string
+		Plan       string
+		ConfigVars []string
+	}
+
+	tests := []struct {
+		name   string
+		kind   string
+		addons []config.Addon
+		env    []config.HerokuEnvVar
+		want   []store
+	}{
+		{
+			name: "attachments with follower URL copied",
+			kind: config.DatastorePostgres,
+			addons: []config.Addon{
+				{Name: "pg-follower", Plan: "heroku-postgresql:standard-2", Service: "heroku-postgresql", ConfigVars: []string{"HEROKU_POSTGRESQL_CHARCOAL_URL"}},
+				{Name: "pg-main", Plan: "heroku-postgresql:standard-0", Service: "heroku-postgresql", ConfigVars: []string{"HEROKU_POSTGRESQL_ROSE_URL", "DATABASE_URL"}},
+			},
+			env: env(
+				"DATABASE_URL", "postgres://u:p@ec2-a.compute.amazonaws.com:5432/d",
+				"HEROKU_POSTGRESQL_ROSE_URL", "postgres://u:p@ec2-a.compute.amazonaws.com:5432/d",
+				"HEROKU_POSTGRESQL_CHARCOAL_URL", "postgres://u:p@ec2-b.compute.amazonaws.com:5432/d",
+				"READ_REPLICA_URL", "postgres://u:p@ec2-b.compute.amazonaws.com:5432/d",
+				"LEGACY_PG_URL", "postgresql://u:p@db.example.com/x",
+			),
+			want: []store{
+				{Addon: "pg-main", Plan: "standard-0", ConfigVars: []string{"DATABASE_URL", "HEROKU_POSTGRESQL_ROSE_URL"}},
+				{Addon: "pg-follower", Plan: "standard-2", ConfigVars: []string{"HEROKU_POSTGRESQL_CHARCOAL_URL", "READ_REPLICA_URL"}},
+				{Plan: "unknown", ConfigVars: []string{"LEGACY_PG_URL"}},
+			},
+		},
+		{
+			name: "non-URL and unset config vars are dropped",
+			kind: config.DatastoreRedis,
+			addons: []config.Addon{
+				{Name: "stackhero", Plan: "ah-redis-stackhero:ecalli", Service: "ah-redis-stackhero",
+					ConfigVars: []string{"STACKHERO_REDIS_HOST", "STACKHERO_REDIS_PASSWORD", "STACKHERO_REDIS_URL_TLS", "STACKHERO_REDIS_URL_CLEAR"}},
+				{Name: "upstash", Plan: "upstash-redis:free", Service: "upstash-redis",
+					ConfigVars: []string{"UPSTASH_REDIS_URL", "UPSTASH_REDIS_REST_URL", "UPSTASH_REDIS_REST_TOKEN", "UPSTASH_REDIS_UNSET_URL"}},
+			},
+			env: env(
+				"STACKHERO_REDIS_HOST", "abc.stackhero-network.com",
+				"STACKHERO_REDIS_PASSWORD", "secret",
+				"STACKHERO_REDIS_URL_TLS", "rediss://:p@abc.stackhero-network.com:6380",
+				"STACKHERO_REDIS_URL_CLEAR", "redis://:p@abc.stackhero-network.com:6379",
+				"UPSTASH_REDIS_URL", "rediss://default:p@xyz.upstash.io:6379",
+				"UPSTASH_REDIS_REST_URL", "https://xyz.upstash.io",
+				"UPSTASH_REDIS_REST_TOKEN", "token",
+			),
+			want: []store{
+				{Addon: "stackhero", Plan: "ecalli", ConfigVars: []string{"STACKHERO_REDIS_URL_TLS", "STACKHERO_REDIS_URL_CLEAR"}},
+				{Addon: "upstash", Plan: "free", ConfigVars: []string{"UPSTASH_REDIS_URL"}},
+			},
+		},
+		{
+			name: "attached from another app without config vars",
+			kind: config.DatastoreRedis,
+			addons: []config.Addon{
+				{Name: "redis-main", Plan: "heroku-redis:premium-0", Service: "heroku-redis", ConfigVars: []string{"REDIS_URL"}},
+				{Name: "redis-elsewhere", Plan: "heroku-redis:mini", Service: "heroku-redis", OwnerApp: "other-app"},
+			},
+			env: env("REDIS_URL", "rediss://:p@ec2-r1.compute.amazonaws.com:6380"),
+			want: []store{
+				{Addon: "redis-main", Plan: "premium-0", ConfigVars: []string{"REDIS_URL"}},
+			},
+		},
+		{
+			name: "no attachments recorded",
+			kind: config.DatastoreRedis,
+			addons: []config.Addon{
+				{Name: "rediscloud", Plan: "rediscloud:30", Service: "rediscloud"},
+				{Name: "redis-main", Plan: "heroku-redis:premium-0", Service: "heroku-redis"},
+			},
+			env: env(
+				"REDIS_URL", "rediss://:p@ec2-r1.compute.amazonaws.com:6380",
+				"REDISCLOUD_URL", "redis://default:p@redis-123.c1.us-east-1-2.ec2.cloud.redislabs.com:12345",
+			),
+			want: []store{
+				{Addon: "redis-main", Plan: "premium-0", ConfigVars: []string{"REDIS_URL"}},
+				{Addon: "rediscloud", Plan: "30", ConfigVars: []string{"REDISCLOUD_URL"}},
+			},
+		},
+		{
+			name: "no attachments recorded for Stackhero",
+			kind: config.DatastoreRedis,
+			addons: []config.Addon{
+				{Name: "stackhero", Plan: "ah-redis-stackhero:ecalli", Service: "ah-redis-stackhero"},
+			},
+			env: env(
+				"STACKHERO_REDIS_HOST", "abc.stackhero-network.com",
+				"STACKHERO_REDIS_URL_TLS", "rediss://:p@abc.stackhero-network.com:6380",
+				"STACKHERO_REDIS_URL_CLEAR", "redis://:p@abc.stackhero-network.com:6379",
+			),
+			want: []store{
+				{Addon: "stackhero", Plan: "ecalli", ConfigVars: []string{"STACKHERO_REDIS_URL_TLS", "STACKHERO_REDIS_URL_CLEAR"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []store{}
+			for _, ds := range FindDatastores(tt.kind, tt.addons, tt.env) {
+				got = append(got, store{Addon: ds.Addon, Plan: ds.Plan, ConfigVars: ds.ConfigVars})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindDatastores() =\n  %+v\nwant\n  %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package heroku
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+// FixtureSource is an in-memory source for deterministic data
+// Config changes are applied to the in-memory env vars unless ReadOnly is set
+type FixtureSource struct {
+	App       *AppInfo
+	EnvVars   []config.HerokuEnvVar
+	Dynos     []config.DynoFormation
+	Processes []config.Dyno
+	Addons    []config.Addon
+	Pipeline  *Pipeline
+	ReadOnly  bool
+
+	// Optional live datastore info, returned by the capability interfaces
+	PostgresInfo *config.PostgresInfo // DATABASE_URL
+	RedisInfo    *config.RedisInfo    // REDIS_URL
+
+	// Optional live info for other datastores, by config var
+	PostgresInfoByVar map[string]*config.PostgresInfo
+	RedisInfoByVar    map[string]*config.RedisInfo
+
+	Logs       string   // Platform log lines in `heroku logs` format
+	Buildpacks []string // Buildpack names or URLs (nil if unknown)
+
+	// Optional release history; config vars are keyed by release version
+	Releases          []config.Release
+	ReleaseConfigVars map[int]map[string]string
+
+	mu sync.Mutex
+}
+
+// NewFixtureSource creates an empty fixture for the named app
+func NewFixtureSource(appName string) *FixtureSource {
+	return &FixtureSource{
+		App: &AppInfo{Name: appName},
+	}
+}
+
+// AppName returns the app name
+func (f *FixtureSource) AppName() string {
+	if f.App == nil {
+		return ""
+	}
+	return f.App.Name
+}
+
+// TestConnection always succeeds for fixtures
+func (f *FixtureSource) TestConnection() error {
+	return nil
+}
+
+// GetAppInfo returns the fixture's app info
+func (f *FixtureSource) GetAppInfo() (*AppInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.App == nil {
+		return nil, fmt.Errorf("fixture has no app info")
+	}
+	info := *f.App
+	return &info, nil
+}
+
+// GetEnvVars returns a copy of the fixture's env vars
+func (f *FixtureSource) GetEnvVars() ([]config.HerokuEnvVar, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]config.HerokuEnvVar(nil), f.EnvVars...), nil
+}
+
+// GetDynos returns a copy of the fixture's dyno formation
+func (f *FixtureSource) GetDynos() ([]config.DynoFormation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]config.DynoFormation(nil), f.Dynos...), nil
+}
+
+// GetProcesses returns a copy of the fixture's running dynos
+func (f *FixtureSource) GetProcesses() ([]config.Dyno, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]config.Dyno(nil), f.Processes...), nil
+}
+
+// GetAddons returns a copy of the fixture's addons
+func (f *FixtureSource) GetAddons() ([]config.Addon, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]config.Addon(nil), f.Addons...), nil
+}
+
+// SetEnvVar sets an env var in memory
+func (f *FixtureSource) SetEnvVar(name, value string) error {
+	if f.ReadOnly {
+		return fmt.Errorf("cannot set %s: source is read-only", name)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, ev := range f.EnvVars {
+		if ev.Name == name {
+			f.EnvVars[i].Value = value
+			return nil
+		}
+	}
+	f.EnvVars = append(f.EnvVars, config.HerokuEnvVar{Name: name, Value: value})
+	return nil
+}
+
+// UnsetEnvVar removes an env var from memory
+func (f *FixtureSource) UnsetEnvVar(name string) error {
+	if f.ReadOnly {
+		return fmt.Errorf("cannot unset %s: source is read-only", name)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	filtered := f.EnvVars[:0]
+	for _, ev := range f.EnvVars {
+		if ev.Name != name {
+			filtered = append(filtered, ev)
+		}
+	}
+	f.EnvVars = filtered
+	return nil
+}
@@ -0,0 +1,48 @@
+package heroku
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+func TestFixtureSourceConcurrentAccess(t *testing.T) {
+	fixture := NewFixtureSource("demo")
+	fixture.Dynos = []config.DynoFormation{{Type: "web", Quantity: 1, Size: "Standard-1X"}}
+	fixture.Addons = []config.Addon{{Name: "pg", Plan: "heroku-postgresql:standard-0"}}
+
+	// Run with -race: readers share the fixture with recommendations being applied
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				_ = fixture.SetEnvVar(fmt.Sprintf("VAR_%d", j%5), fmt.Sprint(i))
+				_ = fixture.UnsetEnvVar(fmt.Sprintf("VAR_%d", (j+1)%5))
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if _, err := fixture.GetAppInfo(); err != nil {
+					t.Error(err)
+				}
+				_, _ = fixture.GetEnvVars()
+				_, _ = fixture.GetDynos()
+				_, _ = fixture.GetProcesses()
+				_, _ = fixture.GetAddons()
+				_, _ = fixture.GetPostgresInfo("DATABASE_URL")
+				_, _ = fixture.GetRedisInfo("REDIS_URL")
+			}
+		}()
+	}
+	wg.Wait()
+
+	fixture.ReadOnly = true
+	if err := fixture.SetEnvVar("VAR_0", "x"); err == nil {
+		t.Error("SetEnvVar on a read-only fixture succeeded")
+	}
+}
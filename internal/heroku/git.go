@@ -4,35 +4,81 @@ import (
 	"fmt"
 	"os/exec"
 	"regexp"
+	"sort"
 	"strings"
 )
 
-// DetectHerokuApp attempts to detect the Heroku app name from git remotes
-func DetectHerokuApp(projectPath string) (appName string, remoteName string, err error) {
-	// Get git remotes
-	cmd := exec.Command("git", "-C", projectPath, "remote", "-v")
-	output, err := cmd.Output()
+// HerokuRemote is a git remote that deploys to a Heroku app
+type HerokuRemote struct {
+	Name    string
+	AppName string
+}
+
+// herokuRemoteRegex matches Heroku git URLs and captures the app name
+// Formats: https://git.heroku.com/appname.git, git@heroku.com:appname.git, ssh://git@heroku.com/appname.git
+var herokuRemoteRegex = regexp.MustCompile(`^(?:https://git\.heroku\.com/|git@heroku\.com:|ssh://git@heroku\.com/)([^./\s]+)(?:\.git)?$`)
+
+// DetectHerokuRemotes returns every git remote pointing at a Heroku app, sorted by remote name
+func DetectHerokuRemotes(projectPath string) ([]HerokuRemote, error) {
+	remotes, err := GetGitRemotes(projectPath)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to get git remotes: %w (is this a git repository?)", err)
+		return nil, fmt.Errorf("%w (is this a git repository?)", err)
 	}
 
-	// Parse remotes looking for heroku.com URLs
-	// Format: remotename	https://git.heroku.com/appname.git (fetch)
-	// or:    remotename	git@heroku.com:appname.git (fetch)
-	lines := strings.Split(string(output), "\n")
+	result := []HerokuRemote{}
+	for name, url := range remotes {
+		if matches := herokuRemoteRegex.FindStringSubmatch(url); matches != nil {
+			result = append(result, HerokuRemote{Name: name, AppName: matches[1]})
+		}
+	}
 
-	herokuRegex := regexp.MustCompile(`^(\S+)\s+(?:https://git\.heroku\.com/|git@heroku\.com:)([^.\s]+)(?:\.git)?\s+\(fetch\)`)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
 
-	for _, line := range lines {
-		matches := herokuRegex.FindStringSubmatch(line)
-		if len(matches) == 3 {
-			remoteName = matches[1]
-			appName = matches[2]
-			return appName, remoteName, nil
+	return result, nil
+}
+
+// SelectRemote picks a remote without asking: the first preferred name that exists,
+// otherwise the only remote. It returns nil when the choice is ambiguous
+func SelectRemote(remotes []HerokuRemote, preferred ...string) *HerokuRemote {
+	for _, name := range preferred {
+		if name == "" {
+			continue
 		}
+		for i := range remotes {
+			if remotes[i].Name == name {
+				return &remotes[i]
+			}
+		}
+	}
+
+	if len(remotes) == 1 {
+		return &remotes[0]
 	}
+	return nil
+}
 
-	return "", "", fmt.Errorf("no Heroku git remote found")
+// DetectHerokuApp detects the Heroku app from git remotes
+// It fails rather than guessing when there are several Heroku remotes
+func DetectHerokuApp(projectPath string) (appName string, remoteName string, err error) {
+	remotes, err := DetectHerokuRemotes(projectPath)
+	if err != nil {
+		return "", "", err
+	}
+
+	switch len(remotes) {
+	case 0:
+		return "", "", fmt.Errorf("no Heroku git remote found")
+	case 1:
+		return remotes[0].AppName, remotes[0].Name, nil
+	}
+
+	names := make([]string, len(remotes))
+	for i, remote := range remotes {
+		names[i] = remote.Name
+	}
+	return "", "", fmt.Errorf("multiple Heroku git remotes found (%s); choose one with --remote", strings.Join(names, ", "))
 }
 
 // GetGitRemotes returns all git remotes for the project
@@ -0,0 +1,67 @@
+package heroku
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strconv"
+)
+
+// DefaultLogLines is the number of log lines fetched for analysis (the most Heroku returns)
+const DefaultLogLines = 1500
+
+// LogSource is implemented by sources that can read recent platform logs
+type LogSource interface {
+	// GetLogs returns up to lines recent platform (source heroku) log lines in `heroku logs` format
+	GetLogs(lines int) (string, error)
+}
+
+// Compile-time checks for the log-capable backends
+var (
+	_ LogSource = (*CLISource)(nil)
+	_ LogSource = (*APISource)(nil)
+	_ LogSource = (*FixtureSource)(nil)
+)
+
+// GetLogs runs `heroku logs --source heroku`
+func (c *CLISource) GetLogs(lines int) (string, error) {
+	output, err := exec.Command("heroku", "logs", "-a", c.appName, "--source", "heroku", "-n", strconv.Itoa(lines)).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get logs via CLI: %w", err)
+	}
+	return string(output), nil
+}
+
+// GetLogs creates a log session and reads it from Logplex
+func (c *APISource) GetLogs(lines int) (string, error) {
+	var session struct {
+		LogplexURL string `json:"logplex_url"`
+	}
+	body := map[string]interface{}{"lines": lines, "source": "heroku"}
+	if err := c.doAPIRequest(http.MethodPost, c.appPath("/log-sessions"), body, &session); err != nil {
+		return "", fmt.Errorf("failed to create log session via API: %w", err)
+	}
+
+	// The session URL carries its own credentials
+	resp, err := c.httpClient.Get(session.LogplexURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to read log session: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("failed to read log session: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read log session: %w", err)
+	}
+	return string(data), nil
+}
+
+// GetLogs returns the fixture's log output
+func (f *FixtureSource) GetLogs(lines int) (string, error) {
+	return f.Logs, nil
+}
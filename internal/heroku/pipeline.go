@@ -0,0 +1,123 @@
+package heroku
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+// Pipeline is a Heroku pipeline and the apps coupled to it
+type Pipeline struct {
+	ID   string        `json:"id"`
+	Name string        `json:"name"`
+	Apps []PipelineApp `json:"apps"`
+}
+
+// PipelineApp is an app coupled to a pipeline stage
+type PipelineApp struct {
+	Name  string `json:"name"`
+	Stage string `json:"stage"` // "review", "development", "staging" or "production"
+}
+
+// PipelineSource is implemented by sources that can discover the app's pipeline
+type PipelineSource interface {
+	// GetPipeline returns the pipeline the app is coupled to, with apps ordered by stage
+	GetPipeline() (*Pipeline, error)
+}
+
+// Compile-time checks for the pipeline-capable backends
+var (
+	_ PipelineSource = (*CLISource)(nil)
+	_ PipelineSource = (*APISource)(nil)
+	_ PipelineSource = (*FixtureSource)(nil)
+)
+
+// stageOrder ranks pipeline stages from first to last
+var stageOrder = map[string]int{
+	"review":      0,
+	"development": 1,
+	"staging":     2,
+	"production":  3,
+}
+
+// SortPipelineApps orders apps by pipeline stage, then by name
+func SortPipelineApps(apps []PipelineApp) {
+	sort.SliceStable(apps, func(i, j int) bool {
+		if stageOrder[apps[i].Stage] != stageOrder[apps[j].Stage] {
+			return stageOrder[apps[i].Stage] < stageOrder[apps[j].Stage]
+		}
+		return apps[i].Name < apps[j].Name
+	})
+}
+
+// GetPipeline discovers the app's pipeline through the Platform API,
+// authenticated with the CLI's token since the CLI has no JSON output for couplings
+func (c *CLISource) GetPipeline() (*Pipeline, error) {
+	api, err := c.apiSource()
+	if err != nil {
+		return nil, err
+	}
+	return api.GetPipeline()
+}
+
+// GetPipeline discovers the app's pipeline and the apps in every stage
+func (c *APISource) GetPipeline() (*Pipeline, error) {
+	var coupling struct {
+		Pipeline struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"pipeline"`
+	}
+	if err := c.doAPIRequest(http.MethodGet, c.appPath("/pipeline-couplings"), nil, &coupling); err != nil {
+		return nil, fmt.Errorf("app %s is not in a pipeline: %w", c.appName, err)
+	}
+
+	pipeline := &Pipeline{ID: coupling.Pipeline.ID, Name: coupling.Pipeline.Name}
+	pipelinePath := "/pipelines/" + url.PathEscape(pipeline.ID)
+
+	if pipeline.Name == "" {
+		var info struct {
+			Name string `json:"name"`
+		}
+		if err := c.doAPIRequest(http.MethodGet, pipelinePath, nil, &info); err != nil {
+			return nil, fmt.Errorf("failed to get pipeline via API: %w", err)
+		}
+		pipeline.Name = info.Name
+	}
+
+	var couplings []struct {
+		App struct {
+			ID string `json:"id"`
+		} `json:"app"`
+		Stage string `json:"stage"`
+	}
+	if err := c.doAPIRequest(http.MethodGet, pipelinePath+"/pipeline-couplings", nil, &couplings); err != nil {
+		return nil, fmt.Errorf("failed to get pipeline apps via API: %w", err)
+	}
+
+	// Couplings only reference apps by ID
+	for _, cp := range couplings {
+		var app struct {
+			Name string `json:"name"`
+		}
+		if err := c.doAPIRequest(http.MethodGet, "/apps/"+url.PathEscape(cp.App.ID), nil, &app); err != nil {
+			return nil, fmt.Errorf("failed to get pipeline app via API: %w", err)
+		}
+		pipeline.Apps = append(pipeline.Apps, PipelineApp{Name: app.Name, Stage: cp.Stage})
+	}
+
+	SortPipelineApps(pipeline.Apps)
+	return pipeline, nil
+}
+
+// GetPipeline returns the fixture's pipeline
+func (f *FixtureSource) GetPipeline() (*Pipeline, error) {
+	if f.Pipeline == nil {
+		return nil, fmt.Errorf("app %s is not in a pipeline", f.AppName())
+	}
+	pipeline := *f.Pipeline
+	pipeline.Apps = append([]PipelineApp(nil), f.Pipeline.Apps...)
+	SortPipelineApps(pipeline.Apps)
+	return &pipeline, nil
+}
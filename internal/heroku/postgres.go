@@ -0,0 +1,98 @@
+package heroku
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+// PostgresInfoSource is implemented by sources that can read live database info
+type PostgresInfoSource interface {
+	// GetPostgresInfo returns pg:info for the database a config var points at, e.g. DATABASE_URL
+	GetPostgresInfo(configVar string) (*config.PostgresInfo, error)
+}
+
+// Compile-time checks for the pg:info-capable backends
+var (
+	_ PostgresInfoSource = (*CLISource)(nil)
+	_ PostgresInfoSource = (*APISource)(nil)
+	_ PostgresInfoSource = (*FixtureSource)(nil)
+)
+
+// GetPostgresInfo runs `heroku pg:info` for the config var
+func (c *CLISource) GetPostgresInfo(configVar string) (*config.PostgresInfo, error) {
+	output, err := exec.Command("heroku", "pg:info", configVar, "-a", c.appName).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pg:info via CLI: %w", err)
+	}
+
+	fields := parseInfoFields(string(output))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("failed to parse pg:info output")
+	}
+	return postgresInfoFromFields(fields), nil
+}
+
+// GetPostgresInfo reads pg:info for the config var's database from the Data API
+func (c *APISource) GetPostgresInfo(configVar string) (*config.PostgresInfo, error) {
+	addonID, err := c.findAddonID("heroku-postgresql", configVar)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := c.getDataAPIInfo("/client/v11/databases/" + url.PathEscape(addonID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pg:info via Data API: %w", err)
+	}
+	return postgresInfoFromFields(fields), nil
+}
+
+// GetPostgresInfo returns the fixture's database info for the config var
+func (f *FixtureSource) GetPostgresInfo(configVar string) (*config.PostgresInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	found := f.PostgresInfoByVar[configVar]
+	if found == nil && configVar == "DATABASE_URL" {
+		found = f.PostgresInfo
+	}
+	if found == nil {
+		return nil, fmt.Errorf("fixture has no Postgres info for %s", configVar)
+	}
+	info := *found
+	return &info, nil
+}
+
+// pgConnectionsRegex matches "12/120" or "12" in the Connections field
+var pgConnectionsRegex = regexp.MustCompile(`^(\d+)(?:\s*/\s*(\d+))?`)
+
+// postgresInfoFromFields builds database info from pg:info's named fields,
+// which the CLI and the Data API label the same way
+func postgresInfoFromFields(fields map[string]string) *config.PostgresInfo {
+	info := &config.PostgresInfo{
+		Addon:   fields["Add-on"],
+		Plan:    fields["Plan"],
+		Status:  fields["Status"],
+		Version: fields["PG Version"],
+	}
+
+	if matches := pgConnectionsRegex.FindStringSubmatch(fields["Connections"]); matches != nil {
+		info.Connections, _ = strconv.Atoi(matches[1])
+		info.ConnectionLimit, _ = strconv.Atoi(matches[2])
+	}
+
+	// "Data Size" is "1.2 GB / 64 GB (1.88%)" on plans with a storage limit
+	dataSize := fields["Data Size"]
+	if i := strings.Index(dataSize, "("); i >= 0 {
+		dataSize = dataSize[:i]
+	}
+	size, limit, _ := strings.Cut(dataSize, "/")
+	info.DataSize = strings.TrimSpace(size)
+	info.DataLimit = strings.TrimSpace(limit)
+
+	return info
+}
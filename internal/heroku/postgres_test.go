@@ -0,0 +1,103 @@
+package heroku
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+// pgInfoOutput is trimmed `heroku pg:info DATABASE_URL -a demo` output
+const pgInfoOutput = `=== DATABASE_URL, HEROKU_POSTGRESQL_ROSE_URL
+Plan:                  Standard 0
+Status:                Available
+Data Size:             1.2 GB / 64 GB (1.88%)
+Tables:                42
+PG Version:            16.2
+Connections:           23/120
+Connection Pooling:    Available
+Fork/Follow:           Available
+Rollback:              earliest from 2024-03-01 10:00 UTC
+Created:               2023-01-15 09:30 UTC
+Maintenance:           not required
+Add-on:                postgresql-rigid-12345
+
+=== HEROKU_POSTGRESQL_GOLD_URL
+Plan:                  Essential 0
+Connections:           2/20
+Add-on:                postgresql-shared-67890
+`
+
+func TestPostgresInfoFromFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields map[string]string
+		want   *config.PostgresInfo
+	}{
+		{
+			name:   "pg:info",
+			fields: parseInfoFields(pgInfoOutput),
+			want: &config.PostgresInfo{
+				Addon:           "postgresql-rigid-12345",
+				Plan:            "Standard 0",
+				Status:          "Available",
+				Version:         "16.2",
+				Connections:     23,
+				ConnectionLimit: 120,
+				DataSize:        "1.2 GB",
+				DataLimit:       "64 GB",
+			},
+		},
+		{
+			name: "no limits reported",
+			fields: map[string]string{
+				"Add-on":      "postgresql-cubic-1",
+				"Plan":        "Premium 4",
+				"Connections": "512",
+				"Data Size":   "310 GB",
+			},
+			want: &config.PostgresInfo{
+				Addon:       "postgresql-cubic-1",
+				Plan:        "Premium 4",
+				Connections: 512,
+				DataSize:    "310 GB",
+			},
+		},
+		{
+			name:   "connections not reported",
+			fields: map[string]string{"Plan": "Essential 0", "Connections": "unknown"},
+			want:   &config.PostgresInfo{Plan: "Essential 0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := postgresInfoFromFields(tt.fields); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("postgresInfoFromFields() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPISourceGetPostgresInfo(t *testing.T) {
+	server, _ := newAPIServer(t, map[string]string{
+		"GET /apps/demo/addon-attachments/DATABASE": `{"name": "DATABASE", "addon": {"id": "p1"}}`,
+		"GET /client/v11/databases/p1": `{"info": [
+			{"name": "Plan", "values": ["Standard 0"]},
+			{"name": "PG Version", "values": ["16.2"]},
+			{"name": "Connections", "values": ["23/120"]},
+			{"name": "Data Size", "values": ["1.2 GB / 64 GB (1.88%)"]}
+		]}`,
+	})
+	source := newTestAPISource(server)
+	source.SetDataAPIURL(server.URL)
+
+	info, err := source.GetPostgresInfo("DATABASE_URL")
+	if err != nil {
+		t.Fatalf("GetPostgresInfo: %v", err)
+	}
+	want := &config.PostgresInfo{Plan: "Standard 0", Version: "16.2", Connections: 23, ConnectionLimit: 120, DataSize: "1.2 GB", DataLimit: "64 GB"}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("GetPostgresInfo() = %+v; want %+v", info, want)
+	}
+}
@@ -0,0 +1,90 @@
+package heroku
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"regexp"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+// RedisInfoSource is implemented by sources that can read live Redis info
+type RedisInfoSource interface {
+	// GetRedisInfo returns redis:info for the instance a config var points at, e.g. REDIS_URL
+	GetRedisInfo(configVar string) (*config.RedisInfo, error)
+}
+
+// Compile-time checks for the redis:info-capable backends
+var (
+	_ RedisInfoSource = (*CLISource)(nil)
+	_ RedisInfoSource = (*APISource)(nil)
+	_ RedisInfoSource = (*FixtureSource)(nil)
+)
+
+// redisInfoHeaderRegex matches the "=== redis-shaped-12345 (REDIS_URL)" header of redis:info
+var redisInfoHeaderRegex = regexp.MustCompile(`(?m)^===\s+(\S+)`)
+
+// GetRedisInfo runs `heroku redis:info` for the config var
+func (c *CLISource) GetRedisInfo(configVar string) (*config.RedisInfo, error) {
+	output, err := exec.Command("heroku", "redis:info", configVar, "-a", c.appName).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get redis:info via CLI: %w", err)
+	}
+
+	return parseRedisInfo(string(output))
+}
+
+// parseRedisInfo reads the first instance in `heroku redis:info` output
+func parseRedisInfo(output string) (*config.RedisInfo, error) {
+	fields := parseInfoFields(output)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("failed to parse redis:info output")
+	}
+	info := redisInfoFromFields(fields)
+	if matches := redisInfoHeaderRegex.FindStringSubmatch(output); matches != nil {
+		info.Addon = matches[1]
+	}
+	return info, nil
+}
+
+// GetRedisInfo reads redis:info for the config var's instance from the Data API
+func (c *APISource) GetRedisInfo(configVar string) (*config.RedisInfo, error) {
+	addonID, err := c.findAddonID("heroku-redis", configVar)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := c.getDataAPIInfo("/redis/v0/databases/" + url.PathEscape(addonID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get redis:info via Data API: %w", err)
+	}
+	info := redisInfoFromFields(fields)
+	info.Addon = addonID
+	return info, nil
+}
+
+// GetRedisInfo returns the fixture's Redis info for the config var
+func (f *FixtureSource) GetRedisInfo(configVar string) (*config.RedisInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	found := f.RedisInfoByVar[configVar]
+	if found == nil && configVar == "REDIS_URL" {
+		found = f.RedisInfo
+	}
+	if found == nil {
+		return nil, fmt.Errorf("fixture has no Redis info for %s", configVar)
+	}
+	info := *found
+	return &info, nil
+}
+
+// redisInfoFromFields builds Redis info from redis:info's named fields
+// redis:info labels the eviction policy "Maxmemory"
+func redisInfoFromFields(fields map[string]string) *config.RedisInfo {
+	return &config.RedisInfo{
+		Plan:            fields["Plan"],
+		Version:         fields["Version"],
+		MaxmemoryPolicy: fields["Maxmemory"],
+	}
+}
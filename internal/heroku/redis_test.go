@@ -0,0 +1,90 @@
+package heroku
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+// redisInfoOutput is trimmed `heroku redis:info REDIS_URL -a demo` output
+const redisInfoOutput = `=== redis-cubic-12345 (REDIS_URL)
+Plan:                   Premium 0
+Status:                 available
+Created:                2024-03-01 10:00 UTC
+Version:                7.2.4
+Timeout:                300
+Maxmemory:              noeviction
+Maintenance:            not required
+Persistence:            AOF
+HA Available:           Yes
+Requires TLS:           Yes
+`
+
+func TestParseRedisInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    *config.RedisInfo
+		wantErr bool
+	}{
+		{
+			name:   "redis:info",
+			output: redisInfoOutput,
+			want:   &config.RedisInfo{Addon: "redis-cubic-12345", Plan: "Premium 0", Version: "7.2.4", MaxmemoryPolicy: "noeviction"},
+		},
+		{
+			name:   "eviction policy without a header",
+			output: "Plan:      Mini\nVersion:   6.2.14\nMaxmemory: allkeys-lru\n",
+			want:   &config.RedisInfo{Plan: "Mini", Version: "6.2.14", MaxmemoryPolicy: "allkeys-lru"},
+		},
+		{
+			name:    "no fields",
+			output:  " !    No Redis instances found.\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRedisInfo(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRedisInfo() error = %v; wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRedisInfo() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPISourceGetRedisInfo(t *testing.T) {
+	server, requests := newAPIServer(t, map[string]string{
+		"GET /apps/demo/addon-attachments/REDIS": `{"name": "REDIS", "addon": {"id": "r1"}}`,
+		"GET /redis/v0/databases/r1": `{"info": [
+			{"name": "Plan", "values": ["Premium 0"]},
+			{"name": "Version", "values": ["7.2.4"]},
+			{"name": "Maxmemory", "values": ["noeviction"]}
+		]}`,
+	})
+	source := newTestAPISource(server)
+	source.SetDataAPIURL(server.URL)
+
+	info, err := source.GetRedisInfo("REDIS_URL")
+	if err != nil {
+		t.Fatalf("GetRedisInfo: %v", err)
+	}
+	want := &config.RedisInfo{Addon: "r1", Plan: "Premium 0", Version: "7.2.4", MaxmemoryPolicy: "noeviction"}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("GetRedisInfo() = %+v; want %+v", info, want)
+	}
+
+	// Only the attachment and the Data API are read: no config vars, so no credentials
+	paths := []string{}
+	for _, req := range *requests {
+		paths = append(paths, req.Path)
+	}
+	if want := []string{"/apps/demo/addon-attachments/REDIS", "/redis/v0/databases/r1"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("requested %v; want %v", paths, want)
+	}
+}
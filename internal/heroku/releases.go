@@ -0,0 +1,151 @@
+package heroku
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+// DefaultReleaseLimit is the number of recent releases fetched for analysis
+const DefaultReleaseLimit = 50
+
+// ReleaseSource is implemented by sources that can read the app's release history
+type ReleaseSource interface {
+	// GetReleases returns up to limit releases, newest first
+	GetReleases(limit int) ([]config.Release, error)
+	// GetReleaseConfigVars returns the config vars as they were in a release
+	GetReleaseConfigVars(version int) (map[string]string, error)
+}
+
+// Compile-time checks for the release-capable backends
+var (
+	_ ReleaseSource = (*CLISource)(nil)
+	_ ReleaseSource = (*APISource)(nil)
+	_ ReleaseSource = (*FixtureSource)(nil)
+)
+
+// releaseJSON is a release as returned by the Platform API and `heroku releases --json`
+type releaseJSON struct {
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+	User        struct {
+		Email string `json:"email"`
+	} `json:"user"`
+	CreatedAt time.Time `json:"created_at"`
+	Status    string    `json:"status"`
+	Current   bool      `json:"current"`
+}
+
+// toReleases converts API releases, newest first
+func toReleases(releases []releaseJSON, limit int) []config.Release {
+	result := make([]config.Release, len(releases))
+	for i, release := range releases {
+		result[i] = config.Release{
+			Version:     release.Version,
+			Description: release.Description,
+			User:        release.User.Email,
+			CreatedAt:   release.CreatedAt,
+			Status:      release.Status,
+			Current:     release.Current,
+		}
+	}
+	sortReleases(result)
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// sortReleases orders releases newest first
+func sortReleases(releases []config.Release) {
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].Version > releases[j].Version
+	})
+}
+
+// GetReleases runs `heroku releases --json`
+func (c *CLISource) GetReleases(limit int) ([]config.Release, error) {
+	output, err := exec.Command("heroku", "releases", "-a", c.appName, "--json", "-n", strconv.Itoa(limit)).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get releases via CLI: %w", err)
+	}
+
+	var releases []releaseJSON
+	if err := json.Unmarshal(output, &releases); err != nil {
+		return nil, fmt.Errorf("failed to parse releases: %w", err)
+	}
+	return toReleases(releases, limit), nil
+}
+
+// GetReleaseConfigVars runs `heroku releases:info --shell`, which prints the release's config vars as KEY=value
+func (c *CLISource) GetReleaseConfigVars(version int) (map[string]string, error) {
+	output, err := exec.Command("heroku", "releases:info", "v"+strconv.Itoa(version), "--shell", "-a", c.appName).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get release config vars via CLI: %w", err)
+	}
+	return parseShellConfig(string(output)), nil
+}
+
+// parseShellConfig parses KEY=value lines, unquoting single-quoted values
+func parseShellConfig(output string) map[string]string {
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			continue
+		}
+		if len(value) >= 2 && strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") {
+			value = value[1 : len(value)-1]
+		}
+		vars[name] = value
+	}
+	return vars
+}
+
+// GetReleases lists the newest releases through the Platform API
+func (c *APISource) GetReleases(limit int) ([]config.Release, error) {
+	var releases []releaseJSON
+	rangeHeader := fmt.Sprintf("version ..; order=desc, max=%d", limit)
+	if err := c.doAPIRangeRequest(c.appPath("/releases"), rangeHeader, &releases); err != nil {
+		return nil, fmt.Errorf("failed to get releases via API: %w", err)
+	}
+	return toReleases(releases, limit), nil
+}
+
+// GetReleaseConfigVars reads a release's config vars through the Platform API
+func (c *APISource) GetReleaseConfigVars(version int) (map[string]string, error) {
+	var vars map[string]string
+	path := c.appPath(fmt.Sprintf("/releases/%d/config-vars", version))
+	if err := c.doAPIRequest(http.MethodGet, path, nil, &vars); err != nil {
+		return nil, fmt.Errorf("failed to get release config vars via API: %w", err)
+	}
+	return vars, nil
+}
+
+// GetReleases returns the fixture's releases, newest first
+func (f *FixtureSource) GetReleases(limit int) ([]config.Release, error) {
+	releases := append([]config.Release(nil), f.Releases...)
+	sortReleases(releases)
+	if limit > 0 && len(releases) > limit {
+		releases = releases[:limit]
+	}
+	return releases, nil
+}
+
+// GetReleaseConfigVars returns the fixture's config vars for a release
+func (f *FixtureSource) GetReleaseConfigVars(version int) (map[string]string, error) {
+	vars, ok := f.ReleaseConfigVars[version]
+	if !ok {
+		return nil, fmt.Errorf("fixture has no config vars for v%d", version)
+	}
+	return vars, nil
+}
@@ -0,0 +1,67 @@
+package heroku
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+func TestParseShellConfig(t *testing.T) {
+	output := "DATABASE_URL='postgres://u:p@host:5432/d'\n" +
+		"WEB_CONCURRENCY=3\n" +
+		"  RAILS_MAX_THREADS=5  \n" +
+		"GREETING='a=b'\n" +
+		"EMPTY=\n" +
+		"=== demo Config Vars\n" +
+		"not a var\n"
+
+	want := map[string]string{
+		"DATABASE_URL":      "postgres://u:p@host:5432/d",
+		"WEB_CONCURRENCY":   "3",
+		"RAILS_MAX_THREADS": "5",
+		"GREETING":          "a=b",
+		"EMPTY":             "",
+	}
+	if got := parseShellConfig(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseShellConfig() = %v; want %v", got, want)
+	}
+}
+
+func TestAPISourceGetReleases(t *testing.T) {
+	server, _ := newAPIServer(t, map[string]string{
+		"GET /apps/demo/releases": `[
+			{"version": 11, "description": "Deploy 1a2b3c4d", "user": {"email": "alice@example.com"},
+			 "created_at": "2024-05-01T12:00:00Z", "status": "succeeded", "current": false},
+			{"version": 12, "description": "Set WEB_CONCURRENCY config vars", "user": {"email": "bob@example.com"},
+			 "created_at": "2024-05-02T12:00:00Z", "status": "succeeded", "current": true},
+			{"version": 10, "description": "Attach DATABASE", "user": {"email": "alice@example.com"},
+			 "created_at": "2024-04-30T12:00:00Z", "status": "succeeded", "current": false}
+		]`,
+		"GET /apps/demo/releases/12/config-vars": `{"WEB_CONCURRENCY": "3"}`,
+	})
+	source := newTestAPISource(server)
+
+	releases, err := source.GetReleases(2)
+	if err != nil {
+		t.Fatalf("GetReleases: %v", err)
+	}
+	want := []config.Release{
+		{Version: 12, Description: "Set WEB_CONCURRENCY config vars", User: "bob@example.com",
+			CreatedAt: time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC), Status: "succeeded", Current: true},
+		{Version: 11, Description: "Deploy 1a2b3c4d", User: "alice@example.com",
+			CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), Status: "succeeded"},
+	}
+	if !reflect.DeepEqual(releases, want) {
+		t.Errorf("GetReleases() = %+v; want %+v", releases, want)
+	}
+
+	vars, err := source.GetReleaseConfigVars(12)
+	if err != nil {
+		t.Fatalf("GetReleaseConfigVars: %v", err)
+	}
+	if !reflect.DeepEqual(vars, map[string]string{"WEB_CONCURRENCY": "3"}) {
+		t.Errorf("GetReleaseConfigVars() = %v; want WEB_CONCURRENCY=3", vars)
+	}
+}
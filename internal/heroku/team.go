@@ -0,0 +1,66 @@
+package heroku
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+)
+
+// ListTeamApps returns the names of a team's apps, sorted
+// It uses the Heroku CLI when available, falling back to the Platform API with HEROKU_API_KEY
+func ListTeamApps(team string) ([]string, error) {
+	if CLIAvailable() {
+		return listTeamAppsCLI(team)
+	}
+
+	token := os.Getenv("HEROKU_API_KEY")
+	if token == "" {
+		return nil, fmt.Errorf("Heroku CLI not found and HEROKU_API_KEY is not set")
+	}
+
+	return NewAPISource("", token).ListTeamApps(team)
+}
+
+// listTeamAppsCLI lists a team's apps with `heroku apps --team`
+func listTeamAppsCLI(team string) ([]string, error) {
+	output, err := exec.Command("heroku", "apps", "--team", team, "--json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list team apps via CLI: %w", err)
+	}
+
+	var apps []struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(output, &apps); err != nil {
+		return nil, fmt.Errorf("failed to parse team apps: %w", err)
+	}
+
+	names := make([]string, len(apps))
+	for i, app := range apps {
+		names[i] = app.Name
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ListTeamApps lists a team's apps through the Platform API
+// The source's own app is not used, so it can be created with an empty app name
+func (c *APISource) ListTeamApps(team string) ([]string, error) {
+	var apps []struct {
+		Name string `json:"name"`
+	}
+	if err := c.doAPIRequest(http.MethodGet, "/teams/"+url.PathEscape(team)+"/apps", nil, &apps); err != nil {
+		return nil, fmt.Errorf("failed to list team apps via API: %w", err)
+	}
+
+	names := make([]string, len(apps))
+	for i, app := range apps {
+		names[i] = app.Name
+	}
+	sort.Strings(names)
+	return names, nil
+}
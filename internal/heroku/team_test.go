@@ -0,0 +1,34 @@
+package heroku
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAPISourceListTeamApps(t *testing.T) {
+	server, requests := newAPIServer(t, map[string]string{
+		"GET /teams/acme/apps": `[
+			{"name": "acme-web", "region": {"name": "us"}},
+			{"name": "acme-api", "region": {"name": "eu"}}
+		]`,
+	})
+
+	// The source's own app is not used
+	source := NewAPISource("", "token")
+	source.SetAPIBaseURL(server.URL)
+
+	apps, err := source.ListTeamApps("acme")
+	if err != nil {
+		t.Fatalf("ListTeamApps: %v", err)
+	}
+	if want := []string{"acme-api", "acme-web"}; !reflect.DeepEqual(apps, want) {
+		t.Errorf("ListTeamApps() = %v; want %v", apps, want)
+	}
+	if len(*requests) != 1 || (*requests)[0].Path != "/teams/acme/apps" {
+		t.Errorf("requests = %+v; want one GET /teams/acme/apps", *requests)
+	}
+
+	if _, err := source.ListTeamApps("unknown"); err == nil {
+		t.Error("ListTeamApps(unknown) succeeded; want the API's not_found error")
+	}
+}
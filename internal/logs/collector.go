@@ -0,0 +1,130 @@
+package logs
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Collector aggregates the metrics found in Heroku log lines
+type Collector struct {
+	from    time.Time
+	to      time.Time
+	runtime map[string]*runtimeSamples // By process type
+	router  *routerSamples
+}
+
+// NewCollector creates an empty collector
+func NewCollector() *Collector {
+	return &Collector{
+		runtime: make(map[string]*runtimeSamples),
+		router:  newRouterSamples(),
+	}
+}
+
+// Add records a parsed log line
+func (c *Collector) Add(line Line) {
+	if line.Source != "heroku" {
+		return
+	}
+
+	if !line.Time.IsZero() {
+		if c.from.IsZero() || line.Time.Before(c.from) {
+			c.from = line.Time
+		}
+		if line.Time.After(c.to) {
+			c.to = line.Time
+		}
+	}
+
+	if line.Dyno == "router" {
+		c.addRouter(line)
+		return
+	}
+	c.addRuntime(line)
+}
+
+// collectorState is the saved form of a collector
+type collectorState struct {
+	From    time.Time                  `json:"from"`
+	To      time.Time                  `json:"to"`
+	Runtime map[string]*runtimeSamples `json:"runtime"`
+	Router  *routerSamples             `json:"router"`
+}
+
+// MarshalJSON saves the aggregated samples (not the log lines) so they can be merged later
+func (c *Collector) MarshalJSON() ([]byte, error) {
+	return json.Marshal(collectorState{From: c.from, To: c.to, Runtime: c.runtime, Router: c.router})
+}
+
+// UnmarshalJSON restores a collector saved with MarshalJSON
+func (c *Collector) UnmarshalJSON(data []byte) error {
+	state := collectorState{Runtime: make(map[string]*runtimeSamples), Router: newRouterSamples()}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	c.from, c.to, c.runtime, c.router = state.From, state.To, state.Runtime, state.Router
+	return nil
+}
+
+// Merge adds the samples of other, e.g. to combine several days of aggregates
+func (c *Collector) Merge(other *Collector) {
+	if c.from.IsZero() || (!other.from.IsZero() && other.from.Before(c.from)) {
+		c.from = other.from
+	}
+	if other.to.After(c.to) {
+		c.to = other.to
+	}
+
+	for processType, samples := range other.runtime {
+		mine, ok := c.runtime[processType]
+		if !ok {
+			mine = newRuntimeSamples()
+			c.runtime[processType] = mine
+		}
+		mine.merge(samples)
+	}
+	c.router.merge(other.router)
+}
+
+// Read records every parseable line from r
+func (c *Collector) Read(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line, ok := ParseLine(scanner.Text()); ok {
+			c.Add(line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read logs: %w", err)
+	}
+	return nil
+}
+
+// Parse collects the metrics in `heroku logs` output
+// Lines after one too long to read are dropped
+func Parse(output string) *Collector {
+	c := NewCollector()
+	_ = c.Read(strings.NewReader(output))
+	return c
+}
+
+// LoadFile collects the metrics in a saved `heroku logs` file
+func LoadFile(path string) (*Collector, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer f.Close()
+
+	c := NewCollector()
+	if err := c.Read(f); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,87 @@
+package logs
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Line is one line of Heroku log output
+type Line struct {
+	Time    time.Time
+	Source  string // "heroku" for platform lines, "app" for the app's own output
+	Dyno    string // "web.1", "router", "worker.2", ...
+	Message string
+}
+
+// lineRegex matches `heroku logs` output: "2024-05-01T12:00:00.123456+00:00 heroku[web.1]: message"
+var lineRegex = regexp.MustCompile(`^(\S+)\s+(heroku|app)\[([^\]]+)\]:\s?(.*)$`)
+
+// ParseLine parses a line of `heroku logs` output
+func ParseLine(text string) (Line, bool) {
+	matches := lineRegex.FindStringSubmatch(strings.TrimSpace(text))
+	if matches == nil {
+		return Line{}, false
+	}
+
+	line := Line{Source: matches[2], Dyno: matches[3], Message: matches[4]}
+	line.Time, _ = time.Parse(time.RFC3339Nano, matches[1])
+	return line, true
+}
+
+// ProcessType returns the process type of a dyno name ("web.1" → "web")
+func ProcessType(dyno string) string {
+	if i := strings.LastIndex(dyno, "."); i > 0 {
+		if _, err := strconv.Atoi(dyno[i+1:]); err == nil {
+			return dyno[:i]
+		}
+	}
+	return dyno
+}
+
+// ParseFields parses the key=value pairs of a platform log message
+// Values may be double-quoted, as in the router's path="/" and desc="Request timeout"
+func ParseFields(message string) map[string]string {
+	fields := map[string]string{}
+
+	for i := 0; i < len(message); {
+		// Skip to the start of a key
+		for i < len(message) && message[i] == ' ' {
+			i++
+		}
+		start := i
+		for i < len(message) && message[i] != '=' && message[i] != ' ' {
+			i++
+		}
+		if i >= len(message) || message[i] != '=' {
+			continue
+		}
+		key := message[start:i]
+		i++
+
+		var value string
+		if i < len(message) && message[i] == '"' {
+			end := strings.IndexByte(message[i+1:], '"')
+			if end < 0 {
+				value = message[i+1:]
+				i = len(message)
+			} else {
+				value = message[i+1 : i+1+end]
+				i += end + 2
+			}
+		} else {
+			start := i
+			for i < len(message) && message[i] != ' ' {
+				i++
+			}
+			value = message[start:i]
+		}
+
+		if key != "" {
+			fields[key] = value
+		}
+	}
+
+	return fields
+}
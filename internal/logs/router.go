@@ -0,0 +1,207 @@
+package logs
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+// routerSamples holds the requests seen in heroku[router] lines
+type routerSamples struct {
+	From     time.Time               `json:"from"`
+	To       time.Time               `json:"to"`
+	Requests int                     `json:"requests"`
+	Service  *histogram              `json:"service"` // service=, ms
+	Connect  *histogram              `json:"connect"` // connect=, ms
+	Statuses map[string]int          `json:"statuses"`
+	Codes    map[string]int          `json:"codes"`
+	Dynos    map[string]*dynoSamples `json:"dynos"`
+	Minutes  map[int64]int           `json:"minutes"` // Requests by Unix minute
+}
+
+// newRouterSamples creates empty router samples
+func newRouterSamples() *routerSamples {
+	return &routerSamples{
+		Service:  newHistogram(),
+		Connect:  newHistogram(),
+		Statuses: make(map[string]int),
+		Codes:    make(map[string]int),
+		Dynos:    make(map[string]*dynoSamples),
+		Minutes:  make(map[int64]int),
+	}
+}
+
+// dynoSamples holds the requests routed to one dyno
+type dynoSamples struct {
+	Requests int        `json:"requests"`
+	Service  *histogram `json:"service"`
+	Errors   int        `json:"errors"`
+}
+
+// merge adds other's requests
+func (r *routerSamples) merge(other *routerSamples) {
+	if other.Requests == 0 {
+		return
+	}
+	if r.From.IsZero() || (!other.From.IsZero() && other.From.Before(r.From)) {
+		r.From = other.From
+	}
+	if other.To.After(r.To) {
+		r.To = other.To
+	}
+	r.Requests += other.Requests
+	r.Service.merge(other.Service)
+	r.Connect.merge(other.Connect)
+	for status, count := range other.Statuses {
+		r.Statuses[status] += count
+	}
+	for code, count := range other.Codes {
+		r.Codes[code] += count
+	}
+	for minute, count := range other.Minutes {
+		r.Minutes[minute] += count
+	}
+	for dyno, samples := range other.Dynos {
+		mine, ok := r.Dynos[dyno]
+		if !ok {
+			mine = &dynoSamples{Service: newHistogram()}
+			r.Dynos[dyno] = mine
+		}
+		mine.Requests += samples.Requests
+		mine.Service.merge(samples.Service)
+		mine.Errors += samples.Errors
+	}
+}
+
+// addRouter records a router line
+// Requests look like "at=info method=GET path="/" host=... dyno=web.1 connect=1ms service=42ms status=200 bytes=512"
+// and errors like "at=error code=H12 desc="Request timeout" ... dyno=web.1 connect=0ms service=30000ms status=503"
+func (c *Collector) addRouter(line Line) {
+	fields := ParseFields(line.Message)
+	if fields["at"] == "" || (fields["status"] == "" && fields["code"] == "") {
+		return
+	}
+
+	r := c.router
+	if !line.Time.IsZero() {
+		if r.From.IsZero() || line.Time.Before(r.From) {
+			r.From = line.Time
+		}
+		if line.Time.After(r.To) {
+			r.To = line.Time
+		}
+		r.Minutes[line.Time.Unix()/60]++
+	}
+	r.Requests++
+
+	if status := fields["status"]; len(status) == 3 {
+		r.Statuses[status[:1]+"xx"]++
+	}
+	if code := fields["code"]; code != "" {
+		r.Codes[code]++
+	}
+
+	service, hasService := parseMilliseconds(fields["service"])
+	if hasService {
+		r.Service.add(service)
+	}
+	if connect, ok := parseMilliseconds(fields["connect"]); ok {
+		r.Connect.add(connect)
+	}
+
+	// H10/H14 errors have no dyno to attribute to
+	dyno := fields["dyno"]
+	if dyno == "" {
+		return
+	}
+	samples, ok := r.Dynos[dyno]
+	if !ok {
+		samples = &dynoSamples{Service: newHistogram()}
+		r.Dynos[dyno] = samples
+	}
+	samples.Requests++
+	if hasService {
+		samples.Service.add(service)
+	}
+	if fields["code"] != "" {
+		samples.Errors++
+	}
+}
+
+// parseMilliseconds parses a router timing such as "42ms"
+func parseMilliseconds(value string) (float64, bool) {
+	number, ok := strings.CutSuffix(value, "ms")
+	if !ok {
+		return 0, false
+	}
+	parsed, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, false
+	}
+	return parsed, true
+}
+
+// RouterMetrics returns the aggregated router traffic, or nil when the logs had no router lines
+func (c *Collector) RouterMetrics(source string) *config.RouterMetrics {
+	r := c.router
+	if r.Requests == 0 {
+		return nil
+	}
+
+	metrics := &config.RouterMetrics{
+		Source:        source,
+		From:          r.From,
+		To:            r.To,
+		Requests:      r.Requests,
+		ServiceMeanMS: r.Service.mean(),
+		ServiceP50MS:  r.Service.percentile(50),
+		ServiceP95MS:  r.Service.percentile(95),
+		ServiceP99MS:  r.Service.percentile(99),
+		ServiceMaxMS:  r.Service.Max,
+		ConnectP95MS:  r.Connect.percentile(95),
+		StatusClasses: make(map[string]int, len(r.Statuses)),
+		ErrorCodes:    make(map[string]int, len(r.Codes)),
+	}
+	for _, count := range r.Minutes {
+		if float64(count) > metrics.PeakRequestsPerMinute {
+			metrics.PeakRequestsPerMinute = float64(count)
+		}
+	}
+	for class, count := range r.Statuses {
+		metrics.StatusClasses[class] = count
+	}
+	for code, count := range r.Codes {
+		metrics.ErrorCodes[code] = count
+	}
+
+	for dyno, samples := range r.Dynos {
+		metrics.Dynos = append(metrics.Dynos, config.DynoTraffic{
+			Dyno:         dyno,
+			Requests:     samples.Requests,
+			ServiceP95MS: samples.Service.percentile(95),
+			Errors:       samples.Errors,
+		})
+	}
+	sort.Slice(metrics.Dynos, func(i, j int) bool {
+		return dynoLess(metrics.Dynos[i].Dyno, metrics.Dynos[j].Dyno)
+	})
+
+	return metrics
+}
+
+// dynoLess orders dyno names by process type, then numerically ("web.2" before "web.10")
+func dynoLess(a, b string) bool {
+	typeA, typeB := ProcessType(a), ProcessType(b)
+	if typeA != typeB {
+		return typeA < typeB
+	}
+	numA, errA := strconv.Atoi(strings.TrimPrefix(a, typeA+"."))
+	numB, errB := strconv.Atoi(strings.TrimPrefix(b, typeB+"."))
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	return numA < numB
+}
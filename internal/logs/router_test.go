@@ -0,0 +1,96 @@
+package logs
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+// routerLogs is trimmed `heroku logs` output with router requests, errors and lines the router ignores
+const routerLogs = `2024-05-01T12:00:05.000000+00:00 heroku[router]: at=info method=GET path="/" host=demo.herokuapp.com request_id=a1 fwd="203.0.113.7" dyno=web.1 connect=1ms service=40ms status=200 bytes=512 protocol=https
+2024-05-01T12:00:06.000000+00:00 app[web.1]: I, [2024-05-01T12:00:06] INFO -- : Completed 200 OK in 38ms status=200
+2024-05-01T12:00:30.000000+00:00 heroku[router]: at=info method=POST path="/session" host=demo.herokuapp.com request_id=a2 fwd="203.0.113.7" dyno=web.2 connect=2ms service=60ms status=302 bytes=120 protocol=https
+2024-05-01T12:01:10.000000+00:00 heroku[router]: at=info method=GET path="/missing" host=demo.herokuapp.com request_id=a3 fwd="203.0.113.8" dyno=web.10 connect=1ms service=80ms status=404 bytes=900 protocol=https
+2024-05-01T12:01:20.000000+00:00 heroku[router]: at=error code=H12 desc="Request timeout" method=GET path="/reports" host=demo.herokuapp.com request_id=a4 fwd="203.0.113.9" dyno=web.1 connect=0ms service=30000ms status=503 bytes=0 protocol=https
+2024-05-01T12:01:40.000000+00:00 heroku[router]: at=error code=H10 desc="App crashed" method=GET path="/favicon.ico" host=demo.herokuapp.com request_id=a5 fwd="203.0.113.9" dyno= connect= service= status=503 bytes= protocol=https
+2024-05-01T12:01:50.000000+00:00 heroku[router]: sock=client at=warning
+`
+
+func TestRouterMetrics(t *testing.T) {
+	metrics := Parse(routerLogs).RouterMetrics("heroku logs")
+	if metrics == nil {
+		t.Fatal("RouterMetrics() = nil; want the router requests")
+	}
+
+	if metrics.Source != "heroku logs" || metrics.Requests != 5 {
+		t.Errorf("Source, Requests = %q, %d; want heroku logs, 5", metrics.Source, metrics.Requests)
+	}
+	wantFrom := time.Date(2024, 5, 1, 12, 0, 5, 0, time.UTC)
+	wantTo := time.Date(2024, 5, 1, 12, 1, 40, 0, time.UTC)
+	if !metrics.From.Equal(wantFrom) || !metrics.To.Equal(wantTo) {
+		t.Errorf("From, To = %s, %s; want %s, %s", metrics.From, metrics.To, wantFrom, wantTo)
+	}
+	if metrics.PeakRequestsPerMinute != 3 {
+		t.Errorf("PeakRequestsPerMinute = %.0f; want 3", metrics.PeakRequestsPerMinute)
+	}
+
+	wantStatuses := map[string]int{"2xx": 1, "3xx": 1, "4xx": 1, "5xx": 2}
+	if !reflect.DeepEqual(metrics.StatusClasses, wantStatuses) {
+		t.Errorf("StatusClasses = %v; want %v", metrics.StatusClasses, wantStatuses)
+	}
+	wantCodes := map[string]int{"H10": 1, "H12": 1}
+	if !reflect.DeepEqual(metrics.ErrorCodes, wantCodes) {
+		t.Errorf("ErrorCodes = %v; want %v", metrics.ErrorCodes, wantCodes)
+	}
+
+	// The H10 has no service time, so only four requests are timed
+	if metrics.ServiceMeanMS != 7545 || metrics.ServiceMaxMS != 30000 {
+		t.Errorf("ServiceMeanMS, ServiceMaxMS = %.0f, %.0f; want 7545, 30000", metrics.ServiceMeanMS, metrics.ServiceMaxMS)
+	}
+	if metrics.ServiceP50MS != 60 || metrics.ConnectP95MS != 2 {
+		t.Errorf("ServiceP50MS, ConnectP95MS = %.0f, %.0f; want 60, 2", metrics.ServiceP50MS, metrics.ConnectP95MS)
+	}
+
+	// H10 errors have no dyno; dynos sort numerically within a process type
+	wantDynos := []config.DynoTraffic{
+		{Dyno: "web.1", Requests: 2, ServiceP95MS: 30000, Errors: 1},
+		{Dyno: "web.2", Requests: 1, ServiceP95MS: 60},
+		{Dyno: "web.10", Requests: 1, ServiceP95MS: 80},
+	}
+	if !reflect.DeepEqual(metrics.Dynos, wantDynos) {
+		t.Errorf("Dynos = %+v; want %+v", metrics.Dynos, wantDynos)
+	}
+}
+
+func TestRouterMetricsWithoutRouterLines(t *testing.T) {
+	logs := "2024-05-01T12:00:00.000000+00:00 app[web.1]: Started GET \"/\" status=200\n"
+	if metrics := Parse(logs).RouterMetrics("heroku logs"); metrics != nil {
+		t.Errorf("RouterMetrics() = %+v; want nil", metrics)
+	}
+}
+
+func TestParseMilliseconds(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   float64
+		wantOK bool
+	}{
+		{value: "42ms", want: 42, wantOK: true},
+		{value: "0ms", want: 0, wantOK: true},
+		{value: "1.5ms", want: 1.5, wantOK: true},
+		{value: "", wantOK: false},
+		{value: "42", wantOK: false},
+		{value: "fastms", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := parseMilliseconds(tt.value)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parseMilliseconds(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+// loadScale stores load averages in the histogram as hundredths, so values
+// below 1 aren't lost to its whole-number buckets
+const loadScale = 100
+
+// runtimeSamples holds the log-runtime-metrics samples of one process type
+type runtimeSamples struct {
+	Dynos  map[string]bool `json:"dynos"`
+	Memory *histogram      `json:"memory"` // sample#memory_total, MB
+	RSS    *histogram      `json:"rss"`    // sample#memory_rss, MB
+	Load   *histogram      `json:"load"`   // sample#load_avg_1m × loadScale
+	Quota  float64         `json:"quota"`  // sample#memory_quota, MB
+	R14    int             `json:"r14"`
+	R15    int             `json:"r15"`
+}
+
+// newRuntimeSamples creates empty runtime samples
+func newRuntimeSamples() *runtimeSamples {
+	return &runtimeSamples{
+		Dynos:  make(map[string]bool),
+		Memory: newHistogram(),
+		RSS:    newHistogram(),
+		Load:   newHistogram(),
+	}
+}
+
+// UnmarshalJSON restores saved samples, including drain aggregates from before
+// samples were kept in histograms, which listed every value
+func (s *runtimeSamples) UnmarshalJSON(data []byte) error {
+	var state struct {
+		Dynos  map[string]bool `json:"dynos"`
+		Memory json.RawMessage `json:"memory"`
+		RSS    json.RawMessage `json:"rss"`
+		Load   json.RawMessage `json:"load"`
+		Quota  float64         `json:"quota"`
+		R14    int             `json:"r14"`
+		R15    int             `json:"r15"`
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+
+	*s = *newRuntimeSamples()
+	if state.Dynos != nil {
+		s.Dynos = state.Dynos
+	}
+	s.Quota, s.R14, s.R15 = state.Quota, state.R14, state.R15
+	if err := s.Memory.unmarshalSamples(state.Memory, 1); err != nil {
+		return err
+	}
+	if err := s.RSS.unmarshalSamples(state.RSS, 1); err != nil {
+		return err
+	}
+	return s.Load.unmarshalSamples(state.Load, loadScale)
+}
+
+// unmarshalSamples restores a saved histogram, or adds the values of a saved list multiplied by scale
+func (h *histogram) unmarshalSamples(data json.RawMessage, scale float64) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if data[0] != '[' {
+		if err := json.Unmarshal(data, h); err != nil {
+			return err
+		}
+		if h.Counts == nil {
+			h.Counts = make(map[int]int)
+		}
+		return nil
+	}
+
+	var values []float64
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	for _, value := range values {
+		h.add(value * scale)
+	}
+	return nil
+}
+
+// merge adds other's samples
+func (s *runtimeSamples) merge(other *runtimeSamples) {
+	for dyno := range other.Dynos {
+		s.Dynos[dyno] = true
+	}
+	s.Memory.merge(other.Memory)
+	s.RSS.merge(other.RSS)
+	s.Load.merge(other.Load)
+	if other.Quota > s.Quota {
+		s.Quota = other.Quota
+	}
+	s.R14 += other.R14
+	s.R15 += other.R15
+}
+
+// addRuntime records runtime metric samples and memory errors from a platform line
+// Samples look like "source=web.1 dyno=heroku.1.abc sample#memory_total=184.54MB ... sample#memory_quota=512.00MB"
+func (c *Collector) addRuntime(line Line) {
+	switch {
+	case strings.Contains(line.Message, "Error R14"):
+		c.runtimeFor(line.Dyno).R14++
+		return
+	case strings.Contains(line.Message, "Error R15"):
+		c.runtimeFor(line.Dyno).R15++
+		return
+	case !strings.Contains(line.Message, "sample#"):
+		return
+	}
+
+	fields := ParseFields(line.Message)
+	dyno := fields["source"]
+	if dyno == "" {
+		dyno = line.Dyno
+	}
+	samples := c.runtimeFor(dyno)
+	samples.Dynos[dyno] = true
+
+	if value, ok := parseMegabytes(fields["sample#memory_total"]); ok {
+		samples.Memory.add(value)
+	}
+	if value, ok := parseMegabytes(fields["sample#memory_rss"]); ok {
+		samples.RSS.add(value)
+	}
+	if value, ok := parseMegabytes(fields["sample#memory_quota"]); ok {
+		samples.Quota = value
+	}
+	if value, err := strconv.ParseFloat(fields["sample#load_avg_1m"], 64); err == nil {
+		samples.Load.add(value * loadScale)
+	}
+}
+
+// runtimeFor returns the samples of a dyno's process type, creating them if needed
+func (c *Collector) runtimeFor(dyno string) *runtimeSamples {
+	processType := ProcessType(dyno)
+	samples, ok := c.runtime[processType]
+	if !ok {
+		samples = newRuntimeSamples()
+		c.runtime[processType] = samples
+	}
+	return samples
+}
+
+// parseMegabytes parses a runtime metric size such as "184.54MB" into megabytes
+func parseMegabytes(value string) (float64, bool) {
+	units := map[string]float64{"kB": 1.0 / 1024, "KB": 1.0 / 1024, "MB": 1, "GB": 1024}
+	for suffix, factor := range units {
+		if number, ok := strings.CutSuffix(value, suffix); ok {
+			parsed, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return 0, false
+			}
+			return parsed * factor, true
+		}
+	}
+	return 0, false
+}
+
+// RuntimeMetrics returns the aggregated runtime metrics per process type,
+// or nil when the logs had no runtime metric samples or memory errors
+func (c *Collector) RuntimeMetrics(source string) *config.RuntimeMetrics {
+	if len(c.runtime) == 0 {
+		return nil
+	}
+
+	metrics := &config.RuntimeMetrics{
+		Source:    source,
+		From:      c.from,
+		To:        c.to,
+		Processes: make(map[string]*config.ProcessRuntime, len(c.runtime)),
+	}
+
+	for processType, samples := range c.runtime {
+		metrics.Processes[processType] = &config.ProcessRuntime{
+			Type:         processType,
+			Dynos:        len(samples.Dynos),
+			Samples:      samples.Memory.Total,
+			MemoryPeakMB: samples.Memory.Max,
+			MemoryP95MB:  samples.Memory.percentile(95),
+			RSSPeakMB:    samples.RSS.Max,
+			QuotaMB:      samples.Quota,
+			LoadAvgP95:   samples.Load.percentile(95) / loadScale,
+			LoadAvgPeak:  samples.Load.Max / loadScale,
+			R14Errors:    samples.R14,
+			R15Errors:    samples.R15,
+			LoadSamples:  samples.Load.Total,
+		}
+	}
+
+	return metrics
+}
+
+// ProcessTypes returns the process types with runtime metrics, sorted
+func ProcessTypes(metrics *config.RuntimeMetrics) []string {
+	if metrics == nil {
+		return nil
+	}
+	types := make([]string, 0, len(metrics.Processes))
+	for processType := range metrics.Processes {
+		types = append(types, processType)
+	}
+	sort.Strings(types)
+	return types
+}
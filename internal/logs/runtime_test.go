@@ -0,0 +1,152 @@
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+// runtimeLine returns a log-runtime-metrics line for web.1
+func runtimeLine(at time.Time, memory, load float64) Line {
+	return Line{
+		Time:   at,
+		Source: "heroku",
+		Dyno:   "web.1",
+		Message: fmt.Sprintf("source=web.1 dyno=heroku.1.abc sample#load_avg_1m=%.2f sample#memory_total=%.2fMB sample#memory_rss=%.2fMB sample#memory_quota=512.00MB",
+			load, memory, memory*0.9),
+	}
+}
+
+func TestRuntimeMetrics(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		memory   func(i int) float64
+		load     func(i int) float64
+		samples  int
+		wantP95  float64 // MB
+		wantPeak float64 // MB
+		wantLoad float64 // p95 load average
+	}{
+		{
+			name:     "steady",
+			memory:   func(i int) float64 { return 300 },
+			load:     func(i int) float64 { return 0.25 },
+			samples:  100,
+			wantP95:  300,
+			wantPeak: 300,
+			wantLoad: 0.25,
+		},
+		{
+			name:     "ramp",
+			memory:   func(i int) float64 { return 200 + float64(i%100)*3 },
+			load:     func(i int) float64 { return float64(i%100) / 25 },
+			samples:  1000,
+			wantP95:  482,
+			wantPeak: 497,
+			wantLoad: 3.76,
+		},
+		{
+			name:     "small values",
+			memory:   func(i int) float64 { return 40 + float64(i%10) },
+			load:     func(i int) float64 { return 0.01 * float64(i%10) },
+			samples:  100,
+			wantP95:  49,
+			wantPeak: 49,
+			wantLoad: 0.09,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCollector()
+			for i := 0; i < tt.samples; i++ {
+				c.Add(runtimeLine(start.Add(time.Duration(i)*20*time.Second), tt.memory(i), tt.load(i)))
+			}
+
+			web := c.RuntimeMetrics("logs").Processes["web"]
+			if web == nil {
+				t.Fatal("no web metrics")
+			}
+			if web.Samples != tt.samples || web.LoadSamples != tt.samples {
+				t.Errorf("Samples = %d, LoadSamples = %d; want %d", web.Samples, web.LoadSamples, tt.samples)
+			}
+			// Histogram buckets are exact below 100 and about 1% wide above
+			if !within(web.MemoryP95MB, tt.wantP95, 0.01) {
+				t.Errorf("MemoryP95MB = %.2f; want %.2f", web.MemoryP95MB, tt.wantP95)
+			}
+			if web.MemoryPeakMB != tt.wantPeak {
+				t.Errorf("MemoryPeakMB = %.2f; want %.2f", web.MemoryPeakMB, tt.wantPeak)
+			}
+			if !within(web.LoadAvgP95, tt.wantLoad, 0.01) {
+				t.Errorf("LoadAvgP95 = %.3f; want %.3f", web.LoadAvgP95, tt.wantLoad)
+			}
+			if web.QuotaMB != 512 {
+				t.Errorf("QuotaMB = %.2f; want 512", web.QuotaMB)
+			}
+		})
+	}
+}
+
+func TestRuntimeSamplesStayBounded(t *testing.T) {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	size := func(samples int) int {
+		c := NewCollector()
+		for i := 0; i < samples; i++ {
+			c.Add(runtimeLine(start.Add(time.Duration(i)*time.Second), 200+float64(i%300), float64(i%400)/100))
+		}
+		data, err := json.Marshal(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(data)
+	}
+
+	// A day of one dyno's samples against ten days of them; only the counts grow
+	day, tenDays := size(4320), size(43200)
+	if tenDays > 2*day {
+		t.Errorf("saved samples grew from %d to %d bytes with ten times the samples", day, tenDays)
+	}
+}
+
+func TestRuntimeSamplesFromSavedList(t *testing.T) {
+	// A drain aggregate written when every sample was listed
+	saved := `{"from":"2024-05-01T12:00:00Z","to":"2024-05-01T12:01:00Z","router":null,
+		"runtime":{"web":{"dynos":{"web.1":true},"memory":[300,310,320],"rss":[290,295,300],"load":[0.5,1.5,2.5],"quota":512,"r14":1,"r15":0}}}`
+
+	c := NewCollector()
+	if err := json.Unmarshal([]byte(saved), c); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	// Saved again, samples are kept as histograms and read back the same
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := NewCollector()
+	if err := json.Unmarshal(data, restored); err != nil {
+		t.Fatalf("Unmarshal resaved: %v", err)
+	}
+
+	for name, collector := range map[string]*Collector{"saved list": c, "resaved": restored} {
+		web := collector.RuntimeMetrics("drain").Processes["web"]
+		if web == nil {
+			t.Fatalf("%s: no web metrics", name)
+		}
+		if web.Samples != 3 || web.LoadSamples != 3 || web.MemoryPeakMB != 320 || web.RSSPeakMB != 300 || web.R14Errors != 1 {
+			t.Errorf("%s: got %+v", name, web)
+		}
+		if !within(web.LoadAvgPeak, 2.5, 0.001) {
+			t.Errorf("%s: LoadAvgPeak = %.3f; want 2.5", name, web.LoadAvgPeak)
+		}
+	}
+}
+
+// within reports whether got is within a fraction of want
+func within(got, want, fraction float64) bool {
+	return math.Abs(got-want) <= want*fraction
+}
@@ -0,0 +1,101 @@
+package logs
+
+import (
+	"math"
+	"sort"
+)
+
+// Histogram buckets: exact below histogramLinearMax, then about 1% wide
+const (
+	histogramLinearMax = 100
+	histogramGrowth    = 1.01
+)
+
+// histogram counts values in buckets so the percentiles of many samples can be
+// kept, saved and merged in bounded space
+type histogram struct {
+	Counts map[int]int `json:"counts"` // By bucket index
+	Total  int         `json:"total"`
+	Sum    float64     `json:"sum"`
+	Max    float64     `json:"max"`
+}
+
+// newHistogram creates an empty histogram
+func newHistogram() *histogram {
+	return &histogram{Counts: make(map[int]int)}
+}
+
+// bucketIndex returns the bucket of a value
+func bucketIndex(value float64) int {
+	if value < histogramLinearMax {
+		if value < 0 {
+			return 0
+		}
+		return int(value)
+	}
+	return histogramLinearMax + int(math.Log(value/histogramLinearMax)/math.Log(histogramGrowth))
+}
+
+// bucketValue returns the value a bucket stands for: exact below histogramLinearMax
+// (router timings are whole milliseconds), otherwise the bucket's geometric middle
+func bucketValue(index int) float64 {
+	if index < histogramLinearMax {
+		return float64(index)
+	}
+	return histogramLinearMax * math.Pow(histogramGrowth, float64(index-histogramLinearMax)+0.5)
+}
+
+func (h *histogram) add(value float64) {
+	h.Counts[bucketIndex(value)]++
+	h.Total++
+	h.Sum += value
+	if value > h.Max {
+		h.Max = value
+	}
+}
+
+func (h *histogram) merge(other *histogram) {
+	for index, count := range other.Counts {
+		h.Counts[index] += count
+	}
+	h.Total += other.Total
+	h.Sum += other.Sum
+	if other.Max > h.Max {
+		h.Max = other.Max
+	}
+}
+
+// mean returns the exact average of the values added
+func (h *histogram) mean() float64 {
+	if h.Total == 0 {
+		return 0
+	}
+	return h.Sum / float64(h.Total)
+}
+
+// percentile returns the p-th percentile (0-100) using the nearest-rank method,
+// to within the bucket width
+func (h *histogram) percentile(p float64) float64 {
+	if h.Total == 0 {
+		return 0
+	}
+
+	indexes := make([]int, 0, len(h.Counts))
+	for index := range h.Counts {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	rank := int(math.Ceil(p / 100 * float64(h.Total)))
+	if rank < 1 {
+		rank = 1
+	}
+	seen := 0
+	for _, index := range indexes {
+		seen += h.Counts[index]
+		if seen >= rank {
+			return math.Min(bucketValue(index), h.Max)
+		}
+	}
+	return h.Max
+}
@@ -12,11 +12,16 @@ const (
 	cacheTTL      = 24 * time.Hour
 	cacheDir      = ".heroku-calc"
 	cacheFileName = "pricing_cache.json"
+
+	// cacheFormat is bumped whenever Data gains a catalog, so caches written by older builds
+	// (without redis_providers and addons) are refetched instead of served for up to cacheTTL
+	cacheFormat = 2
 )
 
 type cacheEntry struct {
 	Data      Data      `json:"data"`
 	Timestamp time.Time `json:"timestamp"`
+	Format    int       `json:"format"` // 0 for caches written before the format was recorded
 }
 
 // GetCachePath returns the path to the pricing cache file
@@ -50,6 +55,10 @@ func LoadFromCache() (*Data, error) {
 		return nil, fmt.Errorf("failed to parse cache: %w", err)
 	}
 
+	if entry.Format != cacheFormat {
+		return nil, fmt.Errorf("cache written by an older version")
+	}
+
 	// Check if cache is expired
 	if time.Since(entry.Timestamp) > cacheTTL {
 		return nil, fmt.Errorf("cache expired")
@@ -74,6 +83,7 @@ func SaveToCache(data *Data) error {
 	entry := cacheEntry{
 		Data:      *data,
 		Timestamp: time.Now(),
+		Format:    cacheFormat,
 	}
 
 	jsonData, err := json.MarshalIndent(entry, "", "  ")
@@ -0,0 +1,109 @@
+package pricing
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCache writes a raw cache entry under a temporary home directory
+func writeCache(t *testing.T, entry map[string]interface{}) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(home, cacheDir, cacheFileName)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadFromCache(t *testing.T) {
+	oldData := map[string]interface{}{
+		"version": "2024-01-01",
+		"dynos":   map[string]interface{}{"standard-1x": map[string]interface{}{"name": "Standard-1X", "price_monthly": 25}},
+	}
+
+	tests := []struct {
+		name    string
+		entry   map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:    "written before the format was recorded",
+			entry:   map[string]interface{}{"data": oldData, "timestamp": time.Now()},
+			wantErr: true,
+		},
+		{
+			name:    "older format",
+			entry:   map[string]interface{}{"data": oldData, "timestamp": time.Now(), "format": cacheFormat - 1},
+			wantErr: true,
+		},
+		{
+			name:    "expired",
+			entry:   map[string]interface{}{"data": oldData, "timestamp": time.Now().Add(-2 * cacheTTL), "format": cacheFormat},
+			wantErr: true,
+		},
+		{
+			name:  "current",
+			entry: map[string]interface{}{"data": oldData, "timestamp": time.Now(), "format": cacheFormat},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeCache(t, tt.entry)
+			_, err := LoadFromCache()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadFromCache() error = %v; wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetRefreshesStaleCacheFormat(t *testing.T) {
+	writeCache(t, map[string]interface{}{
+		"data": map[string]interface{}{
+			"version": "2024-01-01",
+			"dynos":   map[string]interface{}{"standard-1x": map[string]interface{}{"name": "Standard-1X", "price_monthly": 25}},
+		},
+		"timestamp": time.Now(),
+	})
+
+	data, err := Get()
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if _, err := data.GetAddonPrice("papertrail", "choklad"); err != nil {
+		t.Errorf("GetAddonPrice(papertrail, choklad) after an old cache: %v", err)
+	}
+
+	// The refreshed cache is served from now on
+	cached, err := LoadFromCache()
+	if err != nil {
+		t.Fatalf("LoadFromCache() after Get() error = %v", err)
+	}
+	if len(cached.Addons) == 0 {
+		t.Error("refreshed cache has no add-on catalog")
+	}
+}
+
+func TestBundledDataMatchesDataDir(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "data", "pricing.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, bundledPricingData) {
+		t.Error("data/pricing.json differs from internal/pricing/pricing_data.json; update both together")
+	}
+}
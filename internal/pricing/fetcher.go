@@ -2,6 +2,9 @@ package pricing
 
 import (
 	"fmt"
+	"sort"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
 )
 
 // Fetch attempts to fetch fresh pricing data from Heroku
@@ -74,6 +77,115 @@ func (d *Data) GetRedisPrice(plan string) (*RedisPrice, error) {
 	return nil, fmt.Errorf("redis plan not found: %s", plan)
 }
 
+// HerokuRedisService is the add-on service of Heroku Data for Redis, whose plans are in Redis
+const HerokuRedisService = "heroku-redis"
+
+// GetRedisPlanPrice looks up pricing for a plan of a Redis add-on service
+// Plans of Heroku Data for Redis, or of add-ons whose service is unknown, come from Redis
+func (d *Data) GetRedisPlanPrice(service, plan string) (*RedisPrice, error) {
+	if service == "" || service == HerokuRedisService {
+		return d.GetRedisPrice(plan)
+	}
+
+	provider, ok := d.RedisProviders[service]
+	if !ok {
+		return nil, fmt.Errorf("redis provider not found: %s", service)
+	}
+	if price, ok := provider.Plans[normalizeKey(plan)]; ok {
+		return &price, nil
+	}
+	return nil, fmt.Errorf("redis plan not found: %s:%s", service, plan)
+}
+
+// RedisPlans returns the plans of a Redis add-on service from cheapest to most expensive
+func (d *Data) RedisPlans(service string) []string {
+	plans := d.Redis
+	if service != "" && service != HerokuRedisService {
+		plans = d.RedisProviders[service].Plans
+	}
+
+	names := make([]string, 0, len(plans))
+	for name := range plans {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := plans[names[i]], plans[names[j]]
+		if a.PriceMonthly != b.PriceMonthly {
+			return a.PriceMonthly < b.PriceMonthly
+		}
+		return a.MaxConnections < b.MaxConnections
+	})
+	return names
+}
+
+// RedisProviderName returns the display name of a Redis add-on service, e.g. "Redis Cloud"
+func (d *Data) RedisProviderName(service string) string {
+	if service == "" || service == HerokuRedisService {
+		return "Heroku Data for Redis"
+	}
+	if provider, ok := d.RedisProviders[service]; ok && provider.Name != "" {
+		return provider.Name
+	}
+	return service
+}
+
+// Bill categories of the formation and of Heroku's and other providers' data add-ons
+const (
+	CategoryDynos = "Dynos"
+	CategoryData  = "Data"
+)
+
+// GetAddonPrice looks up pricing for a plan of any add-on service
+// Postgres and Redis plans come from their own catalogs, under CategoryData
+func (d *Data) GetAddonPrice(service, plan string) (*AddonPrice, error) {
+	switch {
+	case service == "heroku-postgresql":
+		price, err := d.GetPostgresPrice(plan)
+		if err != nil {
+			return nil, err
+		}
+		return &AddonPrice{Name: "Heroku Postgres " + price.Name, Category: CategoryData, PriceMonthly: price.PriceMonthly}, nil
+	case service == HerokuRedisService || d.RedisProviders[service].Plans != nil:
+		price, err := d.GetRedisPlanPrice(service, plan)
+		if err != nil {
+			return nil, err
+		}
+		return &AddonPrice{Name: d.RedisProviderName(service) + " " + price.Name, Category: CategoryData, PriceMonthly: price.PriceMonthly}, nil
+	}
+
+	addonService, ok := d.Addons[service]
+	if !ok {
+		return nil, fmt.Errorf("addon service not found: %s", service)
+	}
+	addonPlan, ok := addonService.Plans[normalizeKey(plan)]
+	if !ok {
+		return nil, fmt.Errorf("addon plan not found: %s:%s", service, plan)
+	}
+	return &AddonPrice{Name: addonService.Name + " " + addonPlan.Name, Category: addonService.Category, PriceMonthly: addonPlan.PriceMonthly}, nil
+}
+
+// PriceAddons fills in the monthly list price of add-ons whose plan is in the pricing data
+// Add-ons attached from another app are billed to that app
+func (d *Data) PriceAddons(addons []config.Addon) {
+	for i, addon := range addons {
+		if addon.OwnerApp != "" {
+			addons[i].Price = "billed to " + addon.OwnerApp
+			continue
+		}
+		if price, err := d.GetAddonPrice(addon.ServiceName(), addon.PlanName()); err == nil {
+			addons[i].Price = FormatMonthly(price.PriceMonthly)
+		}
+	}
+}
+
+// FormatMonthly formats a monthly price, e.g. "$50.00/month" or "free"
+func FormatMonthly(price float64) string {
+	if price == 0 {
+		return "free"
+	}
+	return fmt.Sprintf("$%.2f/month", price)
+}
+
 // normalizeKey converts a key to lowercase for consistent lookups
 func normalizeKey(key string) string {
 	// Convert to lowercase and replace underscores with hyphens
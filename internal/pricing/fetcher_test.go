@@ -0,0 +1,84 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+func TestRedisProviderPlansAfterStaleCache(t *testing.T) {
+	// A cache written before the marketplace catalogs existed
+	writeCache(t, map[string]interface{}{
+		"data": map[string]interface{}{
+			"version": "2024-01-01",
+			"redis":   map[string]interface{}{"premium-0": map[string]interface{}{"name": "Premium 0", "max_connections": 40}},
+		},
+		"timestamp": time.Now(),
+	})
+
+	data, err := Get()
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	for _, service := range []string{"rediscloud", "upstash-redis", "memetria-redis", "ah-redis-stackhero"} {
+		plans := data.RedisPlans(service)
+		if len(plans) == 0 {
+			t.Errorf("RedisPlans(%s) is empty", service)
+			continue
+		}
+		if _, err := data.GetRedisPlanPrice(service, plans[0]); err != nil {
+			t.Errorf("GetRedisPlanPrice(%s, %s): %v", service, plans[0], err)
+		}
+	}
+}
+
+func TestRedisPlans(t *testing.T) {
+	data, err := LoadBundled()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, service := range []string{"", HerokuRedisService, "rediscloud", "upstash-redis", "memetria-redis", "ah-redis-stackhero"} {
+		plans := data.RedisPlans(service)
+		for i := 1; i < len(plans); i++ {
+			previous, _ := data.GetRedisPlanPrice(service, plans[i-1])
+			current, _ := data.GetRedisPlanPrice(service, plans[i])
+			if previous == nil || current == nil {
+				t.Fatalf("RedisPlans(%q) lists a plan GetRedisPlanPrice can't find: %v", service, plans)
+			}
+			if previous.PriceMonthly > current.PriceMonthly {
+				t.Errorf("RedisPlans(%q): %s ($%.2f) listed before %s ($%.2f)",
+					service, plans[i-1], previous.PriceMonthly, plans[i], current.PriceMonthly)
+			}
+		}
+	}
+
+	if _, err := data.GetRedisPlanPrice("unknown-redis", "30"); err == nil {
+		t.Error("GetRedisPlanPrice(unknown-redis, 30) found a plan")
+	}
+}
+
+func TestPriceAddons(t *testing.T) {
+	data, err := LoadBundled()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addons := []config.Addon{
+		{Name: "pg-main", Plan: "heroku-postgresql:standard-0", Service: "heroku-postgresql"},
+		{Name: "papertrail-1", Plan: "papertrail:choklad"},
+		{Name: "papertrail-2", Plan: "papertrail:volmar"},
+		{Name: "pg-shared", Plan: "heroku-postgresql:standard-2", Service: "heroku-postgresql", OwnerApp: "billing"},
+		{Name: "mystery-1", Plan: "mystery-addon:gold", Price: "unknown"},
+	}
+	data.PriceAddons(addons)
+
+	want := []string{"$50.00/month", "free", "$14.00/month", "billed to billing", "unknown"}
+	for i, addon := range addons {
+		if addon.Price != want[i] {
+			t.Errorf("%s Price = %q; want %q", addon.Name, addon.Price, want[i])
+		}
+	}
+}
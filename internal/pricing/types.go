@@ -6,6 +6,12 @@ type Data struct {
 	Dynos    map[string]DynoPrice    `json:"dynos"`
 	Postgres map[string]PostgresPrice `json:"postgres"`
 	Redis    map[string]RedisPrice   `json:"redis"`
+
+	// Redis add-ons from Elements Marketplace providers, by add-on service
+	RedisProviders map[string]RedisProvider `json:"redis_providers,omitempty"`
+
+	// Other add-ons, by add-on service
+	Addons map[string]AddonService `json:"addons,omitempty"`
 }
 
 // DynoPrice represents pricing for a dyno type
@@ -36,3 +42,29 @@ type RedisPrice struct {
 	EvictionPolicy  string  `json:"eviction_policy,omitempty"`
 	HighAvailability bool   `json:"high_availability,omitempty"`
 }
+
+// RedisProvider is the plan catalog of a marketplace Redis add-on, e.g. "rediscloud"
+type RedisProvider struct {
+	Name  string                `json:"name"`
+	Plans map[string]RedisPrice `json:"plans"`
+}
+
+// AddonService is the plan catalog of an add-on service, e.g. "papertrail"
+type AddonService struct {
+	Name     string               `json:"name"`
+	Category string               `json:"category"` // Bill category, e.g. "Logging"
+	Plans    map[string]AddonPlan `json:"plans"`
+}
+
+// AddonPlan represents pricing for an add-on plan
+type AddonPlan struct {
+	Name         string  `json:"name"`
+	PriceMonthly float64 `json:"price_monthly"`
+}
+
+// AddonPrice is the monthly list price of any add-on plan and the bill category it falls in
+type AddonPrice struct {
+	Name         string
+	Category     string
+	PriceMonthly float64
+}
@@ -0,0 +1,178 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// DatabaseConfigPath is the conventional ActiveRecord config location relative to the project root
+const DatabaseConfigPath = "config/database.yml"
+
+// DefaultPoolSize is ActiveRecord's pool size when database.yml doesn't set one
+const DefaultPoolSize = 5
+
+// DatabaseConfig holds the databases declared per environment in config/database.yml
+type DatabaseConfig struct {
+	// Environments maps an environment name to its databases, primary first
+	Environments map[string][]DatabaseEntry
+}
+
+// DatabaseEntry is one database of an environment
+// ERB expressions are kept unevaluated so they can be resolved against the app's env vars
+type DatabaseEntry struct {
+	// Name is the database key in a multi-database config ("primary" for single-database configs)
+	Name string
+
+	// PoolExpr is the pool setting as a Ruby expression ("" if not set)
+	PoolExpr string
+
+	// URLExpr is the url setting as a Ruby expression ("" if not set)
+	URLExpr string
+
+	// Replica is true for read replicas (replica: true)
+	Replica bool
+
+	// PreparedStatementsExpr and AdvisoryLocksExpr are those settings as Ruby expressions ("" if not set)
+	PreparedStatementsExpr string
+	AdvisoryLocksExpr      string
+}
+
+// envNameRegex extracts the env var name from ENV["NAME"] and ENV.fetch("NAME")
+var envNameRegex = regexp.MustCompile(`ENV(?:\[|\.fetch\()\s*["']([^"']+)["']`)
+
+// LoadDatabaseConfig parses config/database.yml under projectPath
+// It returns nil without error if the file does not exist
+func LoadDatabaseConfig(projectPath string) (*DatabaseConfig, error) {
+	path := filepath.Join(projectPath, DatabaseConfigPath)
+	if _, err := os.Stat(path); err != nil {
+		return nil, nil
+	}
+	return ParseDatabaseConfig(path)
+}
+
+// ParseDatabaseConfig parses a database.yml file, keeping ERB output tags as expressions
+func ParseDatabaseConfig(path string) (*DatabaseConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", DatabaseConfigPath, err)
+	}
+
+	doc, err := parseERBYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", DatabaseConfigPath, err)
+	}
+
+	cfg := &DatabaseConfig{Environments: make(map[string][]DatabaseEntry)}
+	for env, value := range doc.Root {
+		settings, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		// Like Rails, an environment whose values are all hashes is a multi-database config
+		if isMultiDatabase(settings) {
+			names := make([]string, 0, len(settings))
+			for name := range settings {
+				names = append(names, name)
+			}
+			sort.Slice(names, func(i, j int) bool {
+				if (names[i] == "primary") != (names[j] == "primary") {
+					return names[i] == "primary"
+				}
+				return names[i] < names[j]
+			})
+
+			for _, name := range names {
+				db := settings[name].(map[string]interface{})
+				cfg.Environments[env] = append(cfg.Environments[env], newDatabaseEntry(name, db, doc))
+			}
+			continue
+		}
+
+		cfg.Environments[env] = []DatabaseEntry{newDatabaseEntry("primary", settings, doc)}
+	}
+
+	return cfg, nil
+}
+
+// isMultiDatabase returns true if every setting of an environment is itself a database config
+func isMultiDatabase(settings map[string]interface{}) bool {
+	if len(settings) == 0 {
+		return false
+	}
+	for _, value := range settings {
+		if _, ok := value.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// newDatabaseEntry builds an entry from a parsed database hash
+func newDatabaseEntry(name string, settings map[string]interface{}, doc *erbYAML) DatabaseEntry {
+	entry := DatabaseEntry{
+		Name:     name,
+		PoolExpr: doc.ValueExpr(settings["pool"]),
+		URLExpr:  doc.ValueExpr(settings["url"]),
+
+		PreparedStatementsExpr: doc.ValueExpr(settings["prepared_statements"]),
+		AdvisoryLocksExpr:      doc.ValueExpr(settings["advisory_locks"]),
+	}
+	if replica, ok := settings["replica"].(bool); ok {
+		entry.Replica = replica
+	}
+	return entry
+}
+
+// Databases returns the databases configured for env, or nil
+func (c *DatabaseConfig) Databases(env string) []DatabaseEntry {
+	if c == nil {
+		return nil
+	}
+	return c.Environments[env]
+}
+
+// Pool evaluates the pool size against env
+// The boolean result is false when the pool expression cannot be interpreted
+func (e DatabaseEntry) Pool(env map[string]string) (int, bool) {
+	if e.PoolExpr == "" {
+		return DefaultPoolSize, true
+	}
+	return EvalInt(e.PoolExpr, env, nil)
+}
+
+// URLEnv returns the env var holding this database's URL
+// Rails merges DATABASE_URL into the primary database when it has no url of its own
+func (e DatabaseEntry) URLEnv() string {
+	if matches := envNameRegex.FindStringSubmatch(e.URLExpr); matches != nil {
+		return matches[1]
+	}
+	if e.URLExpr == "" && e.Name == "primary" {
+		return "DATABASE_URL"
+	}
+	return ""
+}
+
+// PreparedStatementsDisabled returns false when prepared statements are left at ActiveRecord's default (on)
+// Settings computed in ERB are assumed to turn them off
+func (e DatabaseEntry) PreparedStatementsDisabled() bool {
+	return settingDisabled(e.PreparedStatementsExpr)
+}
+
+// AdvisoryLocksDisabled returns false when migration advisory locks are left at ActiveRecord's default (on)
+func (e DatabaseEntry) AdvisoryLocksDisabled() bool {
+	return settingDisabled(e.AdvisoryLocksExpr)
+}
+
+// settingDisabled returns true if a boolean setting is set to anything but true
+func settingDisabled(expr string) bool {
+	return expr != "" && expr != "true"
+}
+
+// ReadsEnv returns true if the pool size can be changed through the named env var
+func (e DatabaseEntry) ReadsEnv(name string) bool {
+	return ReferencesEnv(e.PoolExpr, name, nil)
+}
@@ -0,0 +1,198 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseDatabaseConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		env  string
+		want []DatabaseEntry
+	}{
+		{
+			name: "single database with ERB pool",
+			yaml: "default: &default\n" +
+				"  adapter: postgresql\n" +
+				"  pool: <%= ENV.fetch(\"RAILS_MAX_THREADS\") { 5 } %>\n" +
+				"<% if ENV[\"CI\"] %>\n" +
+				"test:\n" +
+				"  <<: *default\n" +
+				"<% end %>\n" +
+				"production:\n" +
+				"  <<: *default\n" +
+				"  prepared_statements: false\n",
+			env: "production",
+			want: []DatabaseEntry{{
+				Name:                   "primary",
+				PoolExpr:               `ENV.fetch("RAILS_MAX_THREADS") { 5 }`,
+				PreparedStatementsExpr: "false",
+			}},
+		},
+		{
+			name: "multiple databases",
+			yaml: "default: &default\n" +
+				"  adapter: postgresql\n" +
+				"  pool: 10\n" +
+				"production:\n" +
+				"  cache:\n" +
+				"    <<: *default\n" +
+				"    url: <%= ENV[\"CACHE_DATABASE_URL\"] %>\n" +
+				"    advisory_locks: false\n" +
+				"  primary:\n" +
+				"    <<: *default\n" +
+				"  primary_replica:\n" +
+				"    <<: *default\n" +
+				"    url: <%= ENV.fetch(\"FOLLOWER_URL\") %>\n" +
+				"    replica: true\n",
+			env: "production",
+			want: []DatabaseEntry{
+				{Name: "primary", PoolExpr: "10"},
+				{Name: "cache", PoolExpr: "10", URLExpr: `ENV["CACHE_DATABASE_URL"]`, AdvisoryLocksExpr: "false"},
+				{Name: "primary_replica", PoolExpr: "10", URLExpr: `ENV.fetch("FOLLOWER_URL")`, Replica: true},
+			},
+		},
+		{
+			name: "missing environment",
+			yaml: "development:\n  adapter: postgresql\n",
+			env:  "production",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, DatabaseConfigPath)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(tt.yaml), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := LoadDatabaseConfig(dir)
+			if err != nil {
+				t.Fatalf("LoadDatabaseConfig: %v", err)
+			}
+
+			if got := cfg.Databases(tt.env); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Databases(%q) = %+v; want %+v", tt.env, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsMultiDatabase(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		want     bool
+	}{
+		{
+			name:     "database settings",
+			settings: map[string]interface{}{"adapter": "postgresql", "pool": 5},
+			want:     false,
+		},
+		{
+			name: "database hashes",
+			settings: map[string]interface{}{
+				"primary": map[string]interface{}{"adapter": "postgresql"},
+				"queue":   map[string]interface{}{"adapter": "postgresql"},
+			},
+			want: true,
+		},
+		{
+			name: "hash setting next to scalars",
+			settings: map[string]interface{}{
+				"adapter":   "postgresql",
+				"variables": map[string]interface{}{"statement_timeout": "5s"},
+			},
+			want: false,
+		},
+		{
+			name:     "empty",
+			settings: map[string]interface{}{},
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isMultiDatabase(tt.settings); got != tt.want {
+				t.Errorf("isMultiDatabase() = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDatabaseEntry(t *testing.T) {
+	tests := []struct {
+		name          string
+		entry         DatabaseEntry
+		env           map[string]string
+		wantURLEnv    string
+		wantPool      int
+		wantPoolOK    bool
+		wantPrepared  bool
+		wantReadsPool string
+	}{
+		{
+			name:       "primary without url reads DATABASE_URL",
+			entry:      DatabaseEntry{Name: "primary"},
+			wantURLEnv: "DATABASE_URL",
+			wantPool:   DefaultPoolSize,
+			wantPoolOK: true,
+		},
+		{
+			name:       "url from ENV[]",
+			entry:      DatabaseEntry{Name: "cache", URLExpr: `ENV["CACHE_DATABASE_URL"]`, PoolExpr: "3"},
+			wantURLEnv: "CACHE_DATABASE_URL",
+			wantPool:   3,
+			wantPoolOK: true,
+		},
+		{
+			name:       "literal url",
+			entry:      DatabaseEntry{Name: "primary", URLExpr: `"postgres://localhost/app"`},
+			wantURLEnv: "",
+			wantPool:   DefaultPoolSize,
+			wantPoolOK: true,
+		},
+		{
+			name:          "pool from env",
+			entry:         DatabaseEntry{Name: "primary", PoolExpr: `ENV.fetch("DB_POOL") { 5 }`, PreparedStatementsExpr: `ENV["PGBOUNCER"] != "true"`},
+			env:           map[string]string{"DB_POOL": "15"},
+			wantURLEnv:    "DATABASE_URL",
+			wantPool:      15,
+			wantPoolOK:    true,
+			wantPrepared:  true,
+			wantReadsPool: "DB_POOL",
+		},
+		{
+			name:       "uninterpretable pool",
+			entry:      DatabaseEntry{Name: "primary", PoolExpr: "Rails.application.credentials.pool"},
+			wantURLEnv: "DATABASE_URL",
+			wantPoolOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.entry.URLEnv(); got != tt.wantURLEnv {
+				t.Errorf("URLEnv() = %q; want %q", got, tt.wantURLEnv)
+			}
+			got, ok := tt.entry.Pool(tt.env)
+			if ok != tt.wantPoolOK || (ok && got != tt.wantPool) {
+				t.Errorf("Pool() = %d, %v; want %d, %v", got, ok, tt.wantPool, tt.wantPoolOK)
+			}
+			if disabled := tt.entry.PreparedStatementsDisabled(); disabled != tt.wantPrepared {
+				t.Errorf("PreparedStatementsDisabled() = %v; want %v", disabled, tt.wantPrepared)
+			}
+			if tt.wantReadsPool != "" && !tt.entry.ReadsEnv(tt.wantReadsPool) {
+				t.Errorf("ReadsEnv(%q) = false; want true", tt.wantReadsPool)
+			}
+		})
+	}
+}
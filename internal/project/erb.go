@@ -0,0 +1,59 @@
+package project
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	erbTagRegex         = regexp.MustCompile(`(?s)<%(-|=)?(.*?)-?%>`)
+	erbPlaceholderRegex = regexp.MustCompile(`^__erb_(\d+)__$`)
+)
+
+// erbYAML is a YAML document whose ERB output tags were replaced with placeholders
+type erbYAML struct {
+	Root  map[string]interface{}
+	exprs []string
+}
+
+// parseERBYAML parses a YAML file that Rails or Sidekiq renders through ERB first
+// <%= expr %> tags become placeholders that ValueExpr maps back to expressions;
+// <% code %> tags produce no output and are dropped
+func parseERBYAML(data []byte) (*erbYAML, error) {
+	doc := &erbYAML{}
+	source := erbTagRegex.ReplaceAllStringFunc(string(data), func(tag string) string {
+		matches := erbTagRegex.FindStringSubmatch(tag)
+		if matches[1] != "=" {
+			return ""
+		}
+		doc.exprs = append(doc.exprs, strings.TrimSpace(matches[2]))
+		return fmt.Sprintf("__erb_%d__", len(doc.exprs)-1)
+	})
+
+	if err := yaml.Unmarshal([]byte(source), &doc.Root); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// ValueExpr converts a parsed YAML value back to a Ruby expression ("" for nil and collections)
+func (d *erbYAML) ValueExpr(value interface{}) string {
+	switch v := value.(type) {
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case string:
+		if matches := erbPlaceholderRegex.FindStringSubmatch(v); matches != nil {
+			index, _ := strconv.Atoi(matches[1])
+			return d.exprs[index]
+		}
+		return strconv.Quote(v)
+	default:
+		return ""
+	}
+}
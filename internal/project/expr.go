@@ -0,0 +1,340 @@
+package project
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Expressions in Rails config files are Ruby, but the ones that matter for
+// capacity follow a handful of idioms:
+//
+//	5
+//	ENV["RAILS_MAX_THREADS"]
+//	ENV.fetch("RAILS_MAX_THREADS") { 5 }
+//	ENV.fetch("RAILS_MAX_THREADS", 5)
+//	Integer(ENV["WEB_CONCURRENCY"] || 2)
+//	ENV.fetch("DB_POOL") { ENV["RAILS_MAX_THREADS"] || 5 }.to_i
+//	ENV.fetch("RAILS_MAX_THREADS") { 5 }.to_i + 2
+//	max_threads_count
+//
+// EvalInt evaluates those idioms against a set of env vars and local variables.
+
+var (
+	intLiteralRegex    = regexp.MustCompile(`^-?\d+$`)
+	stringLiteralRegex = regexp.MustCompile(`^["']([^"']*)["']$`)
+	envIndexRegex      = regexp.MustCompile(`^ENV\[\s*["']([^"']+)["']\s*\]$`)
+	identifierRegex    = regexp.MustCompile(`^[a-z_][A-Za-z0-9_]*$`)
+	identifierUseRegex = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+)
+
+// EvalInt evaluates a Ruby integer expression
+// vars maps local variable names to their (unevaluated) expressions
+func EvalInt(expr string, env map[string]string, vars map[string]string) (int, bool) {
+	value, ok := evalExpr(expr, env, vars, 0)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// ReferencesEnv returns true if expr (or a variable it uses) reads the named env var
+func ReferencesEnv(expr, name string, vars map[string]string) bool {
+	return referencesEnv(expr, name, vars, 0)
+}
+
+func referencesEnv(expr, name string, vars map[string]string, depth int) bool {
+	if depth > 10 {
+		return false
+	}
+	if strings.Contains(expr, `"`+name+`"`) || strings.Contains(expr, `'`+name+`'`) {
+		return true
+	}
+	if len(vars) == 0 {
+		return false
+	}
+	for _, identifier := range identifierUseRegex.FindAllString(expr, -1) {
+		if varExpr, ok := vars[identifier]; ok && referencesEnv(varExpr, name, vars, depth+1) {
+			return true
+		}
+	}
+	return false
+}
+
+// evalExpr evaluates an expression to its string value
+// The boolean result is false when the value is nil or cannot be determined
+func evalExpr(expr string, env map[string]string, vars map[string]string, depth int) (string, bool) {
+	// Guard against self-referencing variables
+	if depth > 10 {
+		return "", false
+	}
+
+	expr = unwrapExpr(expr)
+	if expr == "" {
+		return "", false
+	}
+
+	// a || b returns the first non-nil operand
+	if parts := splitTopLevel(expr, "||"); len(parts) > 1 {
+		for _, part := range parts {
+			if value, ok := evalExpr(part, env, vars, depth+1); ok {
+				return value, true
+			}
+		}
+		return "", false
+	}
+
+	// Integer arithmetic binds tighter than ||, and * and / tighter than + and -
+	for _, ops := range []string{"+-", "*/"} {
+		if left, op, right, ok := splitArithmetic(expr, ops); ok {
+			return evalArithmetic(left, op, right, env, vars, depth)
+		}
+	}
+
+	if intLiteralRegex.MatchString(expr) {
+		return expr, true
+	}
+
+	if matches := stringLiteralRegex.FindStringSubmatch(expr); matches != nil {
+		return matches[1], true
+	}
+
+	if matches := envIndexRegex.FindStringSubmatch(expr); matches != nil {
+		value, ok := env[matches[1]]
+		return value, ok
+	}
+
+	if strings.HasPrefix(expr, "ENV.fetch(") {
+		return evalEnvFetch(expr, env, vars, depth)
+	}
+
+	if identifierRegex.MatchString(expr) {
+		if varExpr, ok := vars[expr]; ok {
+			return evalExpr(varExpr, env, vars, depth+1)
+		}
+	}
+
+	return "", false
+}
+
+// evalEnvFetch evaluates ENV.fetch("NAME", default) and ENV.fetch("NAME") { default }
+func evalEnvFetch(expr string, env map[string]string, vars map[string]string, depth int) (string, bool) {
+	open := len("ENV.fetch")
+	closeIdx := matchingClose(expr, open)
+	if closeIdx < 0 {
+		return "", false
+	}
+
+	args := splitTopLevel(expr[open+1:closeIdx], ",")
+	key, ok := evalExpr(args[0], nil, nil, depth+1)
+	if !ok {
+		return "", false
+	}
+
+	if value, ok := env[key]; ok {
+		return value, true
+	}
+
+	// Default given as a second argument
+	if len(args) > 1 {
+		return evalExpr(args[1], env, vars, depth+1)
+	}
+
+	// Default given as a block
+	rest := strings.TrimSpace(expr[closeIdx+1:])
+	if strings.HasPrefix(rest, "{") && strings.HasSuffix(rest, "}") {
+		return evalExpr(rest[1:len(rest)-1], env, vars, depth+1)
+	}
+
+	// ENV.fetch without a default raises when the var is missing
+	return "", false
+}
+
+// evalArithmetic applies an integer operator to two operands
+func evalArithmetic(left string, op byte, right string, env map[string]string, vars map[string]string, depth int) (string, bool) {
+	leftValue, ok := evalExpr(left, env, vars, depth+1)
+	if !ok {
+		return "", false
+	}
+	rightValue, ok := evalExpr(right, env, vars, depth+1)
+	if !ok {
+		return "", false
+	}
+	a, errA := strconv.Atoi(strings.TrimSpace(leftValue))
+	b, errB := strconv.Atoi(strings.TrimSpace(rightValue))
+	if errA != nil || errB != nil {
+		return "", false
+	}
+
+	switch op {
+	case '+':
+		return strconv.Itoa(a + b), true
+	case '-':
+		return strconv.Itoa(a - b), true
+	case '*':
+		return strconv.Itoa(a * b), true
+	case '/':
+		if b == 0 {
+			return "", false
+		}
+		return strconv.Itoa(a / b), true
+	}
+	return "", false
+}
+
+// splitArithmetic splits expr at its last top-level binary operator among ops, so operators of
+// the same precedence associate to the left
+// Operators following another operator or at the start are signs, and ** is not arithmetic here
+func splitArithmetic(expr, ops string) (string, byte, string, bool) {
+	split := -1
+	depth := 0
+	var quote byte
+
+	for i := 0; i < len(expr); i++ {
+		ch := expr[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '(' || ch == '[' || ch == '{':
+			depth++
+		case ch == ')' || ch == ']' || ch == '}':
+			depth--
+		case depth == 0 && strings.IndexByte(ops, ch) >= 0:
+			if ch == '*' && (strings.HasPrefix(expr[i:], "**") || strings.HasSuffix(expr[:i], "*")) {
+				continue
+			}
+			previous := strings.TrimSpace(expr[:i])
+			if previous == "" || strings.ContainsAny(previous[len(previous)-1:], "+-*/|&(,=<>") {
+				continue
+			}
+			split = i
+		}
+	}
+
+	if split < 0 {
+		return "", 0, "", false
+	}
+	return expr[:split], expr[split], expr[split+1:], true
+}
+
+// unwrapExpr strips conversions and redundant parentheses that don't change the value
+func unwrapExpr(expr string) string {
+	for {
+		expr = strings.TrimSpace(expr)
+		before := expr
+
+		expr = strings.TrimSuffix(expr, ".to_i")
+		expr = strings.TrimSuffix(expr, ".to_s")
+
+		for _, wrapper := range []string{"Integer(", "("} {
+			if strings.HasPrefix(expr, wrapper) && matchingClose(expr, len(wrapper)-1) == len(expr)-1 {
+				expr = expr[len(wrapper) : len(expr)-1]
+			}
+		}
+
+		if expr == before {
+			return expr
+		}
+	}
+}
+
+// matchingClose returns the index of the bracket closing the one at open, or -1
+func matchingClose(s string, open int) int {
+	if open >= len(s) {
+		return -1
+	}
+
+	pairs := map[byte]byte{'(': ')', '[': ']', '{': '}'}
+	closer, ok := pairs[s[open]]
+	if !ok {
+		return -1
+	}
+
+	depth := 0
+	var quote byte
+	for i := open; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == s[open]:
+			depth++
+		case ch == closer:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTopLevel splits s on sep where it is not nested in brackets or quotes
+func splitTopLevel(s, sep string) []string {
+	parts := []string{}
+	depth := 0
+	var quote byte
+	start := 0
+
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '(' || ch == '[' || ch == '{':
+			depth++
+		case ch == ')' || ch == ']' || ch == '}':
+			depth--
+		case depth == 0 && strings.HasPrefix(s[i:], sep):
+			parts = append(parts, s[start:i])
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+// stripCallParens removes the parentheses around method call arguments: `(1, 5)` becomes `1, 5`
+func stripCallParens(args string) string {
+	args = strings.TrimSpace(args)
+	if strings.HasPrefix(args, "(") && matchingClose(args, 0) == len(args)-1 {
+		return strings.TrimSpace(args[1 : len(args)-1])
+	}
+	return args
+}
+
+// stripRubyComment removes a trailing # comment that is not inside a string
+func stripRubyComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
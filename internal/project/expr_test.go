@@ -0,0 +1,81 @@
+package project
+
+import "testing"
+
+func TestEvalInt(t *testing.T) {
+	tests := []struct {
+		name   string
+		expr   string
+		env    map[string]string
+		vars   map[string]string
+		want   int
+		wantOK bool
+	}{
+		{name: "literal", expr: "5", want: 5, wantOK: true},
+		{name: "negative literal", expr: "-1", want: -1, wantOK: true},
+		{name: "env index set", expr: `ENV["RAILS_MAX_THREADS"]`, env: map[string]string{"RAILS_MAX_THREADS": "8"}, want: 8, wantOK: true},
+		{name: "env index missing", expr: `ENV["RAILS_MAX_THREADS"]`},
+		{name: "fetch block default", expr: `ENV.fetch("RAILS_MAX_THREADS") { 5 }`, want: 5, wantOK: true},
+		{name: "fetch block set", expr: `ENV.fetch("RAILS_MAX_THREADS") { 5 }`, env: map[string]string{"RAILS_MAX_THREADS": "10"}, want: 10, wantOK: true},
+		{name: "fetch argument default", expr: `ENV.fetch('WEB_CONCURRENCY', 2)`, want: 2, wantOK: true},
+		{name: "fetch without default", expr: `ENV.fetch("WEB_CONCURRENCY")`},
+		{name: "fetch nested fallback", expr: `ENV.fetch("DB_POOL") { ENV["RAILS_MAX_THREADS"] || 5 }.to_i`, want: 5, wantOK: true},
+		{name: "fetch nested fallback set", expr: `ENV.fetch("DB_POOL") { ENV["RAILS_MAX_THREADS"] || 5 }.to_i`, env: map[string]string{"RAILS_MAX_THREADS": "7"}, want: 7, wantOK: true},
+		{name: "or fallback", expr: `Integer(ENV["WEB_CONCURRENCY"] || 2)`, want: 2, wantOK: true},
+		{name: "or first operand", expr: `Integer(ENV["WEB_CONCURRENCY"] || 2)`, env: map[string]string{"WEB_CONCURRENCY": "4"}, want: 4, wantOK: true},
+		{name: "or all nil", expr: `ENV["A"] || ENV["B"]`},
+		{name: "addition", expr: `ENV.fetch("RAILS_MAX_THREADS") { 5 }.to_i + 2`, want: 7, wantOK: true},
+		{name: "subtraction is left associative", expr: "10 - 3 - 2", want: 5, wantOK: true},
+		{name: "multiplication precedence", expr: "2 + 3 * 4", want: 14, wantOK: true},
+		{name: "parentheses", expr: "(2 + 3) * 4", want: 20, wantOK: true},
+		{name: "division", expr: `Integer(ENV.fetch("SIDEKIQ_CONCURRENCY", 10)) / 2`, want: 5, wantOK: true},
+		{name: "division by zero", expr: "10 / 0"},
+		{name: "minus a negative", expr: "5 - -1", want: 6, wantOK: true},
+		{name: "arithmetic in fallback", expr: `ENV["DB_POOL"] || 2 * 3`, want: 6, wantOK: true},
+		{name: "operator in string", expr: `ENV.fetch("A-B") { 3 }`, want: 3, wantOK: true},
+		{name: "variable", expr: "max_threads_count", vars: map[string]string{"max_threads_count": `ENV.fetch("RAILS_MAX_THREADS") { 5 }`}, want: 5, wantOK: true},
+		{name: "self-referencing variable", expr: "threads", vars: map[string]string{"threads": "threads"}},
+		{name: "non-integer", expr: `ENV["RAILS_ENV"]`, env: map[string]string{"RAILS_ENV": "production"}},
+		{name: "unsupported", expr: "Etc.nprocessors"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := EvalInt(tt.expr, tt.env, tt.vars)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("EvalInt(%q) = %d, %v; want %d, %v", tt.expr, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestReferencesEnv(t *testing.T) {
+	vars := map[string]string{
+		"max_threads_count": `ENV.fetch("RAILS_MAX_THREADS") { 5 }`,
+		"min_threads_count": "max_threads_count",
+		"workers_count":     `ENV["WEB_CONCURRENCY"] || 2`,
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		env  string
+		want bool
+	}{
+		{name: "direct double quotes", expr: `ENV.fetch("RAILS_MAX_THREADS") { 5 }`, env: "RAILS_MAX_THREADS", want: true},
+		{name: "direct single quotes", expr: `ENV['DB_POOL']`, env: "DB_POOL", want: true},
+		{name: "through a variable", expr: "max_threads_count", env: "RAILS_MAX_THREADS", want: true},
+		{name: "through a chain of variables", expr: "min_threads_count + 1", env: "RAILS_MAX_THREADS", want: true},
+		{name: "other variable", expr: "workers_count", env: "RAILS_MAX_THREADS"},
+		{name: "prefix of a variable name", expr: "max_threads_count_total", env: "RAILS_MAX_THREADS"},
+		{name: "literal", expr: "5", env: "RAILS_MAX_THREADS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ReferencesEnv(tt.expr, tt.env, vars); got != tt.want {
+				t.Errorf("ReferencesEnv(%q, %q) = %v; want %v", tt.expr, tt.env, got, tt.want)
+			}
+		})
+	}
+}
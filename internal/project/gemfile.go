@@ -0,0 +1,111 @@
+package project
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GemfileLockPath is the Bundler lockfile location relative to the project root
+const GemfileLockPath = "Gemfile.lock"
+
+// GemfileLock holds the resolved gems from a Bundler lockfile
+type GemfileLock struct {
+	// Gems maps each locked gem to its version (without platform suffix)
+	Gems map[string]string
+
+	// RubyVersion is the RUBY VERSION section ("" if absent)
+	RubyVersion string
+}
+
+var (
+	// Top-level specs are indented four spaces; their dependencies six
+	lockSpecRegex = regexp.MustCompile(`^    ([A-Za-z0-9_.-]+) \(([^)]+)\)$`)
+	lockRubyRegex = regexp.MustCompile(`^\s+ruby (\d+\.\d+(?:\.\d+)?)`)
+)
+
+// LoadGemfileLock parses Gemfile.lock under projectPath
+// It returns nil without error if the file does not exist
+func LoadGemfileLock(projectPath string) (*GemfileLock, error) {
+	path := filepath.Join(projectPath, GemfileLockPath)
+	if _, err := os.Stat(path); err != nil {
+		return nil, nil
+	}
+	return ParseGemfileLock(path)
+}
+
+// ParseGemfileLock parses the specs and Ruby version from a Bundler lockfile
+func ParseGemfileLock(path string) (*GemfileLock, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", GemfileLockPath, err)
+	}
+	defer file.Close()
+
+	lock := &GemfileLock{Gems: make(map[string]string)}
+	section := ""
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		// Section headers (GEM, GIT, PATH, PLATFORMS, RUBY VERSION, ...) are not indented
+		if !strings.HasPrefix(line, " ") {
+			section = line
+			continue
+		}
+
+		switch section {
+		case "GEM", "GIT", "PATH":
+			if matches := lockSpecRegex.FindStringSubmatch(line); matches != nil {
+				// Strip platform suffixes like "1.15.4-x86_64-linux"
+				version, _, _ := strings.Cut(matches[2], "-")
+				lock.Gems[matches[1]] = version
+			}
+		case "RUBY VERSION":
+			if matches := lockRubyRegex.FindStringSubmatch(line); matches != nil {
+				lock.RubyVersion = matches[1]
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", GemfileLockPath, err)
+	}
+
+	return lock, nil
+}
+
+// Has returns true if the gem is locked
+func (l *GemfileLock) Has(name string) bool {
+	if l == nil {
+		return false
+	}
+	_, ok := l.Gems[name]
+	return ok
+}
+
+// Version returns the locked version of a gem, or ""
+func (l *GemfileLock) Version(name string) string {
+	if l == nil {
+		return ""
+	}
+	return l.Gems[name]
+}
+
+// MajorVersion returns the major component of a version string, or 0 if it has none
+func MajorVersion(version string) int {
+	major, _, _ := strings.Cut(version, ".")
+	n, err := strconv.Atoi(major)
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,107 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testGemfileLock = `GIT
+  remote: https://github.com/example/audited.git
+  revision: 0123456789abcdef
+  specs:
+    audited (5.4.0)
+      activerecord (>= 5.2)
+
+GEM
+  remote: https://rubygems.org/
+  specs:
+    nokogiri (1.15.4-x86_64-linux)
+      racc (~> 1.4)
+    pg (1.5.4)
+    puma (6.4.0)
+      nio4r (~> 2.0)
+    rails (7.1.2)
+      railties (= 7.1.2)
+    railties (7.1.2)
+    sidekiq (7.2.0)
+      redis-client (>= 0.14.0)
+
+PLATFORMS
+  x86_64-linux
+
+DEPENDENCIES
+  pg
+  puma (>= 5.0)
+
+RUBY VERSION
+   ruby 3.2.2p53
+
+BUNDLED WITH
+   2.4.22
+`
+
+func TestParseGemfileLock(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, GemfileLockPath), []byte(testGemfileLock), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	lock, err := LoadGemfileLock(dir)
+	if err != nil {
+		t.Fatalf("LoadGemfileLock: %v", err)
+	}
+
+	want := map[string]string{
+		"audited":  "5.4.0",
+		"nokogiri": "1.15.4",
+		"pg":       "1.5.4",
+		"puma":     "6.4.0",
+		"rails":    "7.1.2",
+		"railties": "7.1.2",
+		"sidekiq":  "7.2.0",
+	}
+	if !reflect.DeepEqual(lock.Gems, want) {
+		t.Errorf("Gems = %v; want %v", lock.Gems, want)
+	}
+	if lock.RubyVersion != "3.2.2" {
+		t.Errorf("RubyVersion = %q; want 3.2.2", lock.RubyVersion)
+	}
+
+	// Dependencies of specs and the DEPENDENCIES section are not locked specs
+	for _, name := range []string{"racc", "redis-client", "activerecord"} {
+		if lock.Has(name) {
+			t.Errorf("Has(%q) = true; want false", name)
+		}
+	}
+}
+
+func TestGemfileLockMissing(t *testing.T) {
+	lock, err := LoadGemfileLock(t.TempDir())
+	if lock != nil || err != nil {
+		t.Fatalf("LoadGemfileLock() = %+v, %v; want nil, nil", lock, err)
+	}
+	if lock.Has("rails") || lock.Version("rails") != "" {
+		t.Error("a missing lockfile reports locked gems")
+	}
+}
+
+func TestMajorVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    int
+	}{
+		{version: "7.2.0", want: 7},
+		{version: "10", want: 10},
+		{version: "", want: 0},
+		{version: "main", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if got := MajorVersion(tt.version); got != tt.want {
+				t.Errorf("MajorVersion(%q) = %d; want %d", tt.version, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package project
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProcessKind classifies what a process type runs
+type ProcessKind string
+
+const (
+	KindWeb        ProcessKind = "web"
+	KindSidekiq    ProcessKind = "sidekiq"
+	KindGoodJob    ProcessKind = "good_job"
+	KindSolidQueue ProcessKind = "solid_queue"
+	KindResque     ProcessKind = "resque"
+	KindDelayedJob ProcessKind = "delayed_job"
+	KindClock      ProcessKind = "clock"
+	KindRake       ProcessKind = "rake"
+	KindRelease    ProcessKind = "release"
+	KindOther      ProcessKind = "other"
+)
+
+// ProcessType is a process type declared in the Procfile or heroku.yml
+type ProcessType struct {
+	Name    string
+	Command string
+	Kind    ProcessKind
+}
+
+// procfileLineRegex matches "name: command" lines
+var procfileLineRegex = regexp.MustCompile(`^([A-Za-z0-9_-]+):\s*(.+)$`)
+
+// LoadProcesses reads process types from the Procfile, falling back to heroku.yml
+// It returns the file the processes came from, or "" if neither exists
+func LoadProcesses(projectPath string) ([]ProcessType, string, error) {
+	procfilePath := filepath.Join(projectPath, "Procfile")
+	if _, err := os.Stat(procfilePath); err == nil {
+		processes, err := ParseProcfile(procfilePath)
+		return processes, "Procfile", err
+	}
+
+	herokuYMLPath := filepath.Join(projectPath, "heroku.yml")
+	if _, err := os.Stat(herokuYMLPath); err == nil {
+		processes, err := ParseHerokuYML(herokuYMLPath)
+		return processes, "heroku.yml", err
+	}
+
+	return nil, "", nil
+}
+
+// ParseProcfile parses a Procfile
+func ParseProcfile(path string) ([]ProcessType, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Procfile: %w", err)
+	}
+	defer file.Close()
+
+	processes := []ProcessType{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		matches := procfileLineRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+
+		processes = append(processes, ProcessType{
+			Name:    matches[1],
+			Command: strings.TrimSpace(matches[2]),
+			Kind:    ClassifyProcess(matches[1], matches[2]),
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Procfile: %w", err)
+	}
+
+	return processes, nil
+}
+
+// ParseHerokuYML parses the run section of a heroku.yml container manifest
+func ParseHerokuYML(path string) ([]ProcessType, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read heroku.yml: %w", err)
+	}
+
+	var manifest struct {
+		Run map[string]yaml.Node `yaml:"run"`
+	}
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse heroku.yml: %w", err)
+	}
+
+	processes := []ProcessType{}
+	for name, node := range manifest.Run {
+		command := herokuYMLCommand(&node)
+		processes = append(processes, ProcessType{
+			Name:    name,
+			Command: command,
+			Kind:    ClassifyProcess(name, command),
+		})
+	}
+
+	// Map iteration order is random; keep output stable
+	sort.Slice(processes, func(i, j int) bool {
+		return processes[i].Name < processes[j].Name
+	})
+
+	return processes, nil
+}
+
+// herokuYMLCommand extracts the command from a run entry, which is either a
+// string or a mapping with a command string or list
+func herokuYMLCommand(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value
+	case yaml.MappingNode:
+		var entry struct {
+			Command yaml.Node `yaml:"command"`
+		}
+		if err := node.Decode(&entry); err != nil {
+			return ""
+		}
+		if entry.Command.Kind == yaml.SequenceNode {
+			var parts []string
+			if err := entry.Command.Decode(&parts); err == nil {
+				return strings.Join(parts, " ")
+			}
+			return ""
+		}
+		return entry.Command.Value
+	}
+	return ""
+}
+
+// ClassifyProcess determines the kind of a process type from its command,
+// falling back to its name when the command is unknown
+// Heroku routes requests only to the web process type, and a web server command is checked before
+// job backends, so a web process that also starts a worker (puma ... & sidekiq) stays web
+func ClassifyProcess(name, command string) ProcessKind {
+	switch name {
+	case "release":
+		return KindRelease
+	case "web":
+		return KindWeb
+	}
+
+	cmd := strings.ToLower(command)
+	switch {
+	case strings.Contains(cmd, "puma") || strings.Contains(cmd, "rails server") ||
+		strings.Contains(cmd, "rails s ") || strings.HasSuffix(cmd, "rails s") ||
+		strings.Contains(cmd, "unicorn") || strings.Contains(cmd, "passenger") ||
+		strings.Contains(cmd, "falcon"):
+		return KindWeb
+	case strings.Contains(cmd, "sidekiq"):
+		return KindSidekiq
+	case strings.Contains(cmd, "good_job") || strings.Contains(cmd, "goodjob"):
+		return KindGoodJob
+	case strings.Contains(cmd, "solid_queue") || strings.Contains(cmd, "bin/jobs"):
+		return KindSolidQueue
+	case strings.Contains(cmd, "resque"):
+		return KindResque
+	case strings.Contains(cmd, "jobs:work") || strings.Contains(cmd, "delayed_job"):
+		return KindDelayedJob
+	case strings.Contains(cmd, "clock"):
+		return KindClock
+	case strings.Contains(cmd, "rake "):
+		return KindRake
+	}
+
+	// Fall back to conventional process type names
+	lowerName := strings.ToLower(name)
+	switch {
+	case strings.Contains(lowerName, "sidekiq"):
+		return KindSidekiq
+	case lowerName == "worker" && cmd == "":
+		// Without a command, assume the conventional Sidekiq worker
+		return KindSidekiq
+	case strings.Contains(lowerName, "clock") || strings.Contains(lowerName, "scheduler"):
+		return KindClock
+	}
+
+	return KindOther
+}
+
+// IsJobWorker returns true for process kinds that run background jobs
+func (k ProcessKind) IsJobWorker() bool {
+	switch k {
+	case KindSidekiq, KindGoodJob, KindSolidQueue, KindResque, KindDelayedJob:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestClassifyProcess(t *testing.T) {
+	tests := []struct {
+		name    string
+		process string
+		command string
+		want    ProcessKind
+	}{
+		{name: "puma", process: "web", command: "bundle exec puma -C config/puma.rb", want: KindWeb},
+		{name: "web starting Sidekiq too", process: "web", command: "bundle exec puma -C config/puma.rb & bundle exec sidekiq", want: KindWeb},
+		{name: "web by name", process: "web", command: "bin/start-nginx bin/start-pgbouncer ./bin/server", want: KindWeb},
+		{name: "web server under another name", process: "api", command: "bundle exec rails server -p $PORT", want: KindWeb},
+		{name: "web server with an embedded worker under another name", process: "combined", command: "bundle exec sidekiq & bundle exec puma", want: KindWeb},
+		{name: "rails s", process: "admin", command: "bin/rails s", want: KindWeb},
+		{name: "sidekiq", process: "worker", command: "bundle exec sidekiq -C config/sidekiq.yml", want: KindSidekiq},
+		{name: "good_job", process: "worker", command: "bundle exec good_job start", want: KindGoodJob},
+		{name: "solid_queue", process: "jobs", command: "bin/jobs", want: KindSolidQueue},
+		{name: "resque", process: "worker", command: "QUEUE=* bundle exec rake resque:work", want: KindResque},
+		{name: "delayed_job", process: "worker", command: "bundle exec rake jobs:work", want: KindDelayedJob},
+		{name: "clock", process: "clock", command: "bundle exec clockwork config/clock.rb", want: KindClock},
+		{name: "rake", process: "migrate", command: "bundle exec rake db:migrate", want: KindRake},
+		{name: "release phase", process: "release", command: "bundle exec rails db:migrate", want: KindRelease},
+		{name: "sidekiq by name", process: "sidekiq_default", command: "./bin/run-queues", want: KindSidekiq},
+		{name: "worker without a command", process: "worker", want: KindSidekiq},
+		{name: "scheduler by name", process: "scheduler", command: "./bin/tick", want: KindClock},
+		{name: "unknown", process: "metrics", command: "./bin/exporter", want: KindOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyProcess(tt.process, tt.command); got != tt.want {
+				t.Errorf("ClassifyProcess(%q, %q) = %s; want %s", tt.process, tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadProcesses(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      map[string]string
+		wantSource string
+		want       []ProcessType
+		wantErr    bool
+	}{
+		{
+			name: "Procfile",
+			files: map[string]string{"Procfile": "# Deployed by Heroku\n" +
+				"web: bundle exec puma -C config/puma.rb\n" +
+				"\n" +
+				"worker:   bundle exec sidekiq -C config/sidekiq.yml  \n" +
+				"release: bin/rails db:migrate\n" +
+				"not a process line\n"},
+			wantSource: "Procfile",
+			want: []ProcessType{
+				{Name: "web", Command: "bundle exec puma -C config/puma.rb", Kind: KindWeb},
+				{Name: "worker", Command: "bundle exec sidekiq -C config/sidekiq.yml", Kind: KindSidekiq},
+				{Name: "release", Command: "bin/rails db:migrate", Kind: KindRelease},
+			},
+		},
+		{
+			name: "Procfile wins over heroku.yml",
+			files: map[string]string{
+				"Procfile":   "web: bin/rails server\n",
+				"heroku.yml": "run:\n  web: bundle exec puma\n",
+			},
+			wantSource: "Procfile",
+			want:       []ProcessType{{Name: "web", Command: "bin/rails server", Kind: KindWeb}},
+		},
+		{
+			name: "heroku.yml",
+			files: map[string]string{"heroku.yml": "build:\n  docker:\n    web: Dockerfile\n" +
+				"run:\n" +
+				"  web: bundle exec puma -C config/puma.rb\n" +
+				"  worker:\n" +
+				"    command:\n" +
+				"      - bundle exec good_job start\n" +
+				"    image: web\n" +
+				"  clock:\n" +
+				"    command: bundle exec clockwork config/clock.rb\n"},
+			wantSource: "heroku.yml",
+			want: []ProcessType{
+				{Name: "clock", Command: "bundle exec clockwork config/clock.rb", Kind: KindClock},
+				{Name: "web", Command: "bundle exec puma -C config/puma.rb", Kind: KindWeb},
+				{Name: "worker", Command: "bundle exec good_job start", Kind: KindGoodJob},
+			},
+		},
+		{
+			name:       "invalid heroku.yml",
+			files:      map[string]string{"heroku.yml": "run: [web\n"},
+			wantSource: "heroku.yml",
+			wantErr:    true,
+		},
+		{
+			name: "neither",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, source, err := LoadProcesses(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadProcesses() error = %v; wantErr %v", err, tt.wantErr)
+			}
+			if source != tt.wantSource {
+				t.Errorf("source = %q; want %q", source, tt.wantSource)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadProcesses() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
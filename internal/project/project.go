@@ -0,0 +1,143 @@
+package project
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+)
+
+// Project holds configuration parsed statically from a Rails project directory
+type Project struct {
+	// Path is the project root
+	Path string
+
+	// Processes are the process types declared in the Procfile or heroku.yml
+	Processes []ProcessType
+
+	// ProcessFile is the file the process types were read from ("" if none)
+	ProcessFile string
+
+	// Puma is the parsed config/puma.rb (nil if absent)
+	Puma *PumaConfig
+
+	// Database is the parsed config/database.yml (nil if absent)
+	Database *DatabaseConfig
+
+	// Sidekiq maps each parsed Sidekiq config file (config/sidekiq.yml and -C arguments) to its contents
+	Sidekiq map[string]*SidekiqConfig
+
+	// GemfileLock is the parsed Gemfile.lock (nil if absent)
+	GemfileLock *GemfileLock
+
+	// CableAdapters maps each environment to its Action Cable adapter from config/cable.yml
+	CableAdapters map[string]string
+
+	// CableURLEnvs maps each environment to the env var holding Action Cable's Redis URL
+	CableURLEnvs map[string]string
+
+	// CacheStores maps each environment to the cache store set in config/environments/*.rb
+	CacheStores map[string]string
+
+	// CacheURLEnvs maps each environment to the env var holding the cache store's URL
+	CacheURLEnvs map[string]string
+
+	// Warnings are problems encountered while parsing project files
+	Warnings []string
+}
+
+// Load parses the supported configuration files under projectPath
+// Missing files are not errors; parse failures are recorded as warnings
+func Load(projectPath string) *Project {
+	p := &Project{Path: projectPath}
+
+	processes, file, err := LoadProcesses(projectPath)
+	if err != nil {
+		p.Warnings = append(p.Warnings, err.Error())
+	}
+	p.Processes = processes
+	p.ProcessFile = file
+
+	puma, err := LoadPumaConfig(projectPath)
+	if err != nil {
+		p.Warnings = append(p.Warnings, err.Error())
+	}
+	p.Puma = puma
+
+	database, err := LoadDatabaseConfig(projectPath)
+	if err != nil {
+		p.Warnings = append(p.Warnings, err.Error())
+	}
+	p.Database = database
+
+	lock, err := LoadGemfileLock(projectPath)
+	if err != nil {
+		p.Warnings = append(p.Warnings, err.Error())
+	}
+	p.GemfileLock = lock
+
+	p.CableAdapters, p.CableURLEnvs, err = LoadCableAdapters(projectPath)
+	if err != nil {
+		p.Warnings = append(p.Warnings, err.Error())
+	}
+	p.CacheStores, p.CacheURLEnvs = LoadCacheStores(projectPath)
+
+	p.Sidekiq = make(map[string]*SidekiqConfig)
+	for _, path := range p.sidekiqConfigPaths() {
+		sidekiq, err := LoadSidekiqConfig(projectPath, path)
+		if err != nil {
+			p.Warnings = append(p.Warnings, err.Error())
+		}
+		if sidekiq == nil {
+			if path != SidekiqConfigPath && err == nil {
+				p.Warnings = append(p.Warnings, fmt.Sprintf("%s: sidekiq config %s not found", p.ProcessFile, path))
+			}
+			continue
+		}
+		p.Sidekiq[path] = sidekiq
+	}
+
+	return p
+}
+
+// sidekiqConfigPaths returns the default Sidekiq config plus any passed with -C in process commands
+func (p *Project) sidekiqConfigPaths() []string {
+	paths := []string{SidekiqConfigPath}
+	for _, process := range p.Processes {
+		if process.Kind != KindSidekiq {
+			continue
+		}
+		path := ParseSidekiqArgs(process.Command).ConfigFile
+		if path != "" && !slices.Contains(paths, filepath.Clean(path)) {
+			paths = append(paths, filepath.Clean(path))
+		}
+	}
+	return paths
+}
+
+// SidekiqConfigFor returns the Sidekiq config a process type loads, or nil
+// Sidekiq reads config/sidekiq.yml unless the command passes -C
+func (p *Project) SidekiqConfigFor(process *ProcessType) *SidekiqConfig {
+	if p == nil {
+		return nil
+	}
+	path := SidekiqConfigPath
+	if process != nil {
+		if configFile := ParseSidekiqArgs(process.Command).ConfigFile; configFile != "" {
+			path = filepath.Clean(configFile)
+		}
+	}
+	return p.Sidekiq[path]
+}
+
+// Process returns the declared process type with the given name, or nil
+func (p *Project) Process(name string) *ProcessType {
+	if p == nil {
+		return nil
+	}
+	for i := range p.Processes {
+		if p.Processes[i].Name == name {
+			return &p.Processes[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package project
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// PumaConfigPath is the conventional Puma config location relative to the project root
+const PumaConfigPath = "config/puma.rb"
+
+// PumaConfig holds the concurrency directives found in config/puma.rb
+// Expressions are kept unevaluated so they can be resolved against the app's env vars
+type PumaConfig struct {
+	// WorkersExpr is the argument to `workers` ("" if there is no workers directive)
+	WorkersExpr string
+
+	// MinThreadsExpr and MaxThreadsExpr are the arguments to `threads` ("" if absent)
+	MinThreadsExpr string
+	MaxThreadsExpr string
+
+	// PreloadApp is true when preload_app! is present
+	PreloadApp bool
+
+	// Variables are local variable assignments, used to resolve directive arguments
+	Variables map[string]string
+}
+
+var (
+	rubyAssignmentRegex = regexp.MustCompile(`^([a-z_][A-Za-z0-9_]*)\s*=\s*(.+)$`)
+	pumaWorkersRegex    = regexp.MustCompile(`^workers\b\s*(.+)$`)
+	pumaThreadsRegex    = regexp.MustCompile(`^threads\b\s*(.+)$`)
+	rubyModifierRegex   = regexp.MustCompile(`\s+(if|unless)\s+.*$`)
+)
+
+// LoadPumaConfig parses config/puma.rb under projectPath
+// It returns nil without error if the file does not exist
+func LoadPumaConfig(projectPath string) (*PumaConfig, error) {
+	path := filepath.Join(projectPath, PumaConfigPath)
+	if _, err := os.Stat(path); err != nil {
+		return nil, nil
+	}
+	return ParsePumaConfig(path)
+}
+
+// ParsePumaConfig statically parses the common idioms in a Puma config file
+func ParsePumaConfig(path string) (*PumaConfig, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", PumaConfigPath, err)
+	}
+	defer file.Close()
+
+	cfg := &PumaConfig{Variables: make(map[string]string)}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(stripRubyComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		// `workers n if n > 1` - the modifier doesn't change the value we care about
+		line = rubyModifierRegex.ReplaceAllString(line, "")
+
+		switch {
+		case line == "preload_app!":
+			cfg.PreloadApp = true
+		case pumaWorkersRegex.MatchString(line):
+			cfg.WorkersExpr = stripCallParens(pumaWorkersRegex.FindStringSubmatch(line)[1])
+		case pumaThreadsRegex.MatchString(line):
+			args := splitTopLevel(stripCallParens(pumaThreadsRegex.FindStringSubmatch(line)[1]), ",")
+			if len(args) == 2 {
+				cfg.MinThreadsExpr = strings.TrimSpace(args[0])
+				cfg.MaxThreadsExpr = strings.TrimSpace(args[1])
+			}
+		case rubyAssignmentRegex.MatchString(line):
+			matches := rubyAssignmentRegex.FindStringSubmatch(line)
+			cfg.Variables[matches[1]] = strings.TrimSpace(matches[2])
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", PumaConfigPath, err)
+	}
+
+	return cfg, nil
+}
+
+// Workers evaluates the worker count against env
+// Without a workers directive Puma reads WEB_CONCURRENCY itself and runs in single mode (0) when unset
+func (c *PumaConfig) Workers(env map[string]string) (int, bool) {
+	if c.WorkersExpr == "" {
+		return EvalInt(`ENV.fetch("WEB_CONCURRENCY") { 0 }`, env, nil)
+	}
+	return EvalInt(c.WorkersExpr, env, c.Variables)
+}
+
+// MaxThreads evaluates the maximum thread count against env
+func (c *PumaConfig) MaxThreads(env map[string]string) (int, bool) {
+	if c.MaxThreadsExpr == "" {
+		return 0, false
+	}
+	return EvalInt(c.MaxThreadsExpr, env, c.Variables)
+}
+
+// ReadsEnv returns true if the workers or threads directive can be changed through the named env var
+func (c *PumaConfig) ReadsEnv(name string) bool {
+	if name == "WEB_CONCURRENCY" && c.WorkersExpr == "" {
+		return true
+	}
+	return ReferencesEnv(c.WorkersExpr, name, c.Variables) ||
+		ReferencesEnv(c.MaxThreadsExpr, name, c.Variables)
+}
@@ -0,0 +1,97 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParsePumaConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		puma        string
+		env         map[string]string
+		wantWorkers int
+		wantThreads int
+		wantOK      bool
+		wantPreload bool
+		wantReads   []string
+	}{
+		{
+			name: "Rails default",
+			puma: "# Puma can serve each request in a thread from an internal thread pool.\n" +
+				"max_threads_count = ENV.fetch(\"RAILS_MAX_THREADS\") { 5 }\n" +
+				"min_threads_count = ENV.fetch(\"RAILS_MIN_THREADS\") { max_threads_count }\n" +
+				"threads min_threads_count, max_threads_count\n" +
+				"\n" +
+				"workers ENV.fetch(\"WEB_CONCURRENCY\") { 2 }\n" +
+				"preload_app!\n",
+			env:         map[string]string{"WEB_CONCURRENCY": "3"},
+			wantWorkers: 3,
+			wantThreads: 5,
+			wantOK:      true,
+			wantPreload: true,
+			wantReads:   []string{"WEB_CONCURRENCY", "RAILS_MAX_THREADS"},
+		},
+		{
+			name:        "literal values with parens and a modifier",
+			puma:        "threads(1, 8)\nworkers(4) if ENV[\"RACK_ENV\"] == \"production\" # cluster mode\n",
+			wantWorkers: 4,
+			wantThreads: 8,
+			wantOK:      true,
+		},
+		{
+			name:        "Rails 7.1 default without workers",
+			puma:        "threads_count = ENV.fetch(\"RAILS_MAX_THREADS\", 3)\nthreads threads_count, threads_count\n",
+			env:         map[string]string{"RAILS_MAX_THREADS": "6"},
+			wantWorkers: 0,
+			wantThreads: 6,
+			wantOK:      true,
+			wantReads:   []string{"WEB_CONCURRENCY", "RAILS_MAX_THREADS"},
+		},
+		{
+			name:        "no threads directive",
+			puma:        "workers 2\n",
+			wantWorkers: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, PumaConfigPath)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(tt.puma), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := LoadPumaConfig(dir)
+			if err != nil {
+				t.Fatalf("LoadPumaConfig: %v", err)
+			}
+
+			if got, ok := cfg.Workers(tt.env); got != tt.wantWorkers || !ok {
+				t.Errorf("Workers() = %d, %v; want %d, true", got, ok, tt.wantWorkers)
+			}
+			if got, ok := cfg.MaxThreads(tt.env); got != tt.wantThreads || ok != tt.wantOK {
+				t.Errorf("MaxThreads() = %d, %v; want %d, %v", got, ok, tt.wantThreads, tt.wantOK)
+			}
+			if cfg.PreloadApp != tt.wantPreload {
+				t.Errorf("PreloadApp = %v; want %v", cfg.PreloadApp, tt.wantPreload)
+			}
+			for _, name := range tt.wantReads {
+				if !cfg.ReadsEnv(name) {
+					t.Errorf("ReadsEnv(%q) = false; want true", name)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadPumaConfigMissing(t *testing.T) {
+	cfg, err := LoadPumaConfig(t.TempDir())
+	if cfg != nil || err != nil {
+		t.Errorf("LoadPumaConfig() = %+v, %v; want nil, nil", cfg, err)
+	}
+}
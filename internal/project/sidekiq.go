@@ -0,0 +1,258 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SidekiqConfigPath is the default Sidekiq config location relative to the project root
+const SidekiqConfigPath = "config/sidekiq.yml"
+
+// SidekiqConfig holds the settings found in a Sidekiq config file
+// Expressions are kept unevaluated so they can be resolved against the app's env vars
+type SidekiqConfig struct {
+	// Path is the config file relative to the project root
+	Path string
+
+	// ConcurrencyExpr is the :concurrency: setting as a Ruby expression ("" if not set)
+	ConcurrencyExpr string
+
+	// Queues are the queue names processed by the default capsule
+	Queues []string
+
+	// Capsules are additional Sidekiq 7 capsules, each with its own threads
+	Capsules []SidekiqCapsule
+
+	// Environments holds per-environment overrides (e.g. a production: section)
+	Environments map[string]*SidekiqConfig
+}
+
+// SidekiqCapsule is a Sidekiq 7 capsule declared under :capsules:
+type SidekiqCapsule struct {
+	Name            string
+	ConcurrencyExpr string
+	Queues          []string
+}
+
+// SidekiqArgs are the options passed to sidekiq on its command line
+type SidekiqArgs struct {
+	// Concurrency is the -c/--concurrency argument ("" if absent)
+	Concurrency string
+
+	// ConfigFile is the -C/--config argument ("" if absent)
+	ConfigFile string
+
+	// Environment is the -e/--environment argument ("" if absent)
+	Environment string
+
+	// Queues are the -q/--queue arguments
+	Queues []string
+}
+
+// shellVarRegex matches $NAME, ${NAME} and ${NAME:-default}
+var shellVarRegex = regexp.MustCompile(`^\$\{?([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}?$`)
+
+// ParseSidekiqArgs extracts the sidekiq options from a process command
+func ParseSidekiqArgs(command string) SidekiqArgs {
+	args := SidekiqArgs{}
+	fields := strings.Fields(command)
+
+	for i := 0; i < len(fields); i++ {
+		flag, value, hasValue := strings.Cut(fields[i], "=")
+		if !hasValue {
+			if i+1 >= len(fields) {
+				break
+			}
+			value = fields[i+1]
+		}
+
+		switch flag {
+		case "-c", "--concurrency":
+			args.Concurrency = value
+		case "-C", "--config":
+			args.ConfigFile = value
+		case "-e", "--environment":
+			args.Environment = value
+		case "-q", "--queue":
+			// Weighted queues are written "name,weight"
+			name, _, _ := strings.Cut(value, ",")
+			args.Queues = append(args.Queues, name)
+		default:
+			continue
+		}
+
+		if !hasValue {
+			i++
+		}
+	}
+
+	return args
+}
+
+// ConcurrencyValue evaluates the -c argument, expanding shell variables against env
+// The boolean result is false when there is no -c argument or it cannot be interpreted
+func (a SidekiqArgs) ConcurrencyValue(env map[string]string) (int, bool) {
+	value := strings.Trim(a.Concurrency, `"'`)
+	if value == "" {
+		return 0, false
+	}
+
+	if matches := shellVarRegex.FindStringSubmatch(value); matches != nil {
+		if envValue, ok := env[matches[1]]; ok && envValue != "" {
+			value = envValue
+		} else {
+			value = matches[2]
+		}
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// LoadSidekiqConfig parses a Sidekiq config file under projectPath
+// It returns nil without error if the file does not exist
+func LoadSidekiqConfig(projectPath, relPath string) (*SidekiqConfig, error) {
+	path := filepath.Join(projectPath, relPath)
+	if _, err := os.Stat(path); err != nil {
+		return nil, nil
+	}
+	return ParseSidekiqConfig(path, relPath)
+}
+
+// ParseSidekiqConfig parses a Sidekiq config file, which Sidekiq renders through ERB
+func ParseSidekiqConfig(path, relPath string) (*SidekiqConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", relPath, err)
+	}
+
+	doc, err := parseERBYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", relPath, err)
+	}
+
+	cfg := newSidekiqConfig(doc.Root, doc)
+	cfg.Path = relPath
+
+	// Any other top-level hash (production:, :staging:, ...) overrides settings for that environment
+	cfg.Environments = make(map[string]*SidekiqConfig)
+	for key, value := range doc.Root {
+		settings, ok := value.(map[string]interface{})
+		env := strings.TrimPrefix(key, ":")
+		if !ok || env == "capsules" {
+			continue
+		}
+		cfg.Environments[env] = newSidekiqConfig(settings, doc)
+	}
+
+	return cfg, nil
+}
+
+// newSidekiqConfig reads the settings of one level of a Sidekiq config
+// Keys are usually written as Ruby symbols (":concurrency:") but plain keys also work
+func newSidekiqConfig(settings map[string]interface{}, doc *erbYAML) *SidekiqConfig {
+	cfg := &SidekiqConfig{
+		ConcurrencyExpr: doc.ValueExpr(sidekiqSetting(settings, "concurrency")),
+		Queues:          sidekiqQueues(sidekiqSetting(settings, "queues")),
+	}
+
+	if capsules, ok := sidekiqSetting(settings, "capsules").(map[string]interface{}); ok {
+		for name, value := range capsules {
+			capsule, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			cfg.Capsules = append(cfg.Capsules, SidekiqCapsule{
+				Name:            strings.TrimPrefix(name, ":"),
+				ConcurrencyExpr: doc.ValueExpr(sidekiqSetting(capsule, "concurrency")),
+				Queues:          sidekiqQueues(sidekiqSetting(capsule, "queues")),
+			})
+		}
+		// Map iteration order is random; keep output stable
+		sort.Slice(cfg.Capsules, func(i, j int) bool {
+			return cfg.Capsules[i].Name < cfg.Capsules[j].Name
+		})
+	}
+
+	return cfg
+}
+
+// sidekiqSetting looks up a setting by its symbol or plain key
+func sidekiqSetting(settings map[string]interface{}, name string) interface{} {
+	if value, ok := settings[":"+name]; ok {
+		return value
+	}
+	return settings[name]
+}
+
+// sidekiqQueues reads a queue list, where each entry is a name or a [name, weight] pair
+func sidekiqQueues(value interface{}) []string {
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	queues := []string{}
+	for _, item := range list {
+		switch q := item.(type) {
+		case string:
+			queues = append(queues, q)
+		case []interface{}:
+			if len(q) > 0 {
+				queues = append(queues, fmt.Sprint(q[0]))
+			}
+		}
+	}
+	return queues
+}
+
+// ForEnv returns the config with the overrides for env applied
+func (c *SidekiqConfig) ForEnv(env string) *SidekiqConfig {
+	merged := *c
+	override, ok := c.Environments[env]
+	if !ok {
+		return &merged
+	}
+
+	if override.ConcurrencyExpr != "" {
+		merged.ConcurrencyExpr = override.ConcurrencyExpr
+	}
+	if override.Queues != nil {
+		merged.Queues = override.Queues
+	}
+	if override.Capsules != nil {
+		merged.Capsules = override.Capsules
+	}
+	return &merged
+}
+
+// Concurrency evaluates the default capsule's thread count against env
+// The boolean result is false when concurrency is not set or cannot be interpreted
+func (c *SidekiqConfig) Concurrency(env map[string]string) (int, bool) {
+	if c.ConcurrencyExpr == "" {
+		return 0, false
+	}
+	return EvalInt(c.ConcurrencyExpr, env, nil)
+}
+
+// CapsuleThreads evaluates the total threads of the additional capsules against env
+// Capsules without a concurrency setting inherit the default capsule's concurrency
+func (c *SidekiqConfig) CapsuleThreads(env map[string]string, defaultConcurrency int) int {
+	total := 0
+	for _, capsule := range c.Capsules {
+		threads, ok := EvalInt(capsule.ConcurrencyExpr, env, nil)
+		if !ok {
+			threads = defaultConcurrency
+		}
+		total += threads
+	}
+	return total
+}
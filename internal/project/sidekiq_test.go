@@ -0,0 +1,157 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSidekiqConfigForEnv(t *testing.T) {
+	tests := []struct {
+		name      string
+		yaml      string
+		env       string
+		vars      map[string]string
+		want      int
+		wantOK    bool
+		wantQueue []string
+	}{
+		{
+			name:      "base section",
+			yaml:      ":concurrency: 5\n:queues:\n  - default\n",
+			env:       "production",
+			want:      5,
+			wantOK:    true,
+			wantQueue: []string{"default"},
+		},
+		{
+			name:      "plain environment key",
+			yaml:      ":concurrency: 5\nproduction:\n  :concurrency: 20\n",
+			env:       "production",
+			want:      20,
+			wantOK:    true,
+			wantQueue: nil,
+		},
+		{
+			name:   "symbol environment key",
+			yaml:   ":concurrency: 5\n:production:\n  :concurrency: 20\n  :queues:\n    - critical\n    - [default, 2]\n",
+			env:    "production",
+			want:   20,
+			wantOK: true,
+			wantQueue: []string{
+				"critical", "default",
+			},
+		},
+		{
+			name:   "other environment",
+			yaml:   ":concurrency: 5\n:staging:\n  :concurrency: 2\n",
+			env:    "production",
+			want:   5,
+			wantOK: true,
+		},
+		{
+			name:   "ERB concurrency",
+			yaml:   ":concurrency: <%= ENV.fetch(\"SIDEKIQ_CONCURRENCY\") { 10 } %>\n",
+			env:    "production",
+			vars:   map[string]string{"SIDEKIQ_CONCURRENCY": "12"},
+			want:   12,
+			wantOK: true,
+		},
+		{
+			name: "no concurrency",
+			yaml: ":queues:\n  - default\n",
+			env:  "production",
+			wantQueue: []string{
+				"default",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "sidekiq.yml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := ParseSidekiqConfig(path, SidekiqConfigPath)
+			if err != nil {
+				t.Fatalf("ParseSidekiqConfig: %v", err)
+			}
+
+			merged := cfg.ForEnv(tt.env)
+			got, ok := merged.Concurrency(tt.vars)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Concurrency() = %d, %v; want %d, %v", got, ok, tt.want, tt.wantOK)
+			}
+			if tt.wantQueue != nil && !equalStrings(merged.Queues, tt.wantQueue) {
+				t.Errorf("Queues = %v; want %v", merged.Queues, tt.wantQueue)
+			}
+		})
+	}
+}
+
+func TestSidekiqConfigCapsules(t *testing.T) {
+	yaml := ":concurrency: 5\n:capsules:\n  :single:\n    :concurrency: 1\n    :queues:\n      - serial\n  :inherit:\n    :queues:\n      - bulk\n"
+	path := filepath.Join(t.TempDir(), "sidekiq.yml")
+	if err := os.WriteFile(path, []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := ParseSidekiqConfig(path, SidekiqConfigPath)
+	if err != nil {
+		t.Fatalf("ParseSidekiqConfig: %v", err)
+	}
+
+	if _, ok := cfg.Environments["capsules"]; ok {
+		t.Error("capsules parsed as an environment")
+	}
+	if len(cfg.Capsules) != 2 || cfg.Capsules[0].Name != "inherit" || cfg.Capsules[1].Name != "single" {
+		t.Fatalf("Capsules = %+v; want inherit and single", cfg.Capsules)
+	}
+	if got := cfg.CapsuleThreads(nil, 5); got != 6 {
+		t.Errorf("CapsuleThreads() = %d; want 6", got)
+	}
+}
+
+func TestParseSidekiqArgs(t *testing.T) {
+	tests := []struct {
+		command     string
+		env         map[string]string
+		wantThreads int
+		wantOK      bool
+		wantConfig  string
+		wantQueues  []string
+	}{
+		{command: "bundle exec sidekiq -c 10 -q critical,2 -q default", wantThreads: 10, wantOK: true, wantQueues: []string{"critical", "default"}},
+		{command: "bundle exec sidekiq --concurrency=$SIDEKIQ_THREADS", env: map[string]string{"SIDEKIQ_THREADS": "8"}, wantThreads: 8, wantOK: true},
+		{command: "bundle exec sidekiq -c ${SIDEKIQ_THREADS:-4}", wantThreads: 4, wantOK: true},
+		{command: "bundle exec sidekiq -C config/sidekiq_worker.yml", wantConfig: "config/sidekiq_worker.yml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			args := ParseSidekiqArgs(tt.command)
+			got, ok := args.ConcurrencyValue(tt.env)
+			if got != tt.wantThreads || ok != tt.wantOK {
+				t.Errorf("ConcurrencyValue() = %d, %v; want %d, %v", got, ok, tt.wantThreads, tt.wantOK)
+			}
+			if args.ConfigFile != tt.wantConfig {
+				t.Errorf("ConfigFile = %q; want %q", args.ConfigFile, tt.wantConfig)
+			}
+			if tt.wantQueues != nil && !equalStrings(args.Queues, tt.wantQueues) {
+				t.Errorf("Queues = %v; want %v", args.Queues, tt.wantQueues)
+			}
+		})
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
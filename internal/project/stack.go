@@ -0,0 +1,181 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+// CableConfigPath is the Action Cable config location relative to the project root
+const CableConfigPath = "config/cable.yml"
+
+var (
+	// Gems that identify the web server, in order of preference
+	webServerGems = []string{"puma", "unicorn", "passenger", "falcon"}
+
+	// Gems that identify each job backend
+	jobBackendGems = []struct {
+		Kind ProcessKind
+		Gem  string
+	}{
+		{KindSidekiq, "sidekiq"},
+		{KindGoodJob, "good_job"},
+		{KindSolidQueue, "solid_queue"},
+		{KindResque, "resque"},
+		{KindDelayedJob, "delayed_job"},
+	}
+
+	// Gems that open Redis connections
+	redisGems = []string{"redis", "redis-client", "hiredis", "redis-store", "redis-rails", "redis-namespace", "kredis", "sidekiq", "resque"}
+
+	cacheStoreRegex = regexp.MustCompile(`config\.cache_store\s*=\s*\[?\s*:(\w+)`)
+)
+
+// LoadCableAdapters reads the Action Cable adapter per environment from config/cable.yml,
+// along with the env var its url setting reads
+// It returns nil without error if the file does not exist
+func LoadCableAdapters(projectPath string) (adapters, urlEnvs map[string]string, err error) {
+	path := filepath.Join(projectPath, CableConfigPath)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, nil
+	}
+
+	doc, err := parseERBYAML(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", CableConfigPath, err)
+	}
+
+	adapters = make(map[string]string)
+	urlEnvs = make(map[string]string)
+	for env, value := range doc.Root {
+		settings, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		// ERB adapters can't be resolved statically; only literal names are recorded
+		if adapter, ok := settings["adapter"].(string); ok && !erbPlaceholderRegex.MatchString(adapter) {
+			adapters[env] = adapter
+		}
+		if matches := envNameRegex.FindStringSubmatch(doc.ValueExpr(settings["url"])); matches != nil {
+			urlEnvs[env] = matches[1]
+		}
+	}
+	return adapters, urlEnvs, nil
+}
+
+// LoadCacheStores reads config.cache_store from each config/environments/*.rb file,
+// along with the URL env var passed to it
+func LoadCacheStores(projectPath string) (stores, urlEnvs map[string]string) {
+	files, _ := filepath.Glob(filepath.Join(projectPath, "config", "environments", "*.rb"))
+
+	stores = make(map[string]string)
+	urlEnvs = make(map[string]string)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		env := strings.TrimSuffix(filepath.Base(file), ".rb")
+		lines := strings.Split(string(data), "\n")
+		for i, line := range lines {
+			line = strings.TrimSpace(stripRubyComment(line))
+			matches := cacheStoreRegex.FindStringSubmatch(line)
+			if matches == nil {
+				continue
+			}
+			stores[env] = matches[1]
+			if name := cacheStoreURLEnv(lines[i:]); name != "" {
+				urlEnvs[env] = name
+			}
+		}
+	}
+	return stores, urlEnvs
+}
+
+// cacheStoreURLEnv returns the URL env var read by the config.cache_store statement
+// starting the lines, e.g. REDIS_CACHE_URL for `url: ENV["REDIS_CACHE_URL"]`, or ""
+// The statement continues while brackets are open or a line ends with a comma
+func cacheStoreURLEnv(lines []string) string {
+	depth := 0
+	for _, line := range lines {
+		line = strings.TrimSpace(stripRubyComment(line))
+		for _, matches := range envNameRegex.FindAllStringSubmatch(line, -1) {
+			if strings.HasSuffix(matches[1], "URL") {
+				return matches[1]
+			}
+		}
+		depth += strings.Count(line, "{") + strings.Count(line, "(") + strings.Count(line, "[")
+		depth -= strings.Count(line, "}") + strings.Count(line, ")") + strings.Count(line, "]")
+		if depth <= 0 && !strings.HasSuffix(line, ",") {
+			break
+		}
+	}
+	return ""
+}
+
+// StackProfile describes the project's stack for the given Rails environment
+func (p *Project) StackProfile(env string) *config.StackProfile {
+	if p == nil || p.GemfileLock == nil {
+		return &config.StackProfile{}
+	}
+	lock := p.GemfileLock
+
+	profile := &config.StackProfile{
+		Detected:       true,
+		RubyVersion:    lock.RubyVersion,
+		RailsVersion:   firstNonEmpty(lock.Version("rails"), lock.Version("railties")),
+		SidekiqVersion: lock.Version("sidekiq"),
+		CacheStore:     p.CacheStores[env],
+		CableAdapter:   p.CableAdapters[env],
+		CacheURLEnv:    p.CacheURLEnvs[env],
+		CableURLEnv:    p.CableURLEnvs[env],
+	}
+
+	// The web process command is more specific than the lockfile when several servers are bundled
+	if web := p.Process("web"); web != nil {
+		for _, server := range webServerGems {
+			if strings.Contains(web.Command, server) && lock.Has(server) {
+				profile.WebServer = server
+				break
+			}
+		}
+	}
+	if profile.WebServer == "" {
+		for _, server := range webServerGems {
+			if lock.Has(server) {
+				profile.WebServer = server
+				break
+			}
+		}
+	}
+	profile.WebServerVersion = lock.Version(profile.WebServer)
+
+	for _, backend := range jobBackendGems {
+		if lock.Has(backend.Gem) {
+			profile.JobBackends = append(profile.JobBackends, string(backend.Kind))
+		}
+	}
+
+	for _, gem := range redisGems {
+		if lock.Has(gem) {
+			profile.RedisGems = append(profile.RedisGems, gem)
+		}
+	}
+
+	return profile
+}
+
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
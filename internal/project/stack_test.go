@@ -0,0 +1,86 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+func TestStackProfile(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  *config.StackProfile
+	}{
+		{
+			name: "Sidekiq with a Redis cache store and Action Cable",
+			files: map[string]string{
+				"Gemfile.lock": testGemfileLock,
+				"Procfile":     "web: bundle exec puma -C config/puma.rb\nworker: bundle exec sidekiq\n",
+				"config/cable.yml": "development:\n  adapter: async\n" +
+					"production:\n  adapter: redis\n  channel_prefix: demo_production\n",
+				"config/environments/production.rb": "Rails.application.configure do\n" +
+					"  # config.cache_store = :mem_cache_store\n" +
+					"  config.cache_store = :redis_cache_store, { pool: { size: 5 } }\n" +
+					"end\n",
+			},
+			want: &config.StackProfile{
+				Detected:         true,
+				RubyVersion:      "3.2.2",
+				RailsVersion:     "7.1.2",
+				WebServer:        "puma",
+				WebServerVersion: "6.4.0",
+				JobBackends:      []string{"sidekiq"},
+				SidekiqVersion:   "7.2.0",
+				RedisGems:        []string{"sidekiq"},
+				CacheStore:       "redis_cache_store",
+				CableAdapter:     "redis",
+			},
+		},
+		{
+			name: "web command picks the server when several are bundled",
+			files: map[string]string{
+				"Gemfile.lock": "GEM\n  specs:\n    falcon (0.42.3)\n    good_job (3.21.0)\n    puma (6.4.0)\n    railties (7.0.8)\n",
+				"Procfile":     "web: bundle exec falcon serve\n",
+			},
+			want: &config.StackProfile{
+				Detected:         true,
+				RailsVersion:     "7.0.8",
+				WebServer:        "falcon",
+				WebServerVersion: "0.42.3",
+				JobBackends:      []string{"good_job"},
+			},
+		},
+		{
+			name:  "no Gemfile.lock",
+			files: map[string]string{"Procfile": "web: bundle exec puma\n"},
+			want:  &config.StackProfile{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			p := Load(dir)
+			if len(p.Warnings) > 0 {
+				t.Fatalf("Load() warnings: %v", p.Warnings)
+			}
+			if got := p.StackProfile("production"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StackProfile() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package releases
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+	"github.com/leaharmstrong/heroku-calc/internal/heroku"
+)
+
+// tunedVars are the config vars whose values the analysis reads
+// Releases setting them are looked up to show the old and new value
+var tunedVars = map[string]bool{
+	"WEB_CONCURRENCY":     true,
+	"RAILS_MAX_THREADS":   true,
+	"RAILS_MIN_THREADS":   true,
+	"DB_POOL":             true,
+	"DATABASE_POOL":       true,
+	"SIDEKIQ_CONCURRENCY": true,
+	"MALLOC_ARENA_MAX":    true,
+}
+
+var (
+	// configRegex matches "Set WEB_CONCURRENCY, RAILS_MAX_THREADS config vars" and "Remove FOO config vars"
+	configRegex = regexp.MustCompile(`^(Set|Remove) (.+?) config vars?$`)
+	// addonRegex matches "Attach DATABASE (@ref:postgresql-rigid-12345)", "Detach ..." and "Update REDIS by heroku-redis"
+	addonRegex = regexp.MustCompile(`^(Attach|Detach|Update) (\S+)`)
+	// deployRegex matches "Deploy 1a2b3c4d"
+	deployRegex = regexp.MustCompile(`^Deploy (\S+)`)
+	// rollbackRegex matches "Rollback to v41"
+	rollbackRegex = regexp.MustCompile(`^Rollback to (v\d+)`)
+)
+
+// Load reads up to limit recent releases and what each of them changed
+// Config var values are only looked up for tuned vars and only kept when numeric,
+// so credentials never end up in the history
+func Load(source heroku.ReleaseSource, limit int) (*config.ReleaseHistory, error) {
+	releases, err := source.GetReleases(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &config.ReleaseHistory{Releases: releases}
+	vars := newVarCache(source)
+	for _, release := range releases {
+		for _, change := range parseChanges(release) {
+			if change.Kind == config.ReleaseConfig && tunedVars[change.Subject] {
+				if !change.Removed {
+					change.NewValue = vars.numeric(release.Version, change.Subject)
+				}
+				change.OldValue = vars.numeric(release.Version-1, change.Subject)
+			}
+			history.Changes = append(history.Changes, change)
+		}
+	}
+	return history, nil
+}
+
+// parseChanges splits a release into the changes its description names
+func parseChanges(release config.Release) []config.ReleaseChange {
+	base := config.ReleaseChange{
+		Version:     release.Version,
+		User:        release.User,
+		CreatedAt:   release.CreatedAt,
+		Kind:        config.ReleaseOther,
+		Description: release.Description,
+	}
+
+	description := strings.TrimSpace(release.Description)
+	if matches := configRegex.FindStringSubmatch(description); matches != nil {
+		var changes []config.ReleaseChange
+		for _, name := range strings.Split(matches[2], ",") {
+			change := base
+			change.Kind = config.ReleaseConfig
+			change.Subject = strings.TrimSpace(name)
+			change.Removed = matches[1] == "Remove"
+			changes = append(changes, change)
+		}
+		return changes
+	}
+
+	change := base
+	if matches := addonRegex.FindStringSubmatch(description); matches != nil {
+		change.Kind = config.ReleaseAddon
+		change.Subject = matches[2]
+	} else if matches := deployRegex.FindStringSubmatch(description); matches != nil {
+		change.Kind = config.ReleaseDeploy
+		change.Subject = matches[1]
+	} else if matches := rollbackRegex.FindStringSubmatch(description); matches != nil {
+		change.Kind = config.ReleaseRollback
+		change.Subject = matches[1]
+	}
+	return []config.ReleaseChange{change}
+}
+
+// varCache fetches each release's config vars at most once
+type varCache struct {
+	source   heroku.ReleaseSource
+	releases map[int]map[string]string // nil entries are releases that couldn't be read
+}
+
+// newVarCache creates an empty cache over source
+func newVarCache(source heroku.ReleaseSource) *varCache {
+	return &varCache{source: source, releases: make(map[int]map[string]string)}
+}
+
+// numeric returns a config var's value in a release when it is a number, or ""
+func (c *varCache) numeric(version int, name string) string {
+	if version < 1 {
+		return ""
+	}
+
+	vars, ok := c.releases[version]
+	if !ok {
+		vars, _ = c.source.GetReleaseConfigVars(version)
+		c.releases[version] = vars
+	}
+
+	value := strings.TrimSpace(vars[name])
+	if _, err := strconv.ParseFloat(value, 64); err != nil {
+		return ""
+	}
+	return value
+}
@@ -0,0 +1,111 @@
+package releases
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+	"github.com/leaharmstrong/heroku-calc/internal/heroku"
+)
+
+func TestParseChanges(t *testing.T) {
+	tests := []struct {
+		description string
+		want        []config.ReleaseChange
+	}{
+		{
+			description: "Set WEB_CONCURRENCY, RAILS_MAX_THREADS config vars",
+			want: []config.ReleaseChange{
+				{Kind: config.ReleaseConfig, Subject: "WEB_CONCURRENCY"},
+				{Kind: config.ReleaseConfig, Subject: "RAILS_MAX_THREADS"},
+			},
+		},
+		{
+			description: "Remove DB_POOL config var",
+			want:        []config.ReleaseChange{{Kind: config.ReleaseConfig, Subject: "DB_POOL", Removed: true}},
+		},
+		{
+			description: "Attach DATABASE (@ref:postgresql-rigid-12345)",
+			want:        []config.ReleaseChange{{Kind: config.ReleaseAddon, Subject: "DATABASE"}},
+		},
+		{
+			description: "Update REDIS by heroku-redis",
+			want:        []config.ReleaseChange{{Kind: config.ReleaseAddon, Subject: "REDIS"}},
+		},
+		{
+			description: "Deploy 1a2b3c4d",
+			want:        []config.ReleaseChange{{Kind: config.ReleaseDeploy, Subject: "1a2b3c4d"}},
+		},
+		{
+			description: "Rollback to v41",
+			want:        []config.ReleaseChange{{Kind: config.ReleaseRollback, Subject: "v41"}},
+		},
+		{
+			description: "Enable Logplex",
+			want:        []config.ReleaseChange{{Kind: config.ReleaseOther}},
+		},
+	}
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			release := config.Release{Version: 42, Description: tt.description, User: "alice@example.com", CreatedAt: createdAt}
+			for i := range tt.want {
+				tt.want[i].Version = 42
+				tt.want[i].User = "alice@example.com"
+				tt.want[i].CreatedAt = createdAt
+				tt.want[i].Description = tt.description
+			}
+
+			if got := parseChanges(release); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseChanges() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	source := heroku.NewFixtureSource("demo")
+	source.Releases = []config.Release{
+		{Version: 8, Description: "Remove RAILS_MAX_THREADS config vars"},
+		{Version: 10, Description: "Set WEB_CONCURRENCY, SECRET_KEY_BASE config vars"},
+		{Version: 9, Description: "Deploy 1a2b3c4d"},
+		{Version: 7, Description: "Set RAILS_MAX_THREADS config vars"},
+	}
+	source.ReleaseConfigVars = map[int]map[string]string{
+		10: {"WEB_CONCURRENCY": "3", "SECRET_KEY_BASE": "s3cret"},
+		9:  {"WEB_CONCURRENCY": "2", "SECRET_KEY_BASE": "old"},
+		7:  {"RAILS_MAX_THREADS": "5"},
+	}
+
+	history, err := Load(source, 3)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	var versions []int
+	for _, release := range history.Releases {
+		versions = append(versions, release.Version)
+	}
+	if !reflect.DeepEqual(versions, []int{10, 9, 8}) {
+		t.Errorf("release versions = %v; want the newest three", versions)
+	}
+
+	// Values are looked up only for tuned vars; v7 is past the limit but still read for the old value
+	want := []config.ReleaseChange{
+		{Version: 10, Kind: config.ReleaseConfig, Subject: "WEB_CONCURRENCY", OldValue: "2", NewValue: "3"},
+		{Version: 10, Kind: config.ReleaseConfig, Subject: "SECRET_KEY_BASE"},
+		{Version: 9, Kind: config.ReleaseDeploy, Subject: "1a2b3c4d"},
+		{Version: 8, Kind: config.ReleaseConfig, Subject: "RAILS_MAX_THREADS", Removed: true, OldValue: "5"},
+	}
+	if len(history.Changes) != len(want) {
+		t.Fatalf("got %d changes; want %d: %+v", len(history.Changes), len(want), history.Changes)
+	}
+	for i, change := range history.Changes {
+		change.Description = ""
+		if change != want[i] {
+			t.Errorf("change %d = %+v; want %+v", i, change, want[i])
+		}
+	}
+}
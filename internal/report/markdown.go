@@ -2,6 +2,7 @@ package report
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -24,17 +25,33 @@ func GenerateMarkdown(appName string, result *config.AnalysisResult) string {
 	sb.WriteString(generateExecutiveSummary(result))
 	sb.WriteString("\n\n")
 
-	// Database Analysis
-	if result.DatabaseAnalysis != nil {
-		sb.WriteString("## Database Configuration\n\n")
-		sb.WriteString(generateDatabaseSection(result.DatabaseAnalysis))
+	// Problems reading the Rails project
+	if len(result.ProjectWarnings) > 0 {
+		sb.WriteString("## Project Files\n\n")
+		for _, warning := range result.ProjectWarnings {
+			sb.WriteString(fmt.Sprintf("- %s\n", warning))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Stack detected from Gemfile.lock
+	if result.Stack != nil && result.Stack.Detected {
+		sb.WriteString("## Stack\n\n")
+		sb.WriteString(generateStackSection(result.Stack))
+		sb.WriteString("\n")
+	}
+
+	// Database Analysis, one section per Postgres datastore
+	for _, db := range result.Databases {
+		sb.WriteString(datastoreHeading("## Database Configuration", db.Datastore, len(result.Databases)))
+		sb.WriteString(generateDatabaseSection(db))
 		sb.WriteString("\n\n")
 	}
 
-	// Redis Analysis
-	if result.RedisAnalysis != nil {
-		sb.WriteString("## Redis Configuration\n\n")
-		sb.WriteString(generateRedisSection(result.RedisAnalysis))
+	// Redis Analysis, one section per Redis datastore
+	for _, redis := range result.RedisInstances {
+		sb.WriteString(datastoreHeading("## Redis Configuration", redis.Datastore, len(result.RedisInstances)))
+		sb.WriteString(generateRedisSection(redis))
 		sb.WriteString("\n\n")
 	}
 
@@ -45,6 +62,34 @@ func GenerateMarkdown(appName string, result *config.AnalysisResult) string {
 		sb.WriteString("\n\n")
 	}
 
+	// Measured dyno memory
+	if result.MemoryAnalysis != nil {
+		sb.WriteString("## Dyno Memory\n\n")
+		sb.WriteString(generateMemorySection(result.MemoryAnalysis))
+		sb.WriteString("\n\n")
+	}
+
+	// Measured router traffic
+	if result.TrafficAnalysis != nil {
+		sb.WriteString("## Router Traffic\n\n")
+		sb.WriteString(generateTrafficSection(result.TrafficAnalysis))
+		sb.WriteString("\n\n")
+	}
+
+	// Recent releases
+	if result.ReleaseHistory != nil && len(result.ReleaseHistory.Releases) > 0 {
+		sb.WriteString("## Releases\n\n")
+		sb.WriteString(generateReleasesSection(result.ReleaseHistory))
+		sb.WriteString("\n\n")
+	}
+
+	// Monthly bill
+	if result.Cost != nil {
+		sb.WriteString("## Monthly Cost\n\n")
+		sb.WriteString(generateCostSection(result.Cost))
+		sb.WriteString("\n\n")
+	}
+
 	// Recommendations
 	if len(result.Recommendations) > 0 {
 		sb.WriteString("## Recommendations\n\n")
@@ -87,11 +132,45 @@ func generateExecutiveSummary(result *config.AnalysisResult) string {
 	return sb.String()
 }
 
+func generateStackSection(stack *config.StackProfile) string {
+	var sb strings.Builder
+
+	sb.WriteString("| Component | Detected |\n")
+	sb.WriteString("|-----------|----------|\n")
+	sb.WriteString(fmt.Sprintf("| Ruby | %s |\n", stack.RubyVersion))
+	sb.WriteString(fmt.Sprintf("| Rails | %s |\n", stack.RailsVersion))
+	sb.WriteString(fmt.Sprintf("| Web Server | %s %s |\n", stack.WebServer, stack.WebServerVersion))
+	sb.WriteString(fmt.Sprintf("| Job Backends | %s |\n", strings.Join(stack.JobBackends, ", ")))
+	if stack.SidekiqVersion != "" {
+		sb.WriteString(fmt.Sprintf("| Sidekiq | %s |\n", stack.SidekiqVersion))
+	}
+	sb.WriteString(fmt.Sprintf("| Redis Gems | %s |\n", strings.Join(stack.RedisGems, ", ")))
+	if stack.CacheStore != "" {
+		sb.WriteString(fmt.Sprintf("| Cache Store | %s |\n", stack.CacheStore))
+	}
+	if stack.CableAdapter != "" {
+		sb.WriteString(fmt.Sprintf("| Action Cable Adapter | %s |\n", stack.CableAdapter))
+	}
+
+	return sb.String()
+}
+
+// datastoreHeading returns a section heading, naming the datastore's config var when the app has several
+func datastoreHeading(heading string, store *config.Datastore, count int) string {
+	if count > 1 && store != nil {
+		heading += ": " + store.ConfigVars[0]
+	}
+	return heading + "\n\n"
+}
+
 func generateDatabaseSection(analysis *config.DatabaseAnalysis) string {
 	var sb strings.Builder
 
 	// Status
 	sb.WriteString(fmt.Sprintf("**Status:** %s  \n", formatStatus(analysis.Status)))
+	if analysis.Datastore != nil {
+		sb.WriteString(fmt.Sprintf("**Datastore:** %s  \n", analysis.Datastore.Name()))
+	}
 	sb.WriteString(fmt.Sprintf("**Plan:** %s  \n\n", analysis.PostgresPlan))
 
 	// Connection Analysis Table
@@ -99,18 +178,70 @@ func generateDatabaseSection(analysis *config.DatabaseAnalysis) string {
 	sb.WriteString("| Metric | Value |\n")
 	sb.WriteString("|--------|-------|\n")
 	sb.WriteString(fmt.Sprintf("| Max Connections | %d |\n", analysis.MaxConnections))
-	sb.WriteString(fmt.Sprintf("| Web Dynos | %d |\n", analysis.WebDynos))
-	sb.WriteString(fmt.Sprintf("| Workers per Dyno | %d |\n", analysis.WorkersPerDyno))
-	sb.WriteString(fmt.Sprintf("| Threads per Worker | %d |\n", analysis.ThreadsPerWorker))
-	sb.WriteString(fmt.Sprintf("| Web Connections | %d |\n", analysis.WebDynos*analysis.WorkersPerDyno*analysis.ThreadsPerWorker))
-	if analysis.SidekiqDynos > 0 {
-		sb.WriteString(fmt.Sprintf("| Sidekiq Dynos | %d |\n", analysis.SidekiqDynos))
-		sb.WriteString(fmt.Sprintf("| Sidekiq Threads | %d |\n", analysis.SidekiqThreads))
-		sb.WriteString(fmt.Sprintf("| Worker Connections | %d |\n", analysis.SidekiqDynos*analysis.SidekiqThreads))
+
+	// The formation is modelled against the primary database only
+	if analysis.Primary() {
+		sb.WriteString(fmt.Sprintf("| Web Dynos | %d |\n", analysis.WebDynos))
+		sb.WriteString(fmt.Sprintf("| Workers per Dyno | %d |\n", analysis.WorkersPerDyno))
+		sb.WriteString(fmt.Sprintf("| Threads per Worker | %d |\n", analysis.ThreadsPerWorker))
+		sb.WriteString(fmt.Sprintf("| Web Connections | %d |\n", analysis.WebConnections()))
+		for _, process := range analysis.Processes {
+			if process.Type == "web" {
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("| %s Dynos (%s) | %d |\n", process.Type, process.Kind, process.Dynos))
+			if process.ThreadsSource != "" {
+				sb.WriteString(fmt.Sprintf("| %s Threads | %d (%s) |\n", process.Type, process.Threads, process.ThreadsSource))
+			} else {
+				sb.WriteString(fmt.Sprintf("| %s Threads | %d |\n", process.Type, process.Threads))
+			}
+			if len(process.Queues) > 0 {
+				sb.WriteString(fmt.Sprintf("| %s Queues | %s |\n", process.Type, strings.Join(process.Queues, ", ")))
+			}
+			sb.WriteString(fmt.Sprintf("| %s Connections | %d |\n", process.Type, process.Connections))
+		}
+		for _, pool := range analysis.Pools {
+			sb.WriteString(fmt.Sprintf("| Pool: %s (%s) | %s |\n", pool.Name, analysis.Environment, formatPool(pool)))
+		}
+		for _, pooler := range analysis.Poolers {
+			sb.WriteString(fmt.Sprintf("| Pooler | %s |\n", pooler.Describe()))
+			sb.WriteString(fmt.Sprintf("| Pooled Processes | %s |\n", strings.Join(pooler.Processes, ", ")))
+			sb.WriteString(fmt.Sprintf("| Client Connections | %d |\n", pooler.ClientConnections))
+			sb.WriteString(fmt.Sprintf("| Server Connections | %s |\n", formatServerConnections(pooler)))
+		}
+	}
+	// Each process opens a pool per database, so spell out how they add up
+	if len(analysis.Budgets) > 1 || !analysis.Primary() {
+		for _, budget := range analysis.Budgets {
+			sb.WriteString(fmt.Sprintf("| Database: %s | %d (pool %s) |\n",
+				formatBudget(budget), budget.Connections, formatPoolSize(budget.Pool)))
+		}
 	}
 	sb.WriteString(fmt.Sprintf("| **Total Required** | **%d** |\n", analysis.TotalRequired))
 	sb.WriteString(fmt.Sprintf("| **Available Buffer** | **%.1f%%** |\n\n", analysis.BufferPercent))
 
+	// Live numbers next to the estimate
+	if measured := analysis.Measured; measured != nil {
+		sb.WriteString("### Measured (pg:info)\n\n")
+		sb.WriteString("| Metric | Value |\n")
+		sb.WriteString("|--------|-------|\n")
+		sb.WriteString(fmt.Sprintf("| Connections Open | %s |\n", formatMeasuredConnections(measured)))
+		if analysis.Modelled() {
+			sb.WriteString(fmt.Sprintf("| Connections Estimated | %d |\n", analysis.TotalRequired))
+		}
+		if unaccounted := analysis.UnaccountedConnections(); unaccounted > 0 {
+			sb.WriteString(fmt.Sprintf("| **Unaccounted Connections** | **%d** |\n", unaccounted))
+		}
+		if measured.Version != "" {
+			sb.WriteString(fmt.Sprintf("| Postgres Version | %s |\n", measured.Version))
+		}
+		sb.WriteString(fmt.Sprintf("| Data Size | %s |\n", formatDataSize(measured)))
+		if measured.Status != "" {
+			sb.WriteString(fmt.Sprintf("| Database Status | %s |\n", measured.Status))
+		}
+		sb.WriteString("\n")
+	}
+
 	// Issues
 	if len(analysis.Issues) > 0 {
 		sb.WriteString("### Issues\n\n")
@@ -119,16 +250,96 @@ func generateDatabaseSection(analysis *config.DatabaseAnalysis) string {
 		}
 		sb.WriteString("\n")
 	}
+	sb.WriteString(generateRecentChanges(analysis.RecentChanges))
 
 	return sb.String()
 }
 
+// formatServerConnections shows a pooler's server connections, with its maximum when known
+func formatServerConnections(pooler *config.ConnectionPooler) string {
+	if pooler.MaxServerConnections == 0 {
+		return fmt.Sprintf("%d", pooler.ServerConnections)
+	}
+	return fmt.Sprintf("%d (max %d)", pooler.ServerConnections, pooler.MaxServerConnections)
+}
+
+// formatRedisPlan shows the plan with its marketplace provider, e.g. "100 (Redis Cloud)"
+func formatRedisPlan(analysis *config.RedisAnalysis) string {
+	if analysis.Provider == "" {
+		return analysis.RedisPlan
+	}
+	return fmt.Sprintf("%s (%s)", analysis.RedisPlan, analysis.Provider)
+}
+
+// formatBudget names a database and the env var it reads
+func formatBudget(budget config.DatabaseBudget) string {
+	value := budget.Name
+	if budget.URLEnv != "" {
+		value += " via " + budget.URLEnv
+	}
+	if budget.Replica {
+		value += " [replica]"
+	}
+	return value
+}
+
+// formatPoolSize formats an evaluated pool size
+func formatPoolSize(pool int) string {
+	if pool == 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%d", pool)
+}
+
+// formatPool describes a database pool from config/database.yml
+func formatPool(pool config.DatabasePool) string {
+	var value string
+	switch {
+	case pool.Pool == 0:
+		value = "unknown"
+	case !pool.PoolSet:
+		value = fmt.Sprintf("%d (ActiveRecord default)", pool.Pool)
+	default:
+		value = fmt.Sprintf("%d", pool.Pool)
+	}
+
+	if pool.URLEnv != "" {
+		value += " via " + pool.URLEnv
+	}
+	if pool.Replica {
+		value += " (replica)"
+	}
+	return value
+}
+
+// formatMeasuredConnections shows connections in use, with the limit when pg:info reports one
+func formatMeasuredConnections(measured *config.PostgresInfo) string {
+	if measured.ConnectionLimit == 0 {
+		return fmt.Sprintf("%d", measured.Connections)
+	}
+	return fmt.Sprintf("%d / %d", measured.Connections, measured.ConnectionLimit)
+}
+
+// formatDataSize shows the database size, with the plan's storage limit when known
+func formatDataSize(measured *config.PostgresInfo) string {
+	switch {
+	case measured.DataSize == "":
+		return "unknown"
+	case measured.DataLimit == "":
+		return measured.DataSize
+	}
+	return fmt.Sprintf("%s / %s", measured.DataSize, measured.DataLimit)
+}
+
 func generateRedisSection(analysis *config.RedisAnalysis) string {
 	var sb strings.Builder
 
 	// Status
 	sb.WriteString(fmt.Sprintf("**Status:** %s  \n", formatStatus(analysis.Status)))
-	sb.WriteString(fmt.Sprintf("**Plan:** %s  \n\n", analysis.RedisPlan))
+	if analysis.Datastore != nil {
+		sb.WriteString(fmt.Sprintf("**Datastore:** %s  \n", analysis.Datastore.Name()))
+	}
+	sb.WriteString(fmt.Sprintf("**Plan:** %s  \n\n", formatRedisPlan(analysis)))
 
 	if analysis.RedisURL == "unknown" {
 		sb.WriteString("*Redis not configured for this application*\n")
@@ -143,9 +354,38 @@ func generateRedisSection(analysis *config.RedisAnalysis) string {
 	sb.WriteString(fmt.Sprintf("| Sidekiq Concurrency | %d |\n", analysis.SidekiqConcurrency))
 	sb.WriteString(fmt.Sprintf("| Redis Pool Size | %d |\n", analysis.RedisPoolSize))
 	sb.WriteString(fmt.Sprintf("| **Estimated Usage** | **%d** |\n", analysis.EstimatedUsage))
+	if measured := analysis.Measured; measured != nil && measured.Live {
+		sb.WriteString(fmt.Sprintf("| **Connected Clients (measured)** | **%d** |\n", measured.ConnectedClients))
+	}
 	if analysis.MaxConnections > 0 {
-		utilizationPercent := float64(analysis.EstimatedUsage) / float64(analysis.MaxConnections) * 100
-		sb.WriteString(fmt.Sprintf("| **Utilization** | **%.1f%%** |\n\n", utilizationPercent))
+		sb.WriteString(fmt.Sprintf("| **Utilization** | **%.1f%%** |\n", analysis.Utilization()))
+	}
+	sb.WriteString("\n")
+
+	// Live numbers next to the estimate
+	if measured := analysis.Measured; measured != nil {
+		sb.WriteString("### Measured (redis:info)\n\n")
+		sb.WriteString("| Metric | Value |\n")
+		sb.WriteString("|--------|-------|\n")
+		if measured.Live {
+			sb.WriteString(fmt.Sprintf("| Connected Clients | %d |\n", measured.ConnectedClients))
+			if measured.MaxClients > 0 {
+				sb.WriteString(fmt.Sprintf("| Max Clients | %d |\n", measured.MaxClients))
+			}
+			sb.WriteString(fmt.Sprintf("| Used Memory | %s |\n", formatBytes(measured.UsedMemory)))
+			if measured.Maxmemory > 0 {
+				sb.WriteString(fmt.Sprintf("| Maxmemory | %s (%.1f%% used) |\n", formatBytes(measured.Maxmemory), measured.MemoryPercent()))
+			}
+		} else {
+			sb.WriteString("| Connected Clients | not reported (utilization uses the estimate) |\n")
+		}
+		if measured.MaxmemoryPolicy != "" {
+			sb.WriteString(fmt.Sprintf("| Maxmemory Policy | %s |\n", measured.MaxmemoryPolicy))
+		}
+		if measured.Version != "" {
+			sb.WriteString(fmt.Sprintf("| Redis Version | %s |\n", measured.Version))
+		}
+		sb.WriteString("\n")
 	}
 
 	// Issues
@@ -156,10 +396,25 @@ func generateRedisSection(analysis *config.RedisAnalysis) string {
 		}
 		sb.WriteString("\n")
 	}
+	sb.WriteString(generateRecentChanges(analysis.RecentChanges))
 
 	return sb.String()
 }
 
+// formatBytes formats a byte count in binary units
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	value, exp := float64(bytes)/unit, 0
+	for value >= unit && exp < 3 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", value, "KMGT"[exp])
+}
+
 func generateWebTierSection(analysis *config.WebTierAnalysis) string {
 	var sb strings.Builder
 
@@ -172,9 +427,27 @@ func generateWebTierSection(analysis *config.WebTierAnalysis) string {
 	sb.WriteString("| Metric | Value |\n")
 	sb.WriteString("|--------|-------|\n")
 	sb.WriteString(fmt.Sprintf("| Dyno Memory | %d MB |\n", analysis.DynoMemoryMB))
-	sb.WriteString(fmt.Sprintf("| WEB_CONCURRENCY | %d |\n", analysis.WebConcurrency))
-	sb.WriteString(fmt.Sprintf("| RAILS_MAX_THREADS | %d |\n", analysis.RailsMaxThreads))
+	sb.WriteString(fmt.Sprintf("| WEB_CONCURRENCY | %d (%s) |\n", analysis.WebConcurrency, analysis.ConcurrencySource))
+	sb.WriteString(fmt.Sprintf("| RAILS_MAX_THREADS | %d (%s) |\n", analysis.RailsMaxThreads, analysis.ThreadsSource))
+	if analysis.PreloadApp {
+		sb.WriteString("| preload_app! | enabled |\n")
+	}
 	sb.WriteString(fmt.Sprintf("| **Total Threads** | **%d** |\n", analysis.TotalThreads))
+	if memory := analysis.Memory; memory != nil && memory.Samples > 0 {
+		sb.WriteString(fmt.Sprintf("| Peak Memory (measured) | %.0f MB (%.0f%%) |\n", memory.MemoryPeakMB, memory.PeakPercent()))
+		sb.WriteString(fmt.Sprintf("| p95 Memory (measured) | %.0f MB (%.0f%%) |\n", memory.MemoryP95MB, memory.P95Percent()))
+	}
+	if memory := analysis.Memory; memory != nil && memory.R14Errors+memory.R15Errors > 0 {
+		sb.WriteString(fmt.Sprintf("| R14/R15 Errors | %d / %d |\n", memory.R14Errors, memory.R15Errors))
+	}
+	if capacity := analysis.Capacity; capacity != nil {
+		sb.WriteString(fmt.Sprintf("| Peak Request Rate (%s) | %.0f/min |\n", capacity.Source, capacity.RequestsPerMinute))
+		sb.WriteString(fmt.Sprintf("| Mean Service Time | %.0f ms |\n", capacity.ServiceTimeMS))
+		sb.WriteString(fmt.Sprintf("| Requests in Flight | %.1f (%.0f%% of %d threads) |\n",
+			capacity.InFlight, capacity.CurrentUtilization, capacity.CurrentDynos*capacity.ThreadsPerDyno))
+		sb.WriteString(fmt.Sprintf("| **Web Dynos Needed** | **%d** (have %d, %.0f%% target utilization) |\n",
+			capacity.RequiredDynos, capacity.CurrentDynos, capacity.TargetUtilization))
+	}
 	if analysis.MemoryPerThread > 0 {
 		sb.WriteString(fmt.Sprintf("| **Memory per Thread** | **%d MB** |\n\n", analysis.MemoryPerThread))
 	}
@@ -187,10 +460,192 @@ func generateWebTierSection(analysis *config.WebTierAnalysis) string {
 		}
 		sb.WriteString("\n")
 	}
+	sb.WriteString(generateRecentChanges(analysis.RecentChanges))
+
+	return sb.String()
+}
+
+func generateMemorySection(analysis *config.MemoryAnalysis) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("**Status:** %s  \n", formatStatus(analysis.Status)))
+	sb.WriteString(fmt.Sprintf("**Source:** %s%s  \n\n", analysis.Source, formatTimeRange(analysis.From, analysis.To)))
+
+	sb.WriteString("| Process | Dynos | Samples | Peak | p95 | Quota | Load p95 | R14/R15 |\n")
+	sb.WriteString("|---------|-------|---------|------|-----|-------|----------|---------|\n")
+	for _, process := range analysis.Processes {
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %.0f MB | %.0f MB | %s | %.2f | %d / %d |\n",
+			process.Type, process.Dynos, process.Samples, process.MemoryPeakMB, process.MemoryP95MB,
+			formatQuota(process.QuotaMB), process.LoadAvgP95, process.R14Errors, process.R15Errors))
+	}
+	sb.WriteString("\n")
+
+	// Issues
+	if len(analysis.Issues) > 0 {
+		sb.WriteString("### Issues\n\n")
+		for _, issue := range analysis.Issues {
+			sb.WriteString(fmt.Sprintf("- %s\n", issue))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// generateCostSection lists the monthly bill by category with a grand total
+func generateCostSection(cost *config.CostAnalysis) string {
+	var sb strings.Builder
+
+	sb.WriteString("| Category | Item | Detail | Monthly |\n")
+	sb.WriteString("|----------|------|--------|---------|\n")
+	categories, totals := cost.CategoryTotals()
+	for _, category := range categories {
+		for _, item := range cost.Items {
+			if item.Category == category {
+				sb.WriteString(fmt.Sprintf("| %s | %s | %s | $%.2f |\n", category, item.Name, item.Detail, item.Monthly))
+			}
+		}
+		sb.WriteString(fmt.Sprintf("| **%s total** | | | **$%.2f** |\n", category, totals[category]))
+	}
+	sb.WriteString(fmt.Sprintf("| **Total** | | | **$%.2f** |\n\n", cost.Total()))
+
+	if len(cost.Unpriced) > 0 {
+		sb.WriteString("Not in the pricing data (not counted): " + strings.Join(cost.Unpriced, ", ") + "\n\n")
+	}
 
 	return sb.String()
 }
 
+func generateTrafficSection(analysis *config.TrafficAnalysis) string {
+	var sb strings.Builder
+	metrics := analysis.Metrics
+
+	sb.WriteString(fmt.Sprintf("**Status:** %s  \n", formatStatus(analysis.Status)))
+	sb.WriteString(fmt.Sprintf("**Source:** %s%s  \n\n", metrics.Source, formatTimeRange(metrics.From, metrics.To)))
+
+	sb.WriteString("| Metric | Value |\n")
+	sb.WriteString("|--------|-------|\n")
+	sb.WriteString(fmt.Sprintf("| Requests | %d |\n", metrics.Requests))
+	sb.WriteString(fmt.Sprintf("| **Request Rate** | **%.1f/min** |\n", metrics.RequestsPerMinute()))
+	sb.WriteString(fmt.Sprintf("| Service p50 | %.0f ms |\n", metrics.ServiceP50MS))
+	sb.WriteString(fmt.Sprintf("| **Service p95** | **%.0f ms** |\n", metrics.ServiceP95MS))
+	sb.WriteString(fmt.Sprintf("| Service p99 | %.0f ms |\n", metrics.ServiceP99MS))
+	sb.WriteString(fmt.Sprintf("| Service max | %.0f ms |\n", metrics.ServiceMaxMS))
+	sb.WriteString(fmt.Sprintf("| Connect p95 | %.0f ms |\n", metrics.ConnectP95MS))
+	sb.WriteString(fmt.Sprintf("| Status Codes | %s |\n", formatCounts(metrics.StatusClasses)))
+	sb.WriteString(fmt.Sprintf("| Router Errors | %s |\n", formatCounts(metrics.ErrorCodes)))
+	sb.WriteString("\n")
+
+	if len(metrics.Dynos) > 0 {
+		sb.WriteString("### Requests per Dyno\n\n")
+		sb.WriteString("| Dyno | Requests | Share | Service p95 | Router Errors |\n")
+		sb.WriteString("|------|----------|-------|-------------|---------------|\n")
+		for _, dyno := range metrics.Dynos {
+			sb.WriteString(fmt.Sprintf("| %s | %d | %.1f%% | %.0f ms | %d |\n",
+				dyno.Dyno, dyno.Requests, float64(dyno.Requests)/float64(metrics.Requests)*100, dyno.ServiceP95MS, dyno.Errors))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Issues
+	if len(analysis.Issues) > 0 {
+		sb.WriteString("### Issues\n\n")
+		for _, issue := range analysis.Issues {
+			sb.WriteString(fmt.Sprintf("- %s\n", issue))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// generateRecentChanges lists the releases that may explain a component's issues
+func generateRecentChanges(changes []config.ReleaseChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	now := time.Now()
+	sb.WriteString("### Recent Changes\n\n")
+	for _, change := range changes {
+		sb.WriteString(fmt.Sprintf("- %s\n", change.Summary(now)))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+func generateReleasesSection(history *config.ReleaseHistory) string {
+	var sb strings.Builder
+
+	// Tuned config var values, shown next to the release that changed them
+	values := make(map[int][]string)
+	for _, change := range history.Changes {
+		if change.Kind != config.ReleaseConfig || (change.OldValue == "" && change.NewValue == "") {
+			continue
+		}
+		values[change.Version] = append(values[change.Version],
+			fmt.Sprintf("%s %s → %s", change.Subject, valueOrUnset(change.OldValue), valueOrUnset(change.NewValue)))
+	}
+
+	sb.WriteString("| Release | Created | By | Description | Values |\n")
+	sb.WriteString("|---------|---------|----|-------------|--------|\n")
+	for _, release := range history.Releases {
+		version := fmt.Sprintf("v%d", release.Version)
+		if release.Current {
+			version = "**" + version + "**"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n",
+			version, release.CreatedAt.Format("2006-01-02 15:04"), release.User,
+			strings.ReplaceAll(release.Description, "|", "\\|"), strings.Join(values[release.Version], ", ")))
+	}
+	sb.WriteString("\n*Scaling the formation doesn't create a release.*\n")
+
+	return sb.String()
+}
+
+// valueOrUnset shows a config var value, or "(unset)"
+func valueOrUnset(value string) string {
+	if value == "" {
+		return "(unset)"
+	}
+	return value
+}
+
+// formatCounts lists counts by key in key order, e.g. "2xx 120, 5xx 3"
+func formatCounts(counts map[string]int) string {
+	if len(counts) == 0 {
+		return "none"
+	}
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = fmt.Sprintf("%s %d", key, counts[key])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatQuota shows a dyno memory quota, which is unknown for unpriced dyno sizes
+func formatQuota(quotaMB float64) string {
+	if quotaMB == 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%.0f MB", quotaMB)
+}
+
+// formatTimeRange describes the period the log lines covered, e.g. " (2024-05-01 12:00 – 2024-05-01 12:45 UTC)"
+func formatTimeRange(from, to time.Time) string {
+	if from.IsZero() || to.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf(" (%s – %s UTC)", from.UTC().Format("2006-01-02 15:04"), to.UTC().Format("2006-01-02 15:04"))
+}
+
 func generateRecommendationsSection(recommendations []config.Recommendation) string {
 	var sb strings.Builder
 
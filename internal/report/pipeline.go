@@ -0,0 +1,86 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/leaharmstrong/heroku-calc/internal/fleet"
+)
+
+// GeneratePipelineMarkdown creates a markdown report comparing the apps of a pipeline
+func GeneratePipelineMarkdown(pipelineName string, cmp *fleet.Comparison) string {
+	var sb strings.Builder
+
+	sb.WriteString("# Heroku Pipeline Comparison\n\n")
+	sb.WriteString(fmt.Sprintf("**Pipeline:** %s  \n", pipelineName))
+	sb.WriteString(fmt.Sprintf("**Generated:** %s  \n\n", time.Now().Format("2006-01-02 15:04:05 MST")))
+
+	sb.WriteString("---\n\n")
+
+	// Capacity differences first: these are the surprises
+	sb.WriteString("## Capacity Differences\n\n")
+	differences := cmp.CapacityDifferences()
+	if len(differences) == 0 {
+		sb.WriteString("No capacity-relevant differences between apps.\n\n")
+	} else {
+		for _, row := range differences {
+			values := make([]string, len(row.Values))
+			for i, value := range row.Values {
+				values[i] = fmt.Sprintf("%s: %s", cmp.Apps[i].App, value)
+			}
+			sb.WriteString(fmt.Sprintf("- **%s / %s**: %s\n", row.Section, row.Name, strings.Join(values, ", ")))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Side-by-side table
+	sb.WriteString("## Side by Side\n\n")
+	sb.WriteString("| | Setting |")
+	for _, app := range cmp.Apps {
+		sb.WriteString(fmt.Sprintf(" %s |", pipelineAppLabel(app)))
+	}
+	sb.WriteString("\n|---|---|")
+	sb.WriteString(strings.Repeat("---|", len(cmp.Apps)))
+	sb.WriteString("\n")
+
+	for _, row := range cmp.Rows {
+		sb.WriteString(fmt.Sprintf("| %s | %s / %s |", DifferenceMarker(row), row.Section, row.Name))
+		for _, value := range row.Values {
+			sb.WriteString(fmt.Sprintf(" %s |", value))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n`!` differs in a way that changes capacity, `~` differs\n\n")
+
+	// Apps that could not be analyzed
+	for _, app := range cmp.Apps {
+		if app.Err != nil {
+			sb.WriteString(fmt.Sprintf("- **%s** could not be analyzed: %v\n", app.App, app.Err))
+		}
+	}
+
+	sb.WriteString("\n---\n\n")
+	sb.WriteString("*Report generated by [Heroku Config Analyzer](https://github.com/leaharmstrong/heroku-calc)*\n")
+
+	return sb.String()
+}
+
+// DifferenceMarker returns "!" for capacity differences, "~" for other differences, or ""
+func DifferenceMarker(row fleet.ComparisonRow) string {
+	switch {
+	case row.Capacity:
+		return "!"
+	case row.Differs:
+		return "~"
+	}
+	return ""
+}
+
+// pipelineAppLabel names an app with its stage
+func pipelineAppLabel(app fleet.AppResult) string {
+	if app.Stage == "" {
+		return app.App
+	}
+	return fmt.Sprintf("%s (%s)", app.App, app.Stage)
+}
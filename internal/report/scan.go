@@ -0,0 +1,120 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+	"github.com/leaharmstrong/heroku-calc/internal/fleet"
+)
+
+// ScanReport is the combined result of a team scan, as exported to JSON
+type ScanReport struct {
+	Team        string            `json:"team"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	Apps        []fleet.ScanEntry `json:"apps"` // Ranked by risk, then cost
+	MonthlyCost float64           `json:"monthly_cost"`
+}
+
+// NewScanReport wraps ranked scan entries for export
+func NewScanReport(team string, entries []fleet.ScanEntry) *ScanReport {
+	scan := &ScanReport{Team: team, GeneratedAt: time.Now().UTC(), Apps: entries}
+	for _, entry := range entries {
+		scan.MonthlyCost += entry.MonthlyCost
+	}
+	return scan
+}
+
+// GenerateScanJSON encodes a team scan as indented JSON
+func GenerateScanJSON(scan *ScanReport) (string, error) {
+	data, err := json.MarshalIndent(scan, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode scan: %w", err)
+	}
+	return string(data) + "\n", nil
+}
+
+// GenerateScanMarkdown creates a markdown report ranking a team's apps
+func GenerateScanMarkdown(scan *ScanReport) string {
+	var sb strings.Builder
+
+	sb.WriteString("# Heroku Team Scan\n\n")
+	sb.WriteString(fmt.Sprintf("**Team:** %s  \n", scan.Team))
+	sb.WriteString(fmt.Sprintf("**Apps:** %d  \n", len(scan.Apps)))
+	sb.WriteString(fmt.Sprintf("**Monthly Cost:** $%.2f  \n", scan.MonthlyCost))
+	sb.WriteString(fmt.Sprintf("**Generated:** %s  \n\n", scan.GeneratedAt.Format("2006-01-02 15:04:05 MST")))
+
+	sb.WriteString("---\n\n")
+
+	// Ranking
+	sb.WriteString("## Ranking\n\n")
+	sb.WriteString("| # | App | Critical | Warnings | DB Connections | Redis Connections | Dynos | Monthly Cost |\n")
+	sb.WriteString("|---|-----|----------|----------|----------------|-------------------|-------|--------------|\n")
+	for i, entry := range scan.Apps {
+		if entry.Error != "" {
+			sb.WriteString(fmt.Sprintf("| %d | %s | - | - | - | - | - | - |\n", i+1, entry.App))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("| %d | %s | %d | %d | %s | %s | %d | $%.2f |\n",
+			i+1, entry.App, entry.Critical, entry.Warnings,
+			FormatUtilisation(entry.DatabaseUtilisation, entry.PostgresPlan),
+			FormatUtilisation(entry.RedisUtilisation, entry.RedisPlan),
+			entry.Dynos, entry.MonthlyCost))
+	}
+	sb.WriteString("\n")
+
+	// Urgent recommendations per app
+	sb.WriteString("## Critical and High Priority Recommendations\n\n")
+	listed := false
+	for _, entry := range scan.Apps {
+		titles := []string{}
+		for _, rec := range entry.Recommendations {
+			if rec.Severity == config.SeverityCritical || rec.Severity == config.SeverityHigh {
+				titles = append(titles, fmt.Sprintf("%s (%s)", rec.Title, rec.Severity))
+			}
+		}
+		if len(titles) == 0 {
+			continue
+		}
+		listed = true
+		sb.WriteString(fmt.Sprintf("- **%s**: %s\n", entry.App, strings.Join(titles, ", ")))
+	}
+	if !listed {
+		sb.WriteString("None.\n")
+	}
+	sb.WriteString("\n")
+
+	// Apps that could not be analyzed
+	failed := false
+	for _, entry := range scan.Apps {
+		if entry.Error == "" {
+			continue
+		}
+		if !failed {
+			sb.WriteString("## Apps Not Analyzed\n\n")
+			failed = true
+		}
+		sb.WriteString(fmt.Sprintf("- **%s**: %s\n", entry.App, entry.Error))
+	}
+	if failed {
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("---\n\n")
+	sb.WriteString("*Report generated by [Heroku Config Analyzer](https://github.com/leaharmstrong/heroku-calc)*\n")
+
+	return sb.String()
+}
+
+// FormatUtilisation formats a connection utilisation percentage with its plan
+func FormatUtilisation(percent float64, plan string) string {
+	if plan == "" || plan == "unknown" {
+		return "-"
+	}
+	if percent == 0 {
+		return plan
+	}
+	return fmt.Sprintf("%.0f%% (%s)", percent, plan)
+}
@@ -0,0 +1,224 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+	"github.com/leaharmstrong/heroku-calc/internal/heroku"
+	"github.com/leaharmstrong/heroku-calc/internal/logs"
+	"github.com/leaharmstrong/heroku-calc/internal/pricing"
+	"github.com/leaharmstrong/heroku-calc/internal/releases"
+)
+
+// FormatVersion is the current snapshot file format version
+// It is bumped whenever a field is added or changes meaning, and Load rejects older formats:
+// version 1 could record running processes as dynos instead of the formation, without datastore info
+const FormatVersion = 2
+
+// Snapshot is a point-in-time capture of everything the analysis reads from Heroku
+type Snapshot struct {
+	FormatVersion  int                    `json:"format_version"`
+	CapturedAt     time.Time              `json:"captured_at"`
+	AppInfo        *heroku.AppInfo        `json:"app_info"`
+	EnvVars        []config.HerokuEnvVar  `json:"env_vars"`
+	Dynos          []config.DynoFormation `json:"dynos"`
+	Processes      []config.Dyno          `json:"processes,omitempty"`
+	Addons         []config.Addon         `json:"addons"`
+	PostgresInfo   *config.PostgresInfo   `json:"postgres_info,omitempty"` // DATABASE_URL
+	RedisInfo      *config.RedisInfo      `json:"redis_info,omitempty"`    // REDIS_URL
+	RuntimeMetrics *config.RuntimeMetrics `json:"runtime_metrics,omitempty"`
+	RouterMetrics  *config.RouterMetrics  `json:"router_metrics,omitempty"`
+	ReleaseHistory *config.ReleaseHistory `json:"release_history,omitempty"`
+	PricingVersion string                 `json:"pricing_version"`
+
+	// Live info for the other datastores, by config var
+	PostgresInfoByVar map[string]*config.PostgresInfo `json:"postgres_info_by_var,omitempty"`
+	RedisInfoByVar    map[string]*config.RedisInfo    `json:"redis_info_by_var,omitempty"`
+
+	Buildpacks []string `json:"buildpacks,omitempty"`
+}
+
+// Capture reads the app's current configuration from source
+// Env var values are kept as read, so a capture can be analyzed directly; Save sanitizes them
+func Capture(source heroku.Source, pricingData *pricing.Data) (*Snapshot, error) {
+	appInfo, err := source.GetAppInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load app info: %w", err)
+	}
+
+	envVars, err := source.GetEnvVars()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load env vars: %w", err)
+	}
+
+	dynos, err := source.GetDynos()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load dynos: %w", err)
+	}
+
+	processes, err := source.GetProcesses()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load dyno processes: %w", err)
+	}
+
+	addons, err := source.GetAddons()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load addons: %w", err)
+	}
+
+	snap := &Snapshot{
+		FormatVersion: FormatVersion,
+		CapturedAt:    time.Now().UTC(),
+		AppInfo:       appInfo,
+		EnvVars:       envVars,
+		Dynos:         dynos,
+		Processes:     processes,
+		Addons:        addons,
+	}
+
+	// Live database numbers are optional: not every source or app has them
+	if pgSource, ok := source.(heroku.PostgresInfoSource); ok {
+		for _, configVar := range addonConfigVars(config.DatastorePostgres, addons, envVars) {
+			if info, err := pgSource.GetPostgresInfo(configVar); err == nil {
+				if configVar == heroku.PrimaryConfigVar(config.DatastorePostgres) {
+					snap.PostgresInfo = info
+				} else {
+					if snap.PostgresInfoByVar == nil {
+						snap.PostgresInfoByVar = make(map[string]*config.PostgresInfo)
+					}
+					snap.PostgresInfoByVar[configVar] = info
+				}
+			}
+		}
+	}
+	if redisSource, ok := source.(heroku.RedisInfoSource); ok {
+		for _, configVar := range addonConfigVars(config.DatastoreRedis, addons, envVars) {
+			if info, err := redisSource.GetRedisInfo(configVar); err == nil {
+				if configVar == heroku.PrimaryConfigVar(config.DatastoreRedis) {
+					snap.RedisInfo = info
+				} else {
+					if snap.RedisInfoByVar == nil {
+						snap.RedisInfoByVar = make(map[string]*config.RedisInfo)
+					}
+					snap.RedisInfoByVar[configVar] = info
+				}
+			}
+		}
+	}
+
+	if buildpackSource, ok := source.(heroku.BuildpackSource); ok {
+		if buildpacks, err := buildpackSource.GetBuildpacks(); err == nil {
+			snap.Buildpacks = buildpacks
+		}
+	}
+
+	// Only aggregated metrics are kept: raw log lines hold request paths
+	if logSource, ok := source.(heroku.LogSource); ok {
+		if output, err := logSource.GetLogs(heroku.DefaultLogLines); err == nil {
+			collector := logs.Parse(output)
+			snap.RuntimeMetrics = collector.RuntimeMetrics("heroku logs")
+			snap.RouterMetrics = collector.RouterMetrics("heroku logs")
+		}
+	}
+
+	// Release changes keep only numeric values of tuned config vars, never credentials
+	if releaseSource, ok := source.(heroku.ReleaseSource); ok {
+		if history, err := releases.Load(releaseSource, heroku.DefaultReleaseLimit); err == nil {
+			snap.ReleaseHistory = history
+		}
+	}
+
+	if pricingData != nil {
+		snap.PricingVersion = pricingData.Version
+	}
+
+	return snap, nil
+}
+
+// addonConfigVars returns the first config var of every add-on datastore of the kind
+func addonConfigVars(kind string, addons []config.Addon, envVars []config.HerokuEnvVar) []string {
+	var configVars []string
+	for _, store := range heroku.FindDatastores(kind, addons, envVars) {
+		if store.Addon != "" && len(store.ConfigVars) > 0 {
+			configVars = append(configVars, store.ConfigVars[0])
+		}
+	}
+	return configVars
+}
+
+// Save writes the snapshot to path as indented JSON
+// Env var values are sanitized so the file can be shared safely
+func (s *Snapshot) Save(path string) error {
+	saved := *s
+	saved.EnvVars = make([]config.HerokuEnvVar, len(s.EnvVars))
+	for i, ev := range s.EnvVars {
+		saved.EnvVars[i] = config.HerokuEnvVar{
+			Name:  ev.Name,
+			Value: heroku.SanitizeEnvVarValue(ev.Name, ev.Value),
+		}
+	}
+
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads a snapshot file from path
+func Load(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+
+	switch {
+	case snap.FormatVersion == FormatVersion:
+	case snap.FormatVersion > 0 && snap.FormatVersion < FormatVersion:
+		return nil, fmt.Errorf("snapshot format version %d is from an older version of heroku-calc and can't be analyzed the same way; capture the app again", snap.FormatVersion)
+	default:
+		return nil, fmt.Errorf("unsupported snapshot format version %d (this build supports up to %d)", snap.FormatVersion, FormatVersion)
+	}
+	if snap.AppInfo == nil {
+		return nil, fmt.Errorf("snapshot is missing app info")
+	}
+
+	return &snap, nil
+}
+
+// Source returns a read-only source that replays the snapshot
+func (s *Snapshot) Source() *heroku.FixtureSource {
+	return &heroku.FixtureSource{
+		App:          s.AppInfo,
+		EnvVars:      s.EnvVars,
+		Dynos:        s.Dynos,
+		Processes:    s.Processes,
+		Addons:       s.Addons,
+		PostgresInfo: s.PostgresInfo,
+		RedisInfo:    s.RedisInfo,
+		ReadOnly:     true,
+
+		PostgresInfoByVar: s.PostgresInfoByVar,
+		RedisInfoByVar:    s.RedisInfoByVar,
+		Buildpacks:        s.Buildpacks,
+	}
+}
+
+// GenerateFileName creates a timestamped filename for a snapshot
+func GenerateFileName(appName string) string {
+	timestamp := time.Now().Format("2006-01-02-150405")
+	return fmt.Sprintf("heroku-snapshot-%s-%s.json", appName, timestamp)
+}
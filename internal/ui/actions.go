@@ -57,7 +57,7 @@ func (m Model) applySelectedActions() (tea.Model, tea.Cmd) {
 	m.state = StateApplying
 	m.statusMessage = fmt.Sprintf("Applying %d change(s)...", len(applicableRecs))
 
-	return m, applyRecommendations(m.herokuClient, applicableRecs, m.mode)
+	return m, applyRecommendations(m.source, applicableRecs, m.mode)
 }
 
 // exportReport exports the analysis as a markdown report
@@ -87,7 +87,7 @@ func (m Model) exportReport() (tea.Model, tea.Cmd) {
 }
 
 // applyRecommendations applies the given recommendations
-func applyRecommendations(clientInterface interface{}, recommendations []config.Recommendation, mode AppMode) tea.Cmd {
+func applyRecommendations(source heroku.Source, recommendations []config.Recommendation, mode AppMode) tea.Cmd {
 	return func() tea.Msg {
 		if mode == ModeDryRun {
 			// In dry-run mode, just return success without actually applying
@@ -97,12 +97,10 @@ func applyRecommendations(clientInterface interface{}, recommendations []config.
 			}
 		}
 
-		// Cast the client
-		client, ok := clientInterface.(*heroku.Client)
-		if !ok || client == nil {
+		if source == nil {
 			return applyCompleteMsg{
 				success: false,
-				err:     fmt.Errorf("invalid heroku client"),
+				err:     fmt.Errorf("no Heroku data source available"),
 			}
 		}
 
@@ -117,7 +115,7 @@ func applyRecommendations(clientInterface interface{}, recommendations []config.
 			}
 
 			// Set the environment variable
-			err := client.SetEnvVar(rec.EnvVarName, rec.Suggested)
+			err := source.SetEnvVar(rec.EnvVarName, rec.Suggested)
 			if err != nil {
 				return applyCompleteMsg{
 					success: false,
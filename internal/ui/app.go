@@ -9,18 +9,28 @@ import (
 	"github.com/leaharmstrong/heroku-calc/internal/config"
 	"github.com/leaharmstrong/heroku-calc/internal/heroku"
 	"github.com/leaharmstrong/heroku-calc/internal/pricing"
+	"github.com/leaharmstrong/heroku-calc/internal/project"
+	"github.com/leaharmstrong/heroku-calc/internal/snapshot"
 )
 
 // Messages for async operations
 type loadedDataMsg struct {
-	client       *heroku.Client
-	appInfo      *heroku.AppInfo
-	envVars      []config.HerokuEnvVar
-	dynos        []config.DynoFormation
-	addons       []config.Addon
-	pricingData  *pricing.Data
-	cfg          *config.Config
-	err          error
+	source      heroku.Source
+	captured    *snapshot.Snapshot // What the analysis runs on
+	appInfo     *heroku.AppInfo
+	envVars     []config.HerokuEnvVar
+	dynos       []config.DynoFormation
+	oneOffDynos []config.Dyno
+	addons      []config.Addon
+	pricingData *pricing.Data
+	cfg         *config.Config
+	err         error
+}
+
+type remotesDetectedMsg struct {
+	remotes     []heroku.HerokuRemote
+	savedRemote string
+	err         error
 }
 
 type analysisCompleteMsg struct {
@@ -39,9 +49,17 @@ type errMsg struct {
 
 // Init initializes the application
 func (m Model) Init() tea.Cmd {
+	// The app comes from a git remote unless it was given explicitly
+	if m.source == nil && m.appName == "" {
+		return tea.Batch(
+			m.spinner.Tick,
+			detectRemotes(m.projectPath),
+		)
+	}
+
 	return tea.Batch(
 		m.spinner.Tick,
-		loadData(m.projectPath, m.appName),
+		loadData(m.projectPath, m.appName, m.remoteName, m.source),
 	)
 }
 
@@ -64,6 +82,39 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case remotesDetectedMsg:
+		if msg.err != nil {
+			m.state = StateError
+			m.err = msg.err
+			return m, nil
+		}
+
+		m.remotes = msg.remotes
+		if m.remoteName != "" {
+			// An explicit --remote must exist
+			remote := heroku.SelectRemote(m.remotes, m.remoteName)
+			if remote == nil || remote.Name != m.remoteName {
+				m.state = StateError
+				m.err = fmt.Errorf("git remote %q is not a Heroku remote", m.remoteName)
+				return m, nil
+			}
+			return m.selectRemote(*remote)
+		}
+
+		switch remote := heroku.SelectRemote(m.remotes, msg.savedRemote); {
+		case remote != nil:
+			return m.selectRemote(*remote)
+		case len(m.remotes) == 0:
+			m.state = StateError
+			m.err = fmt.Errorf("failed to detect Heroku app: no Heroku git remote found")
+			return m, nil
+		}
+
+		// Several remotes and nothing remembered: ask
+		m.state = StateSelectingRemote
+		m.cursorPos = 0
+		return m, nil
+
 	case loadedDataMsg:
 		if msg.err != nil {
 			m.state = StateError
@@ -71,10 +122,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
-		m.herokuClient = msg.client
+		m.source = msg.source
 		m.appInfo = msg.appInfo
 		m.envVars = msg.envVars
 		m.dynos = msg.dynos
+		m.oneOffDynos = msg.oneOffDynos
 		m.addons = msg.addons
 		m.pricingData = msg.pricingData
 		m.cfg = msg.cfg
@@ -89,7 +141,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Move to analyzing state
 		m.state = StateAnalyzing
 		m.statusMessage = "Running analysis..."
-		return m, runAnalysis(m.herokuClient, m.pricingData)
+		return m, runAnalysis(msg.captured, m.pricingData, m.projectPath, m.measured)
 
 	case analysisCompleteMsg:
 		if msg.err != nil {
@@ -101,6 +153,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.analysis = msg.result
 		m.state = StateReady
 		m.statusMessage = "Analysis complete"
+		if m.snapshot != nil && m.pricingData != nil && m.snapshot.PricingVersion != m.pricingData.Version {
+			m.statusMessage = fmt.Sprintf("Analysis complete (snapshot priced with %s, using %s)", m.snapshot.PricingVersion, m.pricingData.Version)
+		}
 		return m, nil
 
 	case applyCompleteMsg:
@@ -129,7 +184,7 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "tab", "right":
 		if m.state == StateReady {
-			m.currentTab = (m.currentTab + 1) % 6
+			m.currentTab = (m.currentTab + 1) % tabCount
 			m.cursorPos = 0
 		}
 		return m, nil
@@ -137,7 +192,7 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "shift+tab", "left":
 		if m.state == StateReady {
 			if m.currentTab == 0 {
-				m.currentTab = 5
+				m.currentTab = tabCount - 1
 			} else {
 				m.currentTab--
 			}
@@ -145,6 +200,15 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case "enter", " ":
+		if m.state == StateSelectingRemote {
+			if m.cursorPos < len(m.remotes) {
+				return m.selectRemote(m.remotes[m.cursorPos])
+			}
+			return m, nil
+		}
+		return m.handleSelection()
+
 	case "up", "k":
 		if m.cursorPos > 0 {
 			m.cursorPos--
@@ -158,9 +222,6 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "enter", " ":
-		return m.handleSelection()
-
 	case "a":
 		// Apply selected actions (if in appropriate mode and on Actions tab)
 		if m.currentTab == TabActions && m.mode != ModeReadOnly {
@@ -213,8 +274,24 @@ func (m Model) handleSelection() (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// selectRemote loads the app deployed by the chosen git remote
+func (m Model) selectRemote(remote heroku.HerokuRemote) (tea.Model, tea.Cmd) {
+	m.remoteName = remote.Name
+	m.appName = remote.AppName
+	m.state = StateLoading
+	m.cursorPos = 0
+	return m, tea.Batch(
+		m.spinner.Tick,
+		loadData(m.projectPath, m.appName, m.remoteName, m.source),
+	)
+}
+
 // getMaxCursorPos returns the maximum cursor position for the current tab
 func (m Model) getMaxCursorPos() int {
+	if m.state == StateSelectingRemote {
+		return len(m.remotes) - 1
+	}
+
 	switch m.currentTab {
 	case TabEnvVars:
 		return len(m.envVars) - 1
@@ -226,51 +303,51 @@ func (m Model) getMaxCursorPos() int {
 	return 0
 }
 
-// loadData loads all necessary data from Heroku
-func loadData(projectPath, appName string) tea.Cmd {
+// detectRemotes lists the project's Heroku git remotes and the one remembered in the config
+func detectRemotes(projectPath string) tea.Cmd {
 	return func() tea.Msg {
-		// Auto-detect app name from git if not provided
-		if appName == "" {
-			detectedName, _, err := heroku.DetectHerokuApp(projectPath)
-			if err != nil {
-				return loadedDataMsg{err: fmt.Errorf("failed to detect Heroku app: %w", err)}
-			}
-			appName = detectedName
-		}
-
-		// Create Heroku client
-		client, err := heroku.NewClient(appName)
+		remotes, err := heroku.DetectHerokuRemotes(projectPath)
 		if err != nil {
-			return loadedDataMsg{err: fmt.Errorf("failed to create Heroku client: %w", err)}
+			return remotesDetectedMsg{err: fmt.Errorf("failed to detect Heroku app: %w", err)}
 		}
 
-		// Test connection
-		if err := client.TestConnection(); err != nil {
-			return loadedDataMsg{err: fmt.Errorf("failed to connect to Heroku: %w", err)}
+		savedRemote := ""
+		if config.Exists(projectPath) {
+			if cfg, err := config.Load(projectPath); err == nil {
+				savedRemote = cfg.GitRemote
+			}
 		}
 
-		// Load app info
-		appInfo, err := client.GetAppInfo()
-		if err != nil {
-			return loadedDataMsg{err: fmt.Errorf("failed to load app info: %w", err)}
-		}
+		return remotesDetectedMsg{remotes: remotes, savedRemote: savedRemote}
+	}
+}
 
-		// Load env vars
-		envVars, err := client.GetEnvVars()
-		if err != nil {
-			return loadedDataMsg{err: fmt.Errorf("failed to load env vars: %w", err)}
-		}
+// loadData loads all necessary data from Heroku
+func loadData(projectPath, appName, remoteName string, source heroku.Source) tea.Cmd {
+	return func() tea.Msg {
+		if source == nil {
+			// Auto-detect app name from git if not provided
+			if appName == "" {
+				detectedName, _, err := heroku.DetectHerokuApp(projectPath)
+				if err != nil {
+					return loadedDataMsg{err: fmt.Errorf("failed to detect Heroku app: %w", err)}
+				}
+				appName = detectedName
+			}
 
-		// Load dynos
-		dynos, err := client.GetDynos()
-		if err != nil {
-			return loadedDataMsg{err: fmt.Errorf("failed to load dynos: %w", err)}
+			// Create Heroku data source
+			var err error
+			source, err = heroku.NewSource(appName)
+			if err != nil {
+				return loadedDataMsg{err: fmt.Errorf("failed to create Heroku client: %w", err)}
+			}
+		} else if appName == "" {
+			appName = source.AppName()
 		}
 
-		// Load addons
-		addons, err := client.GetAddons()
-		if err != nil {
-			return loadedDataMsg{err: fmt.Errorf("failed to load addons: %w", err)}
+		// Test connection
+		if err := source.TestConnection(); err != nil {
+			return loadedDataMsg{err: fmt.Errorf("failed to connect to Heroku: %w", err)}
 		}
 
 		// Load pricing data
@@ -279,6 +356,14 @@ func loadData(projectPath, appName string) tea.Cmd {
 			return loadedDataMsg{err: fmt.Errorf("failed to load pricing data: %w", err)}
 		}
 
+		// Capture the app once; the analysis replays the capture instead of loading it again
+		captured, err := snapshot.Capture(source, pricingData)
+		if err != nil {
+			return loadedDataMsg{err: err}
+		}
+		addons := append([]config.Addon(nil), captured.Addons...)
+		pricingData.PriceAddons(addons)
+
 		// Load or create config
 		var cfg *config.Config
 		if config.Exists(projectPath) {
@@ -288,14 +373,24 @@ func loadData(projectPath, appName string) tea.Cmd {
 			}
 		} else {
 			cfg = config.New(appName, projectPath)
+			cfg.GitRemote = remoteName
+			_ = config.Save(cfg, projectPath)
+		}
+
+		// Remember the chosen remote for the next run
+		if remoteName != "" && (cfg.GitRemote != remoteName || cfg.AppName != appName) {
+			cfg.GitRemote = remoteName
+			cfg.AppName = appName
 			_ = config.Save(cfg, projectPath)
 		}
 
 		return loadedDataMsg{
-			client:      client,
-			appInfo:     appInfo,
-			envVars:     envVars,
-			dynos:       dynos,
+			source:      source,
+			captured:    captured,
+			appInfo:     captured.AppInfo,
+			envVars:     captured.EnvVars,
+			dynos:       heroku.AttachStates(captured.Dynos, captured.Processes),
+			oneOffDynos: heroku.OneOffDynos(captured.Processes),
 			addons:      addons,
 			pricingData: pricingData,
 			cfg:         cfg,
@@ -303,10 +398,21 @@ func loadData(projectPath, appName string) tea.Cmd {
 	}
 }
 
-// runAnalysis performs the configuration analysis
-func runAnalysis(client *heroku.Client, pricingData *pricing.Data) tea.Cmd {
+// runAnalysis performs the configuration analysis on a captured app
+// Measurements left nil are taken from the capture
+func runAnalysis(captured *snapshot.Snapshot, pricingData *pricing.Data, projectPath string, measured analysis.Measurements) tea.Cmd {
 	return func() tea.Msg {
-		analyzer := analysis.NewAnalyzer(client, pricingData)
+		if measured.Runtime == nil && measured.Router == nil {
+			measured.Runtime = captured.RuntimeMetrics
+			measured.Router = captured.RouterMetrics
+		}
+		if measured.Releases == nil {
+			measured.Releases = captured.ReleaseHistory
+		}
+
+		analyzer := analysis.NewAnalyzer(captured.Source(), pricingData)
+		analyzer.SetProject(project.Load(projectPath))
+		analyzer.SetMeasurements(measured)
 
 		if err := analyzer.LoadData(); err != nil {
 			return analysisCompleteMsg{err: err}
@@ -2,9 +2,11 @@ package ui
 
 import (
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/leaharmstrong/heroku-calc/internal/analysis"
 	"github.com/leaharmstrong/heroku-calc/internal/config"
 	"github.com/leaharmstrong/heroku-calc/internal/heroku"
 	"github.com/leaharmstrong/heroku-calc/internal/pricing"
+	"github.com/leaharmstrong/heroku-calc/internal/snapshot"
 )
 
 // AppMode represents the current operation mode
@@ -25,8 +27,12 @@ const (
 	TabEnvVars
 	TabDynos
 	TabAddons
+	TabCost
 	TabAnalysis
+	TabReleases
 	TabActions
+
+	tabCount // Number of tabs
 )
 
 // AppState represents the current state of the application
@@ -38,6 +44,7 @@ const (
 	StateAnalyzing
 	StateError
 	StateApplying
+	StateSelectingRemote
 )
 
 // Model represents the BubbleTea application model
@@ -45,7 +52,9 @@ type Model struct {
 	// Configuration
 	projectPath string
 	appName     string
+	remoteName  string
 	mode        AppMode
+	measured    analysis.Measurements // Log metrics from a file, drain or snapshot and user-supplied traffic
 
 	// Current state
 	state      AppState
@@ -53,14 +62,17 @@ type Model struct {
 	err        error
 
 	// Data
-	herokuClient *heroku.Client
-	appInfo      *heroku.AppInfo
-	envVars      []config.HerokuEnvVar
-	dynos        []config.DynoFormation
-	addons       []config.Addon
-	cfg          *config.Config
-	pricingData  *pricing.Data
-	analysis     *config.AnalysisResult
+	remotes     []heroku.HerokuRemote
+	source      heroku.Source
+	appInfo     *heroku.AppInfo
+	envVars     []config.HerokuEnvVar
+	dynos       []config.DynoFormation
+	oneOffDynos []config.Dyno
+	addons      []config.Addon
+	cfg         *config.Config
+	pricingData *pricing.Data
+	analysis    *config.AnalysisResult
+	snapshot    *snapshot.Snapshot
 
 	// UI state
 	spinner         spinner.Model
@@ -75,7 +87,17 @@ type Model struct {
 }
 
 // NewModel creates a new application model
-func NewModel(projectPath, appName string, mode AppMode) Model {
+// Without an app name, the app comes from remoteName, the remote saved in the config,
+// the only Heroku git remote, or a remote picked at startup
+func NewModel(projectPath, appName, remoteName string, mode AppMode) Model {
+	m := NewModelWithSource(projectPath, appName, nil, mode)
+	m.remoteName = remoteName
+	return m
+}
+
+// NewModelWithSource creates a model that reads from the given source
+// If source is nil, the default source for the detected app is used
+func NewModelWithSource(projectPath, appName string, source heroku.Source, mode AppMode) Model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = spinnerStyle
@@ -84,6 +106,7 @@ func NewModel(projectPath, appName string, mode AppMode) Model {
 		projectPath:     projectPath,
 		appName:         appName,
 		mode:            mode,
+		source:          source,
 		state:           StateLoading,
 		currentTab:      TabOverview,
 		spinner:         s,
@@ -92,6 +115,25 @@ func NewModel(projectPath, appName string, mode AppMode) Model {
 	}
 }
 
+// NewModelFromSnapshot creates a model that replays a captured snapshot
+func NewModelFromSnapshot(projectPath string, snap *snapshot.Snapshot, mode AppMode) Model {
+	m := NewModelWithSource(projectPath, snap.AppInfo.Name, snap.Source(), mode)
+	m.snapshot = snap
+	m.measured = analysis.Measurements{Runtime: snap.RuntimeMetrics, Router: snap.RouterMetrics, Releases: snap.ReleaseHistory}
+	return m
+}
+
+// SetMeasurements provides dyno memory and router traffic, e.g. from a saved log file,
+// in place of reading recent logs from Heroku, and user-supplied traffic numbers
+// Metrics left nil keep those of the snapshot being replayed
+func (m *Model) SetMeasurements(measured analysis.Measurements) {
+	if measured.Runtime != nil || measured.Router != nil {
+		m.measured.Runtime = measured.Runtime
+		m.measured.Router = measured.Router
+	}
+	m.measured.Traffic = measured.Traffic
+}
+
 // GetTabName returns the display name for a tab
 func (m Model) GetTabName(tab Tab) string {
 	switch tab {
@@ -103,8 +145,12 @@ func (m Model) GetTabName(tab Tab) string {
 		return "Dynos"
 	case TabAddons:
 		return "Addons"
+	case TabCost:
+		return "Cost"
 	case TabAnalysis:
 		return "Analysis"
+	case TabReleases:
+		return "Releases"
 	case TabActions:
 		return "Actions"
 	default:
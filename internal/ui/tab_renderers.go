@@ -2,8 +2,10 @@ package ui
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
+	"github.com/leaharmstrong/heroku-calc/internal/config"
 	"github.com/leaharmstrong/heroku-calc/internal/heroku"
 	"github.com/leaharmstrong/heroku-calc/internal/ui/tabs"
 )
@@ -66,8 +68,8 @@ func (m Model) renderDynosTab() string {
 	}
 
 	// Header
-	content.WriteString("  Type       Quantity  Size            Monthly Cost\n")
-	content.WriteString("  ─────────  ────────  ──────────────  ────────────\n")
+	content.WriteString("  Type       Quantity  Size            Monthly Cost  State\n")
+	content.WriteString("  ─────────  ────────  ──────────────  ────────────  ─────────────────\n")
 
 	totalCost := 0.0
 
@@ -81,16 +83,46 @@ func (m Model) renderDynosTab() string {
 			}
 		}
 
-		content.WriteString(fmt.Sprintf("  %-9s  %-8d  %-14s  $%.2f\n",
-			dyno.Type, dyno.Quantity, dyno.Size, monthlyCost))
+		content.WriteString(fmt.Sprintf("  %-9s  %-8d  %-14s  %-12s  %s\n",
+			dyno.Type, dyno.Quantity, dyno.Size, fmt.Sprintf("$%.2f", monthlyCost), formatDynoStates(dyno)))
 	}
 
-	content.WriteString("  ─────────────────────────────────────────────────\n")
+	content.WriteString("  ────────────────────────────────────────────────────────────────────\n")
 	content.WriteString(fmt.Sprintf("  Total monthly cost: $%.2f\n", totalCost))
 
+	// One-off dynos are not part of the formation and are billed separately
+	if len(m.oneOffDynos) > 0 {
+		content.WriteString("\nONE-OFF DYNOS\n\n")
+		for _, dyno := range m.oneOffDynos {
+			content.WriteString(fmt.Sprintf("  %-12s  %-14s  %s\n", dyno.Name, dyno.Size, dyno.State))
+		}
+	}
+
 	return content.String()
 }
 
+// formatDynoStates summarizes the states of a process type's running dynos
+func formatDynoStates(dyno config.DynoFormation) string {
+	if dyno.Quantity == 0 {
+		return "scaled to zero"
+	}
+	if len(dyno.States) == 0 {
+		return "no dynos running"
+	}
+
+	states := make([]string, 0, len(dyno.States))
+	for state := range dyno.States {
+		states = append(states, state)
+	}
+	sort.Strings(states)
+
+	parts := make([]string, len(states))
+	for i, state := range states {
+		parts[i] = fmt.Sprintf("%d %s", dyno.States[state], state)
+	}
+	return strings.Join(parts, ", ")
+}
+
 // renderAddonsTab renders the addons tab
 func (m Model) renderAddonsTab() string {
 	var content strings.Builder
@@ -115,11 +147,21 @@ func (m Model) renderAddonsTab() string {
 	return content.String()
 }
 
+// renderCostTab renders the cost tab
+func (m Model) renderCostTab() string {
+	return tabs.RenderCost(m.analysis)
+}
+
 // renderAnalysisTab renders the analysis tab
 func (m Model) renderAnalysisTab() string {
 	return tabs.RenderAnalysis(m.analysis)
 }
 
+// renderReleasesTab renders the releases tab
+func (m Model) renderReleasesTab() string {
+	return tabs.RenderReleases(m.analysis)
+}
+
 // renderActionsTab renders the actions tab
 func (m Model) renderActionsTab() string {
 	var content strings.Builder
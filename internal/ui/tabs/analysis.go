@@ -2,7 +2,9 @@ package tabs
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/leaharmstrong/heroku-calc/internal/config"
 )
@@ -19,15 +21,24 @@ func RenderAnalysis(analysis *config.AnalysisResult) string {
 		return content.String()
 	}
 
-	// Database Analysis
-	if analysis.DatabaseAnalysis != nil {
-		content.WriteString(renderDatabaseAnalysis(analysis.DatabaseAnalysis))
+	// Problems reading the Rails project
+	if len(analysis.ProjectWarnings) > 0 {
+		content.WriteString("PROJECT FILES\n")
+		for _, warning := range analysis.ProjectWarnings {
+			content.WriteString(fmt.Sprintf("  • %s\n", warning))
+		}
+		content.WriteString("\n")
+	}
+
+	// Database Analysis, one per Postgres datastore
+	for _, db := range analysis.Databases {
+		content.WriteString(renderDatabaseAnalysis(db))
 		content.WriteString("\n")
 	}
 
-	// Redis Analysis
-	if analysis.RedisAnalysis != nil {
-		content.WriteString(renderRedisAnalysis(analysis.RedisAnalysis))
+	// Redis Analysis, one per Redis datastore
+	for _, redis := range analysis.RedisInstances {
+		content.WriteString(renderRedisAnalysis(redis))
 		content.WriteString("\n")
 	}
 
@@ -36,6 +47,18 @@ func RenderAnalysis(analysis *config.AnalysisResult) string {
 		content.WriteString(renderWebTierAnalysis(analysis.WebTierAnalysis))
 	}
 
+	// Measured dyno memory
+	if analysis.MemoryAnalysis != nil {
+		content.WriteString("\n")
+		content.WriteString(renderMemoryAnalysis(analysis.MemoryAnalysis))
+	}
+
+	// Measured router traffic
+	if analysis.TrafficAnalysis != nil {
+		content.WriteString("\n")
+		content.WriteString(renderTrafficAnalysis(analysis.TrafficAnalysis))
+	}
+
 	return content.String()
 }
 
@@ -43,19 +66,63 @@ func renderDatabaseAnalysis(analysis *config.DatabaseAnalysis) string {
 	var content strings.Builder
 
 	content.WriteString(fmt.Sprintf("DATABASE CONNECTIONS - %s\n", formatStatus(analysis.Status)))
+	if analysis.Datastore != nil {
+		content.WriteString(fmt.Sprintf("  Datastore: %s\n", analysis.Datastore.Name()))
+	}
 	content.WriteString(fmt.Sprintf("  Plan: %s\n", analysis.PostgresPlan))
 	content.WriteString(fmt.Sprintf("  Max connections: %d\n", analysis.MaxConnections))
-	content.WriteString(fmt.Sprintf("  Current usage: %d dynos × %d workers × %d threads = %d connections\n",
-		analysis.WebDynos, analysis.WorkersPerDyno, analysis.ThreadsPerWorker,
-		analysis.WebDynos*analysis.WorkersPerDyno*analysis.ThreadsPerWorker))
 
-	if analysis.SidekiqDynos > 0 {
-		content.WriteString(fmt.Sprintf("  Sidekiq: %d dynos × %d threads = %d connections\n",
-			analysis.SidekiqDynos, analysis.SidekiqThreads, analysis.SidekiqDynos*analysis.SidekiqThreads))
+	// The formation is modelled against the primary database only
+	if analysis.Primary() {
+		content.WriteString(fmt.Sprintf("  Current usage: %d dynos × %d workers × %d threads = %d connections\n",
+			analysis.WebDynos, analysis.WorkersPerDyno, analysis.ThreadsPerWorker,
+			analysis.WebConnections()))
+
+		for _, process := range analysis.Processes {
+			if process.Type == "web" {
+				continue
+			}
+			content.WriteString(fmt.Sprintf("  %s (%s): %d dynos × %d threads%s = %d connections\n",
+				process.Type, process.Kind, process.Dynos, process.Threads, formatSource(process.ThreadsSource), process.Connections))
+			if len(process.Queues) > 0 {
+				content.WriteString(fmt.Sprintf("    queues: %s\n", strings.Join(process.Queues, ", ")))
+			}
+		}
+
+		for _, pool := range analysis.Pools {
+			content.WriteString(fmt.Sprintf("  Pool (%s, %s): %s\n", pool.Name, analysis.Environment, formatPool(pool)))
+		}
+
+		for _, pooler := range analysis.Poolers {
+			content.WriteString(fmt.Sprintf("  Pooler: %s for %s\n", pooler.Describe(), strings.Join(pooler.Processes, ", ")))
+			content.WriteString(fmt.Sprintf("    %d client → %s server connections\n",
+				pooler.ClientConnections, formatServerConnections(pooler)))
+		}
+	}
+
+	// Each process opens a pool per database, so spell out how they add up
+	if len(analysis.Budgets) > 1 || !analysis.Primary() {
+		for _, budget := range analysis.Budgets {
+			content.WriteString(fmt.Sprintf("  Database %s: pool %s → %d connections\n",
+				formatBudget(budget), formatPoolSize(budget.Pool), budget.Connections))
+		}
+	}
+
+	if analysis.Modelled() || analysis.Measured != nil {
+		content.WriteString(fmt.Sprintf("  Total required: %d / %d available (%.1f%% buffer)\n",
+			analysis.TotalRequired, analysis.MaxConnections, analysis.BufferPercent))
 	}
 
-	content.WriteString(fmt.Sprintf("  Total required: %d / %d available (%.1f%% buffer)\n",
-		analysis.TotalRequired, analysis.MaxConnections, analysis.BufferPercent))
+	if measured := analysis.Measured; measured != nil {
+		if analysis.Modelled() {
+			content.WriteString(fmt.Sprintf("  Measured (pg:info): %s open vs %d estimated\n",
+				formatMeasuredConnections(measured), analysis.TotalRequired))
+		} else {
+			content.WriteString(fmt.Sprintf("  Measured (pg:info): %s open\n", formatMeasuredConnections(measured)))
+		}
+		content.WriteString(fmt.Sprintf("  Postgres %s, data size %s\n",
+			valueOrUnknown(measured.Version), formatDataSize(measured)))
+	}
 
 	if len(analysis.Issues) > 0 {
 		content.WriteString("\n  Issues:\n")
@@ -63,10 +130,102 @@ func renderDatabaseAnalysis(analysis *config.DatabaseAnalysis) string {
 			content.WriteString(fmt.Sprintf("  • %s\n", issue))
 		}
 	}
+	content.WriteString(renderRecentChanges(analysis.RecentChanges))
+
+	return content.String()
+}
+
+// renderRecentChanges lists the releases that may explain a component's issues
+func renderRecentChanges(changes []config.ReleaseChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
 
+	var content strings.Builder
+	now := time.Now()
+	content.WriteString("\n  Recent changes:\n")
+	for _, change := range changes {
+		content.WriteString(fmt.Sprintf("  • %s\n", change.Summary(now)))
+	}
 	return content.String()
 }
 
+// formatServerConnections shows a pooler's server connections, with its maximum when known
+func formatServerConnections(pooler *config.ConnectionPooler) string {
+	if pooler.MaxServerConnections == 0 {
+		return fmt.Sprintf("%d", pooler.ServerConnections)
+	}
+	return fmt.Sprintf("%d (max %d)", pooler.ServerConnections, pooler.MaxServerConnections)
+}
+
+// formatPool describes a database pool from config/database.yml
+// formatRedisPlan shows the plan with its marketplace provider, e.g. "100 (Redis Cloud)"
+func formatRedisPlan(analysis *config.RedisAnalysis) string {
+	if analysis.Provider == "" {
+		return analysis.RedisPlan
+	}
+	return fmt.Sprintf("%s (%s)", analysis.RedisPlan, analysis.Provider)
+}
+
+// formatBudget names a database and the env var it reads
+func formatBudget(budget config.DatabaseBudget) string {
+	value := budget.Name
+	if budget.URLEnv != "" {
+		value += " via " + budget.URLEnv
+	}
+	if budget.Replica {
+		value += " [replica]"
+	}
+	return value
+}
+
+// formatPoolSize formats an evaluated pool size
+func formatPoolSize(pool int) string {
+	if pool == 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%d", pool)
+}
+
+func formatPool(pool config.DatabasePool) string {
+	var value string
+	switch {
+	case pool.Pool == 0:
+		value = "unknown"
+	case !pool.PoolSet:
+		value = fmt.Sprintf("%d (ActiveRecord default)", pool.Pool)
+	default:
+		value = fmt.Sprintf("%d", pool.Pool)
+	}
+
+	if pool.URLEnv != "" {
+		value += " via " + pool.URLEnv
+	}
+	if pool.Replica {
+		value += " [replica]"
+	}
+	return value
+}
+
+// formatMeasuredConnections shows connections in use, with the limit when pg:info reports one
+func formatMeasuredConnections(measured *config.PostgresInfo) string {
+	if measured.ConnectionLimit == 0 {
+		return fmt.Sprintf("%d", measured.Connections)
+	}
+	return fmt.Sprintf("%d / %d", measured.Connections, measured.ConnectionLimit)
+}
+
+// formatDataSize shows the database size, with the plan's storage limit when known
+func formatDataSize(measured *config.PostgresInfo) string {
+	switch {
+	case measured.DataSize == "":
+		return "unknown"
+	case measured.DataLimit == "":
+		return measured.DataSize
+	}
+	return fmt.Sprintf("%s / %s", measured.DataSize, measured.DataLimit)
+}
+
 func renderRedisAnalysis(analysis *config.RedisAnalysis) string {
 	var content strings.Builder
 
@@ -77,15 +236,29 @@ func renderRedisAnalysis(analysis *config.RedisAnalysis) string {
 		return content.String()
 	}
 
-	content.WriteString(fmt.Sprintf("  Plan: %s\n", analysis.RedisPlan))
+	if analysis.Datastore != nil {
+		content.WriteString(fmt.Sprintf("  Datastore: %s\n", analysis.Datastore.Name()))
+	}
+	content.WriteString(fmt.Sprintf("  Plan: %s\n", formatRedisPlan(analysis)))
 	content.WriteString(fmt.Sprintf("  Max connections: %d\n", analysis.MaxConnections))
 	content.WriteString(fmt.Sprintf("  Sidekiq concurrency: %d\n", analysis.SidekiqConcurrency))
 	content.WriteString(fmt.Sprintf("  Redis pool size: %d\n", analysis.RedisPoolSize))
 	content.WriteString(fmt.Sprintf("  Estimated usage: %d\n", analysis.EstimatedUsage))
 
+	if measured := analysis.Measured; measured != nil {
+		if measured.Live {
+			content.WriteString(fmt.Sprintf("  Measured (redis:info): %d clients connected vs %d estimated\n",
+				measured.ConnectedClients, analysis.EstimatedUsage))
+			content.WriteString(fmt.Sprintf("  Memory: %s\n", formatRedisMemory(measured)))
+		} else {
+			content.WriteString("  Measured (redis:info): connected clients not reported, using the estimate\n")
+		}
+		content.WriteString(fmt.Sprintf("  Redis %s, maxmemory-policy %s\n",
+			valueOrUnknown(measured.Version), valueOrUnknown(measured.MaxmemoryPolicy)))
+	}
+
 	if analysis.MaxConnections > 0 {
-		utilizationPercent := float64(analysis.EstimatedUsage) / float64(analysis.MaxConnections) * 100
-		content.WriteString(fmt.Sprintf("  Utilization: %.1f%%\n", utilizationPercent))
+		content.WriteString(fmt.Sprintf("  Utilization: %.1f%%\n", analysis.Utilization()))
 	}
 
 	if len(analysis.Issues) > 0 {
@@ -94,23 +267,86 @@ func renderRedisAnalysis(analysis *config.RedisAnalysis) string {
 			content.WriteString(fmt.Sprintf("  • %s\n", issue))
 		}
 	}
+	content.WriteString(renderRecentChanges(analysis.RecentChanges))
 
 	return content.String()
 }
 
+// formatRedisMemory shows used memory against maxmemory
+func formatRedisMemory(measured *config.RedisInfo) string {
+	if measured.Maxmemory == 0 {
+		return formatBytes(measured.UsedMemory)
+	}
+	return fmt.Sprintf("%s / %s (%.1f%%)", formatBytes(measured.UsedMemory), formatBytes(measured.Maxmemory), measured.MemoryPercent())
+}
+
+// formatBytes formats a byte count in binary units
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	value, exp := float64(bytes)/unit, 0
+	for value >= unit && exp < 3 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", value, "KMGT"[exp])
+}
+
 func renderWebTierAnalysis(analysis *config.WebTierAnalysis) string {
 	var content strings.Builder
 
 	content.WriteString(fmt.Sprintf("WEB TIER - %s\n", formatStatus(analysis.Status)))
 	content.WriteString(fmt.Sprintf("  Dyno type: %s (%d MB RAM)\n", analysis.DynoType, analysis.DynoMemoryMB))
-	content.WriteString(fmt.Sprintf("  WEB_CONCURRENCY: %d workers\n", analysis.WebConcurrency))
-	content.WriteString(fmt.Sprintf("  RAILS_MAX_THREADS: %d threads per worker\n", analysis.RailsMaxThreads))
+	content.WriteString(fmt.Sprintf("  WEB_CONCURRENCY: %d workers%s\n", analysis.WebConcurrency, formatSource(analysis.ConcurrencySource)))
+	content.WriteString(fmt.Sprintf("  RAILS_MAX_THREADS: %d threads per worker%s\n", analysis.RailsMaxThreads, formatSource(analysis.ThreadsSource)))
+	if analysis.PreloadApp {
+		content.WriteString("  preload_app!: enabled (copy-on-write memory sharing)\n")
+	}
 	content.WriteString(fmt.Sprintf("  Total threads: %d\n", analysis.TotalThreads))
 
 	if analysis.MemoryPerThread > 0 {
 		content.WriteString(fmt.Sprintf("  Memory per thread: %d MB\n", analysis.MemoryPerThread))
 	}
 
+	if memory := analysis.Memory; memory != nil && memory.Samples > 0 {
+		content.WriteString(fmt.Sprintf("  Measured memory: peak %.0f MB, p95 %.0f MB of %s\n",
+			memory.MemoryPeakMB, memory.MemoryP95MB, formatQuota(memory.QuotaMB)))
+	}
+	if memory := analysis.Memory; memory != nil && memory.R14Errors+memory.R15Errors > 0 {
+		content.WriteString(fmt.Sprintf("  Memory errors: %d R14, %d R15\n", memory.R14Errors, memory.R15Errors))
+	}
+
+	if capacity := analysis.Capacity; capacity != nil {
+		content.WriteString(fmt.Sprintf("  Traffic (%s): %.0f req/min × %.0f ms = %.1f requests in flight\n",
+			capacity.Source, capacity.RequestsPerMinute, capacity.ServiceTimeMS, capacity.InFlight))
+		content.WriteString(fmt.Sprintf("  Capacity: %d dynos × %d threads, %.0f%% busy; %d dynos needed at %.0f%% target\n",
+			capacity.CurrentDynos, capacity.ThreadsPerDyno, capacity.CurrentUtilization, capacity.RequiredDynos, capacity.TargetUtilization))
+	}
+
+	if len(analysis.Issues) > 0 {
+		content.WriteString("\n  Issues:\n")
+		for _, issue := range analysis.Issues {
+			content.WriteString(fmt.Sprintf("  • %s\n", issue))
+		}
+	}
+	content.WriteString(renderRecentChanges(analysis.RecentChanges))
+
+	return content.String()
+}
+
+func renderMemoryAnalysis(analysis *config.MemoryAnalysis) string {
+	var content strings.Builder
+
+	content.WriteString(fmt.Sprintf("DYNO MEMORY (measured) - %s\n", formatStatus(analysis.Status)))
+	content.WriteString(fmt.Sprintf("  Source: %s\n", analysis.Source))
+
+	for _, process := range analysis.Processes {
+		content.WriteString(fmt.Sprintf("  %s: %d dynos, peak %.0f MB, p95 %.0f MB of %s, load p95 %.2f\n",
+			process.Type, process.Dynos, process.MemoryPeakMB, process.MemoryP95MB, formatQuota(process.QuotaMB), process.LoadAvgP95))
+	}
+
 	if len(analysis.Issues) > 0 {
 		content.WriteString("\n  Issues:\n")
 		for _, issue := range analysis.Issues {
@@ -120,3 +356,67 @@ func renderWebTierAnalysis(analysis *config.WebTierAnalysis) string {
 
 	return content.String()
 }
+
+func renderTrafficAnalysis(analysis *config.TrafficAnalysis) string {
+	var content strings.Builder
+	metrics := analysis.Metrics
+
+	content.WriteString(fmt.Sprintf("ROUTER TRAFFIC (measured) - %s\n", formatStatus(analysis.Status)))
+	content.WriteString(fmt.Sprintf("  Source: %s (%s)\n", metrics.Source, metrics.Duration().Round(time.Second)))
+	content.WriteString(fmt.Sprintf("  Requests: %d (%.1f/min)\n", metrics.Requests, metrics.RequestsPerMinute()))
+	content.WriteString(fmt.Sprintf("  Service time: p50 %.0f ms, p95 %.0f ms, p99 %.0f ms, max %.0f ms\n",
+		metrics.ServiceP50MS, metrics.ServiceP95MS, metrics.ServiceP99MS, metrics.ServiceMaxMS))
+	content.WriteString(fmt.Sprintf("  Connect time: p95 %.0f ms\n", metrics.ConnectP95MS))
+	content.WriteString(fmt.Sprintf("  Status codes: %s\n", formatCounts(metrics.StatusClasses)))
+	if len(metrics.ErrorCodes) > 0 {
+		content.WriteString(fmt.Sprintf("  Router errors: %s\n", formatCounts(metrics.ErrorCodes)))
+	}
+
+	for _, dyno := range metrics.Dynos {
+		content.WriteString(fmt.Sprintf("  %s: %d requests (%.1f%%), p95 %.0f ms, %d errors\n",
+			dyno.Dyno, dyno.Requests, float64(dyno.Requests)/float64(metrics.Requests)*100, dyno.ServiceP95MS, dyno.Errors))
+	}
+
+	if len(analysis.Issues) > 0 {
+		content.WriteString("\n  Issues:\n")
+		for _, issue := range analysis.Issues {
+			content.WriteString(fmt.Sprintf("  • %s\n", issue))
+		}
+	}
+
+	return content.String()
+}
+
+// formatCounts lists counts by key in key order, e.g. "2xx 120, 5xx 3"
+func formatCounts(counts map[string]int) string {
+	if len(counts) == 0 {
+		return "none"
+	}
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = fmt.Sprintf("%s %d", key, counts[key])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatQuota shows a dyno memory quota, which is unknown for unpriced dyno sizes
+func formatQuota(quotaMB float64) string {
+	if quotaMB == 0 {
+		return "unknown quota"
+	}
+	return fmt.Sprintf("%.0f MB", quotaMB)
+}
+
+// formatSource describes where a setting came from, e.g. " (from config/puma.rb)"
+func formatSource(source string) string {
+	if source == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (from %s)", source)
+}
@@ -0,0 +1,47 @@
+package tabs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+// RenderCost renders the cost tab: the monthly bill by category, dynos first, then data and
+// marketplace add-ons, with a grand total
+func RenderCost(analysis *config.AnalysisResult) string {
+	var content strings.Builder
+
+	content.WriteString("\n")
+	content.WriteString("MONTHLY COST\n\n")
+
+	if analysis == nil || analysis.Cost == nil {
+		content.WriteString("  No pricing data available\n")
+		return content.String()
+	}
+	cost := analysis.Cost
+
+	categories, totals := cost.CategoryTotals()
+	for _, category := range categories {
+		content.WriteString(fmt.Sprintf("  %-62s  %10s\n", category, fmt.Sprintf("$%.2f", totals[category])))
+		for _, item := range cost.Items {
+			if item.Category != category {
+				continue
+			}
+			content.WriteString(fmt.Sprintf("    %-24s  %-34s  %10s\n", item.Name, item.Detail, fmt.Sprintf("$%.2f", item.Monthly)))
+		}
+	}
+
+	content.WriteString("  ──────────────────────────────────────────────────────────────────────────\n")
+	content.WriteString(fmt.Sprintf("  %-62s  %10s\n", "Total monthly cost", fmt.Sprintf("$%.2f", cost.Total())))
+
+	if len(cost.Unpriced) > 0 {
+		content.WriteString("\n  Not in the pricing data (not counted):\n")
+		for _, unpriced := range cost.Unpriced {
+			content.WriteString(fmt.Sprintf("  • %s\n", unpriced))
+		}
+	}
+
+	content.WriteString("\n  List prices; add-ons attached from other apps are billed there and one-off dynos by the second.\n")
+	return content.String()
+}
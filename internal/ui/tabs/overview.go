@@ -21,11 +21,22 @@ func RenderOverview(appInfo *heroku.AppInfo, dynos []config.DynoFormation, addon
 		content.WriteString(fmt.Sprintf("  Stack:  %s\n\n", appInfo.Stack))
 	}
 
+	// Stack detected from the project
+	if analysis != nil && analysis.Stack != nil {
+		content.WriteString("STACK\n")
+		content.WriteString(renderStackProfile(analysis.Stack))
+		content.WriteString("\n")
+	}
+
 	// Dyno summary
 	content.WriteString("DYNOS\n")
 	if len(dynos) > 0 {
 		for _, dyno := range dynos {
-			content.WriteString(fmt.Sprintf("  %s: %d × %s\n", dyno.Type, dyno.Quantity, dyno.Size))
+			line := fmt.Sprintf("  %s: %d × %s", dyno.Type, dyno.Quantity, dyno.Size)
+			if crashed := dyno.States["crashed"]; crashed > 0 {
+				line += fmt.Sprintf(" (%d crashed)", crashed)
+			}
+			content.WriteString(line + "\n")
 		}
 	} else {
 		content.WriteString("  No dynos found\n")
@@ -69,6 +80,50 @@ func RenderOverview(appInfo *heroku.AppInfo, dynos []config.DynoFormation, addon
 	return content.String()
 }
 
+// renderStackProfile lists the stack detected from Gemfile.lock
+func renderStackProfile(stack *config.StackProfile) string {
+	if !stack.Detected {
+		return "  No Gemfile.lock found (assuming Sidekiq and Redis)\n"
+	}
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("  Ruby:       %s\n", valueOrUnknown(stack.RubyVersion)))
+	content.WriteString(fmt.Sprintf("  Rails:      %s\n", valueOrUnknown(stack.RailsVersion)))
+	content.WriteString(fmt.Sprintf("  Web server: %s\n", valueOrUnknown(strings.TrimSpace(stack.WebServer+" "+stack.WebServerVersion))))
+
+	jobs := "none"
+	if len(stack.JobBackends) > 0 {
+		jobs = strings.Join(stack.JobBackends, ", ")
+		if stack.SidekiqVersion != "" {
+			jobs = strings.Replace(jobs, "sidekiq", "sidekiq "+stack.SidekiqVersion, 1)
+		}
+	}
+	content.WriteString(fmt.Sprintf("  Jobs:       %s\n", jobs))
+
+	redis := "not used"
+	if stack.UsesRedis() {
+		users := append([]string{}, stack.RedisGems...)
+		if stack.CacheStore == "redis_cache_store" {
+			users = append(users, "cache store")
+		}
+		if stack.CableAdapter == "redis" {
+			users = append(users, "Action Cable")
+		}
+		redis = strings.Join(users, ", ")
+	}
+	content.WriteString(fmt.Sprintf("  Redis:      %s\n", redis))
+
+	return content.String()
+}
+
+// valueOrUnknown returns value, or "unknown" if it is empty
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
+
 func formatStatus(status config.AnalysisStatus) string {
 	switch status {
 	case config.StatusCritical:
@@ -0,0 +1,67 @@
+package tabs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/leaharmstrong/heroku-calc/internal/config"
+)
+
+// RenderReleases renders the releases tab: recent releases and the config vars,
+// add-ons and code each of them changed
+func RenderReleases(analysis *config.AnalysisResult) string {
+	var content strings.Builder
+
+	content.WriteString("\n")
+	content.WriteString("RECENT RELEASES\n\n")
+
+	if analysis == nil || analysis.ReleaseHistory == nil {
+		content.WriteString("  No release history available\n")
+		return content.String()
+	}
+	history := analysis.ReleaseHistory
+	if len(history.Releases) == 0 {
+		content.WriteString("  No releases\n")
+		return content.String()
+	}
+
+	// Value changes by release, shown under the release that made them
+	changes := make(map[int][]config.ReleaseChange)
+	for _, change := range history.Changes {
+		if change.Kind == config.ReleaseConfig {
+			changes[change.Version] = append(changes[change.Version], change)
+		}
+	}
+
+	now := time.Now()
+	for _, release := range history.Releases {
+		marker := " "
+		if release.Current {
+			marker = "*"
+		}
+		content.WriteString(fmt.Sprintf(" %s v%-5d %-14s %-28s %s\n",
+			marker, release.Version, config.FormatAge(now.Sub(release.CreatedAt)), valueOrUnknown(release.User), release.Description))
+		for _, change := range changes[release.Version] {
+			if value := formatValueChange(change); value != "" {
+				content.WriteString(fmt.Sprintf("           %s\n", value))
+			}
+		}
+	}
+
+	content.WriteString("\n  * current release. Scaling the formation doesn't create a release.\n")
+	return content.String()
+}
+
+// formatValueChange shows a tuned config var's old and new value, or "" when neither is known
+func formatValueChange(change config.ReleaseChange) string {
+	switch {
+	case change.Removed && change.OldValue != "":
+		return fmt.Sprintf("%s: %s → (unset)", change.Subject, change.OldValue)
+	case change.OldValue != "" && change.NewValue != "":
+		return fmt.Sprintf("%s: %s → %s", change.Subject, change.OldValue, change.NewValue)
+	case change.NewValue != "":
+		return fmt.Sprintf("%s: (unset) → %s", change.Subject, change.NewValue)
+	}
+	return ""
+}
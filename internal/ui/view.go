@@ -17,6 +17,10 @@ func (m Model) View() string {
 		return m.renderLoading()
 	}
 
+	if m.state == StateSelectingRemote {
+		return m.renderRemoteSelector()
+	}
+
 	var content strings.Builder
 
 	// Header
@@ -48,6 +52,9 @@ func (m Model) renderHeader() string {
 	appInfo := ""
 	if m.appInfo != nil {
 		appInfo = fmt.Sprintf("App: %s | Region: %s", m.appInfo.Name, m.appInfo.Region)
+		if m.remoteName != "" {
+			appInfo = fmt.Sprintf("App: %s (remote: %s) | Region: %s", m.appInfo.Name, m.remoteName, m.appInfo.Region)
+		}
 	}
 
 	// Dyno summary
@@ -65,9 +72,15 @@ func (m Model) renderHeader() string {
 		dynoSummary = fmt.Sprintf(" | Dynos: %d web, %d worker", webCount, workerCount)
 	}
 
+	// Snapshot marker
+	snapshotInfo := ""
+	if m.snapshot != nil {
+		snapshotInfo = fmt.Sprintf(" | Snapshot: %s", m.snapshot.CapturedAt.Format("2006-01-02 15:04 MST"))
+	}
+
 	header.WriteString(title)
 	header.WriteString("  ")
-	header.WriteString(baseStyle.Render(appInfo + dynoSummary))
+	header.WriteString(baseStyle.Render(appInfo + dynoSummary + snapshotInfo))
 
 	return header.String()
 }
@@ -76,7 +89,7 @@ func (m Model) renderHeader() string {
 func (m Model) renderTabs() string {
 	var tabs []string
 
-	for i := Tab(0); i < 6; i++ {
+	for i := Tab(0); i < tabCount; i++ {
 		name := m.GetTabName(i)
 		if i == m.currentTab {
 			tabs = append(tabs, activeTabStyle.Render(name))
@@ -99,8 +112,12 @@ func (m Model) renderTabContent() string {
 		return m.renderDynosTab()
 	case TabAddons:
 		return m.renderAddonsTab()
+	case TabCost:
+		return m.renderCostTab()
 	case TabAnalysis:
 		return m.renderAnalysisTab()
+	case TabReleases:
+		return m.renderReleasesTab()
 	case TabActions:
 		return m.renderActionsTab()
 	default:
@@ -149,6 +166,30 @@ func (m Model) renderLoading() string {
 	return content.String()
 }
 
+// renderRemoteSelector renders the startup list of Heroku git remotes
+func (m Model) renderRemoteSelector() string {
+	var content strings.Builder
+
+	content.WriteString("\n\n")
+	content.WriteString(titleStyle.Render("Heroku Config Analyzer"))
+	content.WriteString("\n\n")
+	content.WriteString("  This project has several Heroku git remotes. Which app should be analyzed?\n\n")
+
+	for i, remote := range m.remotes {
+		cursor := "  "
+		if i == m.cursorPos {
+			cursor = "> "
+		}
+		content.WriteString(fmt.Sprintf("  %s%s (%s)\n", cursor, remote.Name, remote.AppName))
+	}
+
+	content.WriteString("\n")
+	content.WriteString(helpStyle.Render("  ↑↓ Navigate  Enter Select  q Quit"))
+	content.WriteString("\n\n")
+
+	return content.String()
+}
+
 // renderError renders the error screen
 func (m Model) renderError() string {
 	var content strings.Builder